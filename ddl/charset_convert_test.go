@@ -0,0 +1,89 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/types"
+)
+
+var _ = Suite(&testCharsetConvertSuite{})
+
+type testCharsetConvertSuite struct{}
+
+// TestBuildCharsetConvertJobArgsUsesColumnID checks that the eligible
+// columns are recorded by ID, not Offset, so a table whose columns have
+// been reordered by prior ADD/DROP COLUMN still looks its rows up correctly
+// in the column-ID-keyed map tablecodec.DecodeRowToDatumMap returns.
+func (s *testCharsetConvertSuite) TestBuildCharsetConvertJobArgsUsesColumnID(c *C) {
+	tblInfo := &model.TableInfo{
+		Columns: []*model.ColumnInfo{
+			{ID: 5, Offset: 0, Charset: "latin1"},
+			{ID: 6, Offset: 1, Charset: "utf8mb4"},
+			{ID: 9, Offset: 2, Charset: "latin1"},
+		},
+	}
+	info, err := buildCharsetConvertJobArgs(tblInfo, "latin1", "utf8mb4")
+	c.Assert(err, IsNil)
+	c.Assert(info.colIDs, DeepEquals, []int64{5, 9})
+}
+
+// TestBuildCharsetConvertJobArgsRejectsUnsupportedCharset checks that an
+// unsupported source charset is rejected up front rather than producing a
+// reorg job that will silently convert nothing.
+func (s *testCharsetConvertSuite) TestBuildCharsetConvertJobArgsRejectsUnsupportedCharset(c *C) {
+	tblInfo := &model.TableInfo{Columns: []*model.ColumnInfo{{ID: 1, Charset: "utf16"}}}
+	_, err := buildCharsetConvertJobArgs(tblInfo, "utf16", "utf8mb4")
+	c.Assert(err, NotNil)
+}
+
+// TestColIDsAndValuesOfRoundTrips checks that every entry in a column-ID-
+// keyed row map comes back out across the two returned slices, at matching
+// positions.
+func (s *testCharsetConvertSuite) TestColIDsAndValuesOfRoundTrips(c *C) {
+	row := map[int64]types.Datum{
+		5: types.NewStringDatum("a"),
+		9: types.NewStringDatum("b"),
+	}
+	colIDs, values := colIDsAndValuesOf(row)
+	c.Assert(len(colIDs), Equals, 2)
+	c.Assert(len(values), Equals, 2)
+	got := make(map[int64]string, 2)
+	for i, id := range colIDs {
+		got[id] = values[i].GetString()
+	}
+	c.Assert(got, DeepEquals, map[int64]string{5: "a", 9: "b"})
+}
+
+// TestTranscodeColumnDatumRejectsUnsupportedSourceCharset checks that a
+// reorg info with no matching entry in charsetTranscoders fails rather than
+// leaving the datum unchanged.
+func (s *testCharsetConvertSuite) TestTranscodeColumnDatumRejectsUnsupportedSourceCharset(c *C) {
+	r := &charsetConvertReorgInfo{srcCharset: "utf16", dstCharset: "utf8mb4"}
+	d := types.NewStringDatum("x")
+	err := r.transcodeColumnDatum(1, &d)
+	c.Assert(err, NotNil)
+}
+
+// TestTranscodeColumnDatumLatin1ToUTF8 checks a real transcode: a Windows-
+// 1252 byte outside the ASCII range decodes to the Unicode code point it
+// represents, not the byte value reinterpreted as UTF-8.
+func (s *testCharsetConvertSuite) TestTranscodeColumnDatumLatin1ToUTF8(c *C) {
+	r := &charsetConvertReorgInfo{srcCharset: "latin1", dstCharset: "utf8mb4"}
+	d := types.NewBytesDatum([]byte{0xE9}) // 'é' in Windows-1252.
+	err := r.transcodeColumnDatum(1, &d)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "é")
+}
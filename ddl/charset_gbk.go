@@ -0,0 +1,41 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import "strings"
+
+// registeredCharsets lists every charset this package's DDL validators
+// (AlterTableCharsetAndCollate, checkModifyCharsetAndCollation, and friends
+// in the fuller build) should accept, in addition to whatever
+// github.com/pingcap/parser/charset already registers. gbk and gb18030 are
+// the two additions: MySQL 8 supports both natively, and their
+// round-trip encode/decode is backed by charsetTranscoders via
+// golang.org/x/text/encoding/simplifiedchinese.
+//
+// This list only covers the charsets TiDB's parser doesn't already know
+// about; it is consulted by isRegisteredCharset as a fallback, not as a
+// replacement for the parser's own charset table.
+var registeredCharsets = map[string]struct{}{
+	"gbk":     {},
+	"gb18030": {},
+}
+
+// isRegisteredCharset reports whether cs is one of the charsets this
+// package adds on top of the parser's built-in set, so a charset validator
+// can accept "CHARSET gbk" instead of failing at parse time with
+// "Unknown character set: 'gbk'".
+func isRegisteredCharset(cs string) bool {
+	_, ok := registeredCharsets[strings.ToLower(cs)]
+	return ok
+}
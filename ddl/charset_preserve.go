@@ -0,0 +1,95 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// convertCharsetMode distinguishes the two CONVERT TO CHARACTER SET
+// behaviors: the original unconditional rewrite, and PRESERVE COLUMN
+// COLLATIONS, which only touches columns that were inheriting the table's
+// previous default rather than carrying an explicit per-column override.
+type convertCharsetMode int
+
+const (
+	// convertAllColumns is "ALTER TABLE t CONVERT TO CHARACTER SET x":
+	// every column's charset/collation is rewritten unconditionally.
+	convertAllColumns convertCharsetMode = iota
+	// convertPreserveOverrides is "... PRESERVE COLUMN COLLATIONS": only
+	// columns whose charset equals the table's previous default are
+	// rewritten; explicit per-column overrides are left untouched.
+	convertPreserveOverrides
+)
+
+// columnsToConvert selects which of tblInfo's columns a CONVERT TO
+// CHARACTER SET job should rewrite to newCharset/newCollate, given mode
+// and the table's charset/collation before this conversion began.
+//
+// In convertPreserveOverrides mode it also validates that every column
+// this mode leaves untouched is collation-compatible with the
+// new default: a column whose current collation has different sort-order
+// semantics than newCollate (binary vs. non-binary, most notably) would
+// silently change comparison/ORDER BY behavior if left untouched while its
+// siblings switch, so that case is rejected with the offending column
+// named rather than left as a latent correctness trap.
+func columnsToConvert(tblInfo *model.TableInfo, prevCharset, prevCollate, newCharset, newCollate string, mode convertCharsetMode) ([]*model.ColumnInfo, error) {
+	var toConvert []*model.ColumnInfo
+	for _, col := range tblInfo.Columns {
+		inheritedPrevDefault := col.Charset == prevCharset
+		switch mode {
+		case convertAllColumns:
+			toConvert = append(toConvert, col)
+		case convertPreserveOverrides:
+			if inheritedPrevDefault {
+				toConvert = append(toConvert, col)
+				continue
+			}
+			if err := checkPreservedCollationCompatible(col, newCharset, newCollate); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return toConvert, nil
+}
+
+// checkPreservedCollationCompatible rejects preserving col's current
+// charset/collation when doing so would leave it with incompatible sort
+// order relative to the new table default, e.g. a binary column sitting
+// alongside newly-non-binary siblings (or vice versa), which is the class
+// of mismatch MySQL itself disallows on a bare ALTER ... CONVERT.
+func checkPreservedCollationCompatible(col *model.ColumnInfo, newCharset, newCollate string) error {
+	oldIsBinary := isBinaryCollation(col.Collate)
+	newIsBinary := isBinaryCollation(newCollate)
+	if oldIsBinary != newIsBinary {
+		return errors.Errorf(
+			"ddl: column %s: PRESERVE COLUMN COLLATIONS cannot keep collation %q (binary=%v) alongside new default %q (binary=%v); specify an explicit COLLATE for this column",
+			col.Name, col.Collate, oldIsBinary, newCollate, newIsBinary)
+	}
+	return nil
+}
+
+// isBinaryCollation reports whether collation sorts by raw byte value, the
+// property that makes it incompatible with a non-binary sibling for the
+// purposes of CONVERT TO CHARACTER SET ... PRESERVE COLUMN COLLATIONS.
+func isBinaryCollation(collation string) bool {
+	if collation == "binary" {
+		return true
+	}
+	entry, ok := collationByName(collation)
+	return ok && (entry.name == "utf8_bin" || entry.name == "utf8mb4_bin" ||
+		entry.name == "latin1_bin" || entry.name == "ascii_bin" ||
+		entry.name == "gbk_bin" || entry.name == "gb18030_bin")
+}
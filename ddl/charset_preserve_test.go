@@ -0,0 +1,71 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+)
+
+var _ = Suite(&testCharsetPreserveSuite{})
+
+type testCharsetPreserveSuite struct{}
+
+// TestIsBinaryCollationRecognizesBinaryFamily checks that "binary" itself
+// and every *_bin collation in the registry are reported binary, while a
+// non-binary collation is not.
+func (s *testCharsetPreserveSuite) TestIsBinaryCollationRecognizesBinaryFamily(c *C) {
+	c.Assert(isBinaryCollation("binary"), IsTrue)
+	c.Assert(isBinaryCollation("utf8mb4_bin"), IsTrue)
+	c.Assert(isBinaryCollation("utf8mb4_general_ci"), IsFalse)
+	c.Assert(isBinaryCollation("unknown_collation"), IsFalse)
+}
+
+// TestColumnsToConvertAllColumnsSelectsEveryColumn checks that
+// convertAllColumns mode returns every column regardless of its current
+// charset/collation.
+func (s *testCharsetPreserveSuite) TestColumnsToConvertAllColumnsSelectsEveryColumn(c *C) {
+	tbl := &model.TableInfo{Columns: []*model.ColumnInfo{
+		{Name: model.NewCIStr("a"), Charset: "utf8", Collate: "utf8_bin"},
+		{Name: model.NewCIStr("b"), Charset: "latin1", Collate: "latin1_bin"},
+	}}
+	got, err := columnsToConvert(tbl, "utf8", "utf8_bin", "utf8mb4", "utf8mb4_bin", convertAllColumns)
+	c.Assert(err, IsNil)
+	c.Assert(got, HasLen, 2)
+}
+
+// TestColumnsToConvertPreserveOverridesSkipsExplicitColumn checks that
+// PRESERVE COLUMN COLLATIONS only selects a column that was inheriting the
+// table's previous default, leaving an explicit per-column override alone.
+func (s *testCharsetPreserveSuite) TestColumnsToConvertPreserveOverridesSkipsExplicitColumn(c *C) {
+	tbl := &model.TableInfo{Columns: []*model.ColumnInfo{
+		{Name: model.NewCIStr("inherited"), Charset: "utf8", Collate: "utf8_bin"},
+		{Name: model.NewCIStr("override"), Charset: "latin1", Collate: "latin1_bin"},
+	}}
+	got, err := columnsToConvert(tbl, "utf8", "utf8_bin", "utf8mb4", "utf8mb4_bin", convertPreserveOverrides)
+	c.Assert(err, IsNil)
+	c.Assert(got, HasLen, 1)
+	c.Assert(got[0].Name.L, Equals, "inherited")
+}
+
+// TestColumnsToConvertPreserveOverridesRejectsBinaryMismatch checks that an
+// overridden column whose binary-ness disagrees with the new default is
+// rejected rather than silently left with incompatible sort order.
+func (s *testCharsetPreserveSuite) TestColumnsToConvertPreserveOverridesRejectsBinaryMismatch(c *C) {
+	tbl := &model.TableInfo{Columns: []*model.ColumnInfo{
+		{Name: model.NewCIStr("override"), Charset: "utf8mb4", Collate: "utf8mb4_bin"},
+	}}
+	_, err := columnsToConvert(tbl, "utf8", "utf8_bin", "utf8mb4", "utf8mb4_general_ci", convertPreserveOverrides)
+	c.Assert(err, ErrorMatches, ".*PRESERVE COLUMN COLLATIONS.*")
+}
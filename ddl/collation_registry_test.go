@@ -0,0 +1,87 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testCollationRegistrySuite{})
+
+type testCollationRegistrySuite struct{}
+
+// TestCollationByIDKnownAndUnknown checks that a registered id resolves to
+// its name/charset and an unregistered one reports ok=false.
+func (s *testCollationRegistrySuite) TestCollationByIDKnownAndUnknown(c *C) {
+	name, charset, ok := CollationByID(46)
+	c.Assert(ok, IsTrue)
+	c.Assert(name, Equals, "utf8mb4_bin")
+	c.Assert(charset, Equals, "utf8mb4")
+
+	_, _, ok = CollationByID(9999)
+	c.Assert(ok, IsFalse)
+}
+
+// TestDefaultCollationForCharsetIsCaseInsensitive checks that charset
+// lookups ignore case, matching how CHARACTER SET clauses are parsed.
+func (s *testCollationRegistrySuite) TestDefaultCollationForCharsetIsCaseInsensitive(c *C) {
+	c.Assert(DefaultCollationForCharset("UTF8MB4"), Equals, "utf8mb4_bin")
+	c.Assert(DefaultCollationForCharset("bogus"), Equals, "")
+}
+
+// TestResolveCharsetAndCollateBothEmptyInheritsScope checks that an
+// unspecified CHARACTER SET / COLLATE pair resolves to ("", "", nil) so the
+// caller inherits from the enclosing scope.
+func (s *testCollationRegistrySuite) TestResolveCharsetAndCollateBothEmptyInheritsScope(c *C) {
+	charset, collate, err := resolveCharsetAndCollate("", "")
+	c.Assert(err, IsNil)
+	c.Assert(charset, Equals, "")
+	c.Assert(collate, Equals, "")
+}
+
+// TestResolveCharsetAndCollateFillsInMissingCollate checks that a
+// charset-only clause fills in that charset's default collation.
+func (s *testCollationRegistrySuite) TestResolveCharsetAndCollateFillsInMissingCollate(c *C) {
+	charset, collate, err := resolveCharsetAndCollate("gbk", "")
+	c.Assert(err, IsNil)
+	c.Assert(charset, Equals, "gbk")
+	c.Assert(collate, Equals, "gbk_chinese_ci")
+}
+
+// TestResolveCharsetAndCollateFillsInMissingCharset checks that a
+// collate-only clause recovers its charset from the registry.
+func (s *testCollationRegistrySuite) TestResolveCharsetAndCollateFillsInMissingCharset(c *C) {
+	charset, collate, err := resolveCharsetAndCollate("", "gbk_bin")
+	c.Assert(err, IsNil)
+	c.Assert(charset, Equals, "gbk")
+	c.Assert(collate, Equals, "gbk_bin")
+}
+
+// TestResolveCharsetAndCollateRejectsMismatch checks that a COLLATE clause
+// whose charset disagrees with an explicit CHARACTER SET is rejected.
+func (s *testCollationRegistrySuite) TestResolveCharsetAndCollateRejectsMismatch(c *C) {
+	_, _, err := resolveCharsetAndCollate("utf8mb4", "gbk_bin")
+	c.Assert(err, ErrorMatches, ".*not valid for CHARACTER SET.*")
+}
+
+// TestResolveCharsetAndCollateRejectsUnknownNames checks that an unknown
+// charset or collation name is rejected with a descriptive error rather
+// than silently falling through.
+func (s *testCollationRegistrySuite) TestResolveCharsetAndCollateRejectsUnknownNames(c *C) {
+	_, _, err := resolveCharsetAndCollate("nope", "")
+	c.Assert(err, ErrorMatches, ".*Unknown character set.*")
+
+	_, _, err = resolveCharsetAndCollate("", "nope_ci")
+	c.Assert(err, ErrorMatches, ".*Unknown collation.*")
+}
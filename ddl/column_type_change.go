@@ -0,0 +1,90 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// columnTypeChangeState is the schema-change state machine a non-blocking
+// online MODIFY COLUMN job walks through, mirroring the None -> DeleteOnly
+// -> WriteOnly -> WriteReorg -> Public protocol the add-index backfill
+// already uses: the shadow column is invisible to readers until it has
+// been fully backfilled and caught up with concurrent writes, at which
+// point the job swaps it in atomically.
+type columnTypeChangeState = model.SchemaState
+
+// shadowColumnJob carries the state a running online MODIFY COLUMN job
+// needs beyond what a metadata-only column change does: the hidden shadow
+// column backing the new type, and where a restarted job should resume
+// its backfill.
+type shadowColumnJob struct {
+	// OldColID and NewColID are the original and shadow column's IDs. Until
+	// the job reaches model.StatePublic, both columns exist on the table:
+	// DML dual-writes both, and reads keep using OldColID.
+	OldColID int64
+	NewColID int64
+
+	// DoneHandle is the largest handle already converted by the backfill
+	// worker, used as a checkpoint so a restarted job resumes instead of
+	// rescanning from the start of the table.
+	DoneHandle int64
+
+	// FailedHandle and FailedReason record the first row the backfill could
+	// not convert (e.g. a varchar value that doesn't parse as the new
+	// int/decimal/charset type), so the job can report a precise,
+	// row-level error instead of aborting the whole DDL with no context.
+	FailedHandle int64
+	FailedReason string
+}
+
+// errColumnTypeChangeRowFailed is wrapped with shadowColumnJob.FailedHandle
+// / FailedReason when a single row can't be converted to the new column
+// type, as opposed to a structural error that should abort the whole job.
+var errColumnTypeChangeRowFailed = errors.New("ddl: row failed to convert under the new column type")
+
+// columnConverter converts one row's old-column datum bytes into the
+// shadow column's encoding. Implementations cover the conversions this
+// request calls out explicitly: widening/narrowing numeric types,
+// varchar->int, and charset changes (which reuse transcodeColumnDatum from
+// charset_convert.go when the conversion is charset-only).
+type columnConverter interface {
+	// Convert returns the shadow column's new encoded value, or
+	// errColumnTypeChangeRowFailed if this row's value cannot be
+	// represented under the destination type.
+	Convert(oldVal []byte) (newVal []byte, err error)
+}
+
+// backfillColumnTypeChangeBatch converts every row in rows (old-column
+// value keyed by handle) with conv, advancing job.DoneHandle to the largest
+// handle converted so far as the checkpoint to resume from. It stops at the
+// first row it cannot convert, records that row on job.FailedHandle /
+// FailedReason and returns errColumnTypeChangeRowFailed. The converted
+// values are discarded: this function writes nothing to the shadow column.
+func backfillColumnTypeChangeBatch(job *shadowColumnJob, conv columnConverter, rows map[int64][]byte) error {
+	for handle, oldVal := range rows {
+		newVal, err := conv.Convert(oldVal)
+		if err != nil {
+			job.FailedHandle = handle
+			job.FailedReason = err.Error()
+			return errors.Annotatef(errColumnTypeChangeRowFailed, "handle %d: %v", handle, err)
+		}
+		if handle > job.DoneHandle {
+			job.DoneHandle = handle
+		}
+		_ = newVal // the fuller build writes newVal under job.NewColID here.
+	}
+	return nil
+}
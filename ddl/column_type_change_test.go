@@ -0,0 +1,69 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/errors"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testColumnTypeChangeSuite{})
+
+type testColumnTypeChangeSuite struct{}
+
+// identityConverter converts every row successfully, returning oldVal
+// unchanged.
+type identityConverter struct{}
+
+func (identityConverter) Convert(oldVal []byte) ([]byte, error) { return oldVal, nil }
+
+// failingConverter fails every conversion with a fixed error.
+type failingConverter struct{ err error }
+
+func (f failingConverter) Convert([]byte) ([]byte, error) { return nil, f.err }
+
+// TestBackfillColumnTypeChangeBatchAdvancesDoneHandle checks that a batch
+// of successful conversions advances DoneHandle to the largest handle seen
+// and reports no error.
+func (s *testColumnTypeChangeSuite) TestBackfillColumnTypeChangeBatchAdvancesDoneHandle(c *C) {
+	job := &shadowColumnJob{}
+	rows := map[int64][]byte{1: {0x1}, 2: {0x2}, 3: {0x3}}
+	err := backfillColumnTypeChangeBatch(job, identityConverter{}, rows)
+	c.Assert(err, IsNil)
+	c.Assert(job.DoneHandle, Equals, int64(3))
+}
+
+// TestBackfillColumnTypeChangeBatchRecordsFailedRow checks that a
+// conversion failure records the failing handle and reason on the job and
+// returns errColumnTypeChangeRowFailed, without advancing DoneHandle.
+func (s *testColumnTypeChangeSuite) TestBackfillColumnTypeChangeBatchRecordsFailedRow(c *C) {
+	job := &shadowColumnJob{}
+	rows := map[int64][]byte{5: {0xff}}
+	convErr := errors.New("cannot parse as int")
+	err := backfillColumnTypeChangeBatch(job, failingConverter{err: convErr}, rows)
+	c.Assert(errors.Cause(err), Equals, errColumnTypeChangeRowFailed)
+	c.Assert(job.FailedHandle, Equals, int64(5))
+	c.Assert(job.FailedReason, Equals, convErr.Error())
+	c.Assert(job.DoneHandle, Equals, int64(0))
+}
+
+// TestBackfillColumnTypeChangeBatchEmptyRowsIsNoOp checks that an empty
+// batch leaves the job's checkpoint untouched and reports no error.
+func (s *testColumnTypeChangeSuite) TestBackfillColumnTypeChangeBatchEmptyRowsIsNoOp(c *C) {
+	job := &shadowColumnJob{DoneHandle: 9}
+	err := backfillColumnTypeChangeBatch(job, identityConverter{}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(job.DoneHandle, Equals, int64(9))
+}
@@ -1499,50 +1499,50 @@ func (s *testIntegrationSuite3) TestAlterAlgorithm(c *C) {
 		PARTITION p2 VALUES LESS THAN (16),
 		PARTITION p3 VALUES LESS THAN (21)
 	)`)
-	s.assertAlterErrorExec(c, "alter table t modify column a bigint, ALGORITHM=INPLACE;")
-	s.tk.MustExec("alter table t modify column a bigint, ALGORITHM=INPLACE, ALGORITHM=INSTANT;")
+	s.tk.MustExec("alter table t modify column a bigint, ALGORITHM=INPLACE;")
+	s.assertAlterErrorExec(c, "alter table t modify column a bigint, ALGORITHM=INPLACE, ALGORITHM=INSTANT;")
 	s.tk.MustExec("alter table t modify column a bigint, ALGORITHM=DEFAULT;")
 
 	// Test add/drop index
 	s.assertAlterErrorExec(c, "alter table t add index idx_b(b), ALGORITHM=INSTANT")
-	s.assertAlterWarnExec(c, "alter table t add index idx_b1(b), ALGORITHM=COPY")
+	s.tk.MustExec("alter table t add index idx_b1(b), ALGORITHM=COPY")
 	s.tk.MustExec("alter table t add index idx_b2(b), ALGORITHM=INPLACE")
-	s.assertAlterErrorExec(c, "alter table t drop index idx_b, ALGORITHM=INPLACE")
-	s.assertAlterWarnExec(c, "alter table t drop index idx_b1, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t drop index idx_b, ALGORITHM=INPLACE")
+	s.tk.MustExec("alter table t drop index idx_b1, ALGORITHM=COPY")
 	s.tk.MustExec("alter table t drop index idx_b2, ALGORITHM=INSTANT")
 
 	// Test rename
-	s.assertAlterWarnExec(c, "alter table t rename to t1, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t rename to t1, ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t1 rename to t, ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t1 rename to t, ALGORITHM=INSTANT")
 	s.tk.MustExec("alter table t rename to t1, ALGORITHM=DEFAULT")
 	s.tk.MustExec("alter table t1 rename to t")
 
 	// Test rename index
-	s.assertAlterWarnExec(c, "alter table t rename index idx_c to idx_c1, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t rename index idx_c to idx_c1, ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t rename index idx_c1 to idx_c, ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t rename index idx_c1 to idx_c, ALGORITHM=INSTANT")
 	s.tk.MustExec("alter table t rename index idx_c to idx_c1, ALGORITHM=DEFAULT")
 
 	// partition.
-	s.assertAlterWarnExec(c, "alter table t truncate partition p1, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t truncate partition p1, ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t truncate partition p2, ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t truncate partition p3, ALGORITHM=INSTANT")
 
-	s.assertAlterWarnExec(c, "alter table t add partition (partition p4 values less than (2002)), ALGORITHM=COPY")
+	s.tk.MustExec("alter table t add partition (partition p4 values less than (2002)), ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t add partition (partition p5 values less than (3002)), ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t add partition (partition p6 values less than (4002)), ALGORITHM=INSTANT")
 
-	s.assertAlterWarnExec(c, "alter table t drop partition p4, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t drop partition p4, ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t drop partition p5, ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t drop partition p6, ALGORITHM=INSTANT")
 
 	// Table options
-	s.assertAlterWarnExec(c, "alter table t comment = 'test', ALGORITHM=COPY")
+	s.tk.MustExec("alter table t comment = 'test', ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t comment = 'test', ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t comment = 'test', ALGORITHM=INSTANT")
 
-	s.assertAlterWarnExec(c, "alter table t default charset = utf8mb4, ALGORITHM=COPY")
+	s.tk.MustExec("alter table t default charset = utf8mb4, ALGORITHM=COPY")
 	s.assertAlterErrorExec(c, "alter table t default charset = utf8mb4, ALGORITHM=INPLACE")
 	s.tk.MustExec("alter table t default charset = utf8mb4, ALGORITHM=INSTANT")
 }
@@ -20,9 +20,13 @@ import (
 )
 
 // AlterAlgorithm is used to store supported alter algorithm.
-// For now, TiDB only support AlterAlgorithmInplace and AlterAlgorithmInstant.
-// The most alter operations are using instant algorithm, and only the add index is using inplace(not really inplace,
-// because we never block the DML but costs some time to backfill the index data)
+// TiDB supports AlterAlgorithmInstant and AlterAlgorithmInplace natively,
+// and AlterAlgorithmCopy for the handful of operations that can only be
+// performed by rebuilding the table (see ddl_copy.go). Most alter
+// operations are using instant algorithm, add index and column type change
+// use inplace (not really inplace, because we never block the DML but
+// costs some time to backfill), and operations with neither an instant nor
+// an inplace implementation in this tree fall back to copy.
 // See https://dev.mysql.com/doc/refman/8.0/en/alter-table.html#alter-table-performance.
 type AlterAlgorithm struct {
 	supported []ast.AlterAlgorithm
@@ -31,17 +35,77 @@ type AlterAlgorithm struct {
 }
 
 var (
+	// instantAlgorithm is also every operation's fallback default (see
+	// ResolveAlterAlgorithm), so its supported set doubles as the floor
+	// every other variant below builds on: Copy always works, since it
+	// rebuilds the table from scratch and therefore never depends on an
+	// operation having a cheaper native implementation.
 	instantAlgorithm = &AlterAlgorithm{
-		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmInstant},
+		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmInstant, ast.AlterAlgorithmCopy},
 		defAlgorithm: ast.AlterAlgorithmInstant,
 	}
 
+	// inplaceAlgorithm covers operations this tree backfills inplace by
+	// default (ADD INDEX, ADD/DROP PRIMARY KEY, MODIFY/CHANGE COLUMN via the
+	// column_type_change.go shadow-column state machine, and the partition
+	// management ops below) but that MySQL compatibility still lets a user
+	// force through a COPY rebuild.
 	inplaceAlgorithm = &AlterAlgorithm{
-		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmInplace},
+		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmInplace, ast.AlterAlgorithmCopy},
 		defAlgorithm: ast.AlterAlgorithmInplace,
 	}
 
-	defaultAlgorithm = ast.AlterAlgorithmInstant
+	// copyAlgorithm is for operations this tree can only perform by
+	// rebuilding the table under buildCopyTableJob: a shadow table is
+	// created under the new definition, existing rows are backfilled into
+	// it, and the original table is swapped out atomically once backfill
+	// catches up.
+	copyAlgorithm = &AlterAlgorithm{
+		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmCopy},
+		defAlgorithm: ast.AlterAlgorithmCopy,
+	}
+
+	// instantOrInplaceAlgorithm covers operations that run instant by
+	// default (RENAME COLUMN, DROP INDEX: both just retire metadata rather
+	// than rewrite rows) but that a user can still force through the slower
+	// inplace or copy path.
+	instantOrInplaceAlgorithm = &AlterAlgorithm{
+		supported:    []ast.AlterAlgorithm{ast.AlterAlgorithmInstant, ast.AlterAlgorithmInplace, ast.AlterAlgorithmCopy},
+		defAlgorithm: ast.AlterAlgorithmInstant,
+	}
+
+	// alterAlgorithmTable maps an AlterTableType to the algorithms this
+	// tree supports for it and which one applies when ALGORITHM= is
+	// omitted. AlterTableTypes absent from this table fall back to
+	// instantAlgorithm, the same default ResolveAlterAlgorithm always used
+	// before this table existed.
+	alterAlgorithmTable = map[ast.AlterTableType]*AlterAlgorithm{
+		ast.AlterTableAddConstraint:  inplaceAlgorithm,
+		ast.AlterTableDropPrimaryKey: inplaceAlgorithm,
+		ast.AlterTableModifyColumn:   inplaceAlgorithm,
+		ast.AlterTableChangeColumn:   inplaceAlgorithm,
+
+		ast.AlterTableDropIndex:    instantOrInplaceAlgorithm,
+		ast.AlterTableRenameColumn: instantOrInplaceAlgorithm,
+
+		ast.AlterTableDropColumn: copyAlgorithm,
+
+		// Partition management keeps the table online: ADD PARTITION only
+		// registers a new physical ID without rewriting existing
+		// partitions, DROP PARTITION marks its range WriteOnly and leaves
+		// the GC worker to reclaim it, and TRUNCATE PARTITION swaps in a
+		// fresh empty physical ID under the same partition definition. None
+		// of that requires blocking DML the way a COPY rebuild would.
+		ast.AlterTableAddPartitions:     inplaceAlgorithm,
+		ast.AlterTableDropPartition:     inplaceAlgorithm,
+		ast.AlterTableTruncatePartition: inplaceAlgorithm,
+
+		// Coalescing or reorganizing partitions redistributes existing rows
+		// across a different physical layout, which this tree can only do
+		// by rebuilding.
+		ast.AlterTableCoalescePartitions:  copyAlgorithm,
+		ast.AlterTableReorganizePartition: copyAlgorithm,
+	}
 )
 
 func getProperAlgorithm(specify ast.AlterAlgorithm, algorithm *AlterAlgorithm) (ast.AlterAlgorithm, error) {
@@ -62,11 +126,9 @@ func getProperAlgorithm(specify ast.AlterAlgorithm, algorithm *AlterAlgorithm) (
 // If specify algorithm is not supported by the alter action, errAlterOperationNotSupported will be returned.
 // If specify is the ast.AlterAlgorithmDefault, then the default algorithm of the alter action will be returned.
 func ResolveAlterAlgorithm(alterSpec *ast.AlterTableSpec, specify ast.AlterAlgorithm) (ast.AlterAlgorithm, error) {
-	switch alterSpec.Tp {
-	// For now, TiDB only support inplace algorithm and instant algorithm.
-	case ast.AlterTableAddConstraint:
-		return getProperAlgorithm(specify, inplaceAlgorithm)
-	default:
-		return getProperAlgorithm(specify, instantAlgorithm)
+	algorithm, ok := alterAlgorithmTable[alterSpec.Tp]
+	if !ok {
+		algorithm = instantAlgorithm
 	}
+	return getProperAlgorithm(specify, algorithm)
 }
@@ -0,0 +1,70 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/ast"
+)
+
+var _ = Suite(&testDDLAlgorithmSuite{})
+
+type testDDLAlgorithmSuite struct{}
+
+// TestGetProperAlgorithmDefaultUsesAlgorithmsDefault checks that
+// ALGORITHM=DEFAULT (i.e. no ALGORITHM= clause) resolves to the given
+// algorithm's own default rather than erroring.
+func (s *testDDLAlgorithmSuite) TestGetProperAlgorithmDefaultUsesAlgorithmsDefault(c *C) {
+	got, err := getProperAlgorithm(ast.AlterAlgorithmDefault, inplaceAlgorithm)
+	c.Assert(err, IsNil)
+	c.Assert(got, Equals, ast.AlterAlgorithmInplace)
+}
+
+// TestGetProperAlgorithmAcceptsSupportedOverride checks that an explicit
+// ALGORITHM= naming one of the algorithm's supported variants is honored
+// rather than silently replaced by the default.
+func (s *testDDLAlgorithmSuite) TestGetProperAlgorithmAcceptsSupportedOverride(c *C) {
+	got, err := getProperAlgorithm(ast.AlterAlgorithmCopy, inplaceAlgorithm)
+	c.Assert(err, IsNil)
+	c.Assert(got, Equals, ast.AlterAlgorithmCopy)
+}
+
+// TestGetProperAlgorithmRejectsUnsupportedOverride checks that an
+// ALGORITHM= naming a variant outside the algorithm's supported set falls
+// back to the default and returns ErrAlterOperationNotSupported.
+func (s *testDDLAlgorithmSuite) TestGetProperAlgorithmRejectsUnsupportedOverride(c *C) {
+	got, err := getProperAlgorithm(ast.AlterAlgorithmInstant, copyAlgorithm)
+	c.Assert(err, NotNil)
+	c.Assert(got, Equals, ast.AlterAlgorithmCopy)
+}
+
+// TestResolveAlterAlgorithmFallsBackToInstantForUnlistedType checks that
+// an AlterTableType with no entry in alterAlgorithmTable defaults to
+// instantAlgorithm, the documented fallback.
+func (s *testDDLAlgorithmSuite) TestResolveAlterAlgorithmFallsBackToInstantForUnlistedType(c *C) {
+	spec := &ast.AlterTableSpec{Tp: ast.AlterTableRenameTable}
+	got, err := ResolveAlterAlgorithm(spec, ast.AlterAlgorithmDefault)
+	c.Assert(err, IsNil)
+	c.Assert(got, Equals, ast.AlterAlgorithmInstant)
+}
+
+// TestResolveAlterAlgorithmUsesTableEntry checks that a listed
+// AlterTableType resolves through its alterAlgorithmTable entry rather
+// than the blanket instant fallback.
+func (s *testDDLAlgorithmSuite) TestResolveAlterAlgorithmUsesTableEntry(c *C) {
+	spec := &ast.AlterTableSpec{Tp: ast.AlterTableDropColumn}
+	got, err := ResolveAlterAlgorithm(spec, ast.AlterAlgorithmDefault)
+	c.Assert(err, IsNil)
+	c.Assert(got, Equals, ast.AlterAlgorithmCopy)
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// copyTableJob carries the state a running ALGORITHM=COPY job needs: the
+// shadow table being built under the new definition, and where a restarted
+// job should resume its backfill. It plays the same role for copyAlgorithm
+// operations that shadowColumnJob (column_type_change.go) plays for
+// inplace MODIFY COLUMN: both dual-write while backfilling and only swap in
+// the new definition once it has fully caught up, but copyTableJob swaps in
+// a whole new physical table rather than a single shadow column.
+type copyTableJob struct {
+	// OldTableID and NewTableID are the original and shadow table's
+	// physical IDs. Until the job reaches model.StatePublic, both tables
+	// exist: DML dual-writes both, and reads keep using OldTableID.
+	OldTableID int64
+	NewTableID int64
+
+	// DoneHandle is the largest handle already copied into NewTableID,
+	// used as a checkpoint so a restarted job resumes instead of
+	// rescanning the whole table.
+	DoneHandle int64
+}
+
+// errCopyTableJobNotImplemented is returned by buildCopyTableJob until the
+// DDL job handlers that would drive it (onModifyColumn's ActionModifyColumn
+// case and friends in ddl_worker.go) exist in this tree to dispatch a copy
+// job's backfill and atomic swap.
+var errCopyTableJobNotImplemented = errors.New("ddl: ALGORITHM=COPY is resolved but its job handler is not implemented in this build")
+
+// buildCopyTableJob is the entry point ResolveAlterAlgorithm's callers
+// should use once an alterSpec resolves to ast.AlterAlgorithmCopy: it is
+// meant to build and submit a model.Job carrying a copyTableJob, the same
+// way onCreateIndex builds an add-index job for the inplace path. The
+// per-action job handlers that would actually run the backfill and swap
+// (model.ActionModifyColumn and friends, dispatched from
+// worker.runDDLJob) aren't present in this tree, so this returns
+// errCopyTableJobNotImplemented rather than silently no-op'ing; callers
+// should surface that error instead of assuming the rebuild happened.
+func buildCopyTableJob(tableID int64, newTblInfo *model.TableInfo) (*model.Job, error) {
+	return nil, errCopyTableJobNotImplemented
+}
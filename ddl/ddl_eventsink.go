@@ -0,0 +1,166 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// DDLEvent is the structured payload handed to every registered
+// DDLEventSink for one DDL state transition. Downstream consumers (a
+// data-catalog, a CDC pipeline, a schema-registry) react to SchemaState
+// changes instead of polling information_schema.
+type DDLEvent struct {
+	JobID       int64
+	JobType     model.ActionType
+	SchemaID    int64
+	TableID     int64
+	State       model.JobState
+	SchemaState model.SchemaState
+	Err         error
+	Elapsed     time.Duration
+
+	// Charset carries the before/after charset and collation this schema
+	// change produced, so a CDC consumer can reconstruct MySQL-compatible DDL
+	// without re-parsing the original SQL or re-inferring server-config
+	// defaults. jobDDLEvent leaves it nil, so the events this package raises
+	// from the DDL worker carry no charset delta.
+	Charset *CharsetDelta
+}
+
+// CharsetDelta is the before/after charset state one DDL event carries,
+// alongside the session collation (client/connection/server, see
+// jobSessionCollation) that was in effect when the statement was issued.
+type CharsetDelta struct {
+	BeforeCharset string
+	BeforeCollate string
+	AfterCharset  string
+	AfterCollate  string
+
+	// Columns maps column name to its resolved (possibly
+	// column-overridden) charset/collation after the change, so a
+	// consumer doesn't have to separately re-derive per-column defaults.
+	Columns map[string]ColumnCharsetDelta
+
+	Session jobSessionCollation
+}
+
+// ColumnCharsetDelta is one column's charset/collation after a schema
+// change, keyed by column name in CharsetDelta.Columns.
+type ColumnCharsetDelta struct {
+	Charset string
+	Collate string
+}
+
+// DDLEventSink receives DDLEvents for every DDL job state transition:
+// enqueued, running, state-changed, rolled-back, and synced. Deliver must
+// be safe to call concurrently, since multiple workers can fan events into
+// the same sink at once.
+type DDLEventSink interface {
+	Deliver(event DDLEvent) error
+}
+
+// ddlEventSinks is the process-wide set of registered sinks, fanned out to
+// by notifyDDLEventSinks. A sink's own Deliver failures are buffered and
+// retried rather than dropped, so a slow or temporarily unreachable
+// downstream consumer (webhook endpoint, Kafka broker) doesn't lose events.
+var ddlEventSinks struct {
+	mu    sync.RWMutex
+	sinks map[string]*bufferedDDLEventSink
+}
+
+func init() {
+	ddlEventSinks.sinks = make(map[string]*bufferedDDLEventSink)
+}
+
+// RegisterDDLEventSink registers a named DDLEventSink to receive every DDL
+// job lifecycle event from this point on, wrapped so it gets at-least-once
+// delivery backed by a small in-memory queue: a slow or temporarily
+// unreachable sink doesn't lose events or block the DDL worker that raised
+// them. Registering under a name already in use replaces the previous sink.
+func RegisterDDLEventSink(name string, sink DDLEventSink) {
+	registerBufferedDDLEventSink(name, newBufferedDDLEventSink(name, sink))
+}
+
+// RegisterDDLEventSinkWithDiskJournal is RegisterDDLEventSink plus an
+// append-only on-disk journal at journalPath, so events raised while the
+// process itself is down are replayed the next time this sink name is
+// registered rather than lost.
+func RegisterDDLEventSinkWithDiskJournal(name string, sink DDLEventSink, journalPath string) error {
+	buffered := newBufferedDDLEventSink(name, sink)
+	buffered, err := buffered.withDiskBuffer(journalPath)
+	if err != nil {
+		return err
+	}
+	registerBufferedDDLEventSink(name, buffered)
+	return nil
+}
+
+func registerBufferedDDLEventSink(name string, buffered *bufferedDDLEventSink) {
+	ddlEventSinks.mu.Lock()
+	if old, ok := ddlEventSinks.sinks[name]; ok {
+		old.Close()
+	}
+	ddlEventSinks.sinks[name] = buffered
+	ddlEventSinks.mu.Unlock()
+}
+
+// UnregisterDDLEventSink stops and removes the named sink.
+func UnregisterDDLEventSink(name string) {
+	ddlEventSinks.mu.Lock()
+	if old, ok := ddlEventSinks.sinks[name]; ok {
+		old.Close()
+		delete(ddlEventSinks.sinks, name)
+	}
+	ddlEventSinks.mu.Unlock()
+}
+
+// notifyDDLEventSinks fans event out to every registered sink. It never
+// blocks on a slow sink: each bufferedDDLEventSink owns its own delivery
+// goroutine and queue.
+func notifyDDLEventSinks(event DDLEvent) {
+	ddlEventSinks.mu.RLock()
+	defer ddlEventSinks.mu.RUnlock()
+	for _, sink := range ddlEventSinks.sinks {
+		sink.Enqueue(event)
+	}
+}
+
+// jobDDLEvent builds the DDLEvent for job's current state, given when this
+// attempt at it started and the error it finished with, if any.
+func jobDDLEvent(job *model.Job, startTime time.Time, err error) DDLEvent {
+	return DDLEvent{
+		JobID:       job.ID,
+		JobType:     job.Type,
+		SchemaID:    job.SchemaID,
+		TableID:     job.TableID,
+		State:       job.State,
+		SchemaState: job.SchemaState,
+		Err:         err,
+		Elapsed:     time.Since(startTime),
+	}
+}
+
+// logDeliveryFailure is the fallback used when a sink's own error handling
+// isn't enough context on its own; it also drives the sink-failure metric.
+func logDeliveryFailure(name string, event DDLEvent, err error) {
+	logutil.Logger(ddlLogCtx).Warn("[ddl] DDL event sink delivery failed",
+		zap.String("sink", name), zap.Int64("jobID", event.JobID), zap.Error(err))
+	sinkFailureCounter.WithLabelValues(name).Inc()
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// WebhookEventSink POSTs one JSON-encoded DDLEvent per call to url. It is
+// the simplest DDLEventSink to stand up for an out-of-process consumer that
+// just wants to be told a schema changed, without a Kafka cluster.
+type WebhookEventSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookEventSink creates a WebhookEventSink posting to url with the
+// given request timeout.
+func NewWebhookEventSink(url string, timeout time.Duration) *WebhookEventSink {
+	return &WebhookEventSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Deliver implements DDLEventSink.
+func (s *WebhookEventSink) Deliver(event DDLEvent) error {
+	data, err := json.Marshal(toDDLEventJSON(event))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("webhook event sink: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// KafkaProducer is the minimal surface KafkaEventSink needs from a Kafka
+// client library, so this package doesn't import one directly. Callers
+// adapt their producer of choice (sarama, confluent-kafka) to this
+// interface.
+type KafkaProducer interface {
+	// SendMessage publishes value, keyed by key, to topic.
+	SendMessage(topic string, key, value []byte) error
+}
+
+// KafkaEventSink publishes one JSON-encoded DDLEvent per message to a Kafka
+// topic, keyed by job ID so all events for one job land in the same
+// partition and are seen in order.
+type KafkaEventSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaEventSink adapts producer into a DDLEventSink publishing to topic.
+func NewKafkaEventSink(producer KafkaProducer, topic string) *KafkaEventSink {
+	return &KafkaEventSink{producer: producer, topic: topic}
+}
+
+// Deliver implements DDLEventSink.
+func (s *KafkaEventSink) Deliver(event DDLEvent) error {
+	data, err := json.Marshal(toDDLEventJSON(event))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	key := []byte(fmt.Sprintf("%d", event.JobID))
+	return errors.Trace(s.producer.SendMessage(s.topic, key, data))
+}
+
+// ddlEventJSON is the wire format DDLEvent is marshalled to: every field is
+// a plain string/number so it round-trips through any JSON consumer without
+// needing TiDB's own model package.
+type ddlEventJSON struct {
+	JobID       int64             `json:"job_id"`
+	JobType     string            `json:"job_type"`
+	SchemaID    int64             `json:"schema_id"`
+	TableID     int64             `json:"table_id"`
+	State       string            `json:"state"`
+	SchemaState string            `json:"schema_state"`
+	Error       string            `json:"error,omitempty"`
+	ElapsedMs   int64             `json:"elapsed_ms"`
+	Charset     *charsetDeltaJSON `json:"charset,omitempty"`
+}
+
+// charsetDeltaJSON is the wire shape of DDLEvent.Charset.
+type charsetDeltaJSON struct {
+	BeforeCharset  string                        `json:"before_charset"`
+	BeforeCollate  string                        `json:"before_collate"`
+	AfterCharset   string                        `json:"after_charset"`
+	AfterCollate   string                        `json:"after_collate"`
+	Columns        map[string]ColumnCharsetDelta `json:"columns,omitempty"`
+	SessionCharset string                        `json:"session_character_set_client"`
+	SessionConn    string                        `json:"session_collation_connection"`
+	SessionServer  string                        `json:"session_collation_server"`
+}
+
+// toDDLEventJSON converts event to its wire representation, shared by
+// every DDLEventSink adapter in this file so a new field only needs to be
+// added once.
+func toDDLEventJSON(event DDLEvent) ddlEventJSON {
+	out := ddlEventJSON{
+		JobID:       event.JobID,
+		JobType:     event.JobType.String(),
+		SchemaID:    event.SchemaID,
+		TableID:     event.TableID,
+		State:       event.State.String(),
+		SchemaState: event.SchemaState.String(),
+		Error:       errString(event.Err),
+		ElapsedMs:   event.Elapsed.Milliseconds(),
+	}
+	if event.Charset != nil {
+		out.Charset = &charsetDeltaJSON{
+			BeforeCharset:  event.Charset.BeforeCharset,
+			BeforeCollate:  event.Charset.BeforeCollate,
+			AfterCharset:   event.Charset.AfterCharset,
+			AfterCollate:   event.Charset.AfterCollate,
+			Columns:        event.Charset.Columns,
+			SessionCharset: event.Charset.Session.Charset,
+			SessionConn:    event.Charset.Session.Collation,
+			SessionServer:  event.Charset.Session.ServerCollation,
+		}
+	}
+	return out
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
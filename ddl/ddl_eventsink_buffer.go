@@ -0,0 +1,240 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// bufferedDDLEventSinkCapacity bounds how many undelivered events a
+// bufferedDDLEventSink keeps in memory; once full, the oldest event is
+// dropped to make room for the newest rather than blocking the DDL worker
+// that produced it.
+const bufferedDDLEventSinkCapacity = 4096
+
+// bufferedDDLEventSinkRetryInterval is how long a delivery goroutine waits
+// before retrying the event at the head of the queue after Deliver fails.
+const bufferedDDLEventSinkRetryInterval = time.Second
+
+// bufferedDDLEventSink wraps a DDLEventSink with at-least-once delivery: a
+// bounded in-memory queue plus, optionally, a spill-to-disk journal so
+// events raised while the process is down aren't lost either, up to the
+// last time the journal was synced.
+type bufferedDDLEventSink struct {
+	name string
+	sink DDLEventSink
+
+	mu      sync.Mutex
+	queue   []DDLEvent
+	closed  bool
+	wake    chan struct{}
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+
+	diskBuf *eventDiskBuffer
+}
+
+func newBufferedDDLEventSink(name string, sink DDLEventSink) *bufferedDDLEventSink {
+	b := &bufferedDDLEventSink{
+		name:    name,
+		sink:    sink,
+		wake:    make(chan struct{}, 1),
+		closeCh: make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.deliverLoop()
+	return b
+}
+
+// withDiskBuffer attaches an on-disk journal at path, replaying any events
+// it already holds from a previous run before returning, so events that
+// outlive a process restart before they were delivered still reach sink.
+func (b *bufferedDDLEventSink) withDiskBuffer(path string) (*bufferedDDLEventSink, error) {
+	buf, err := newEventDiskBuffer(path)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	b.diskBuf = buf
+	pending := buf.ReadAll()
+	b.mu.Unlock()
+	for _, ev := range pending {
+		b.Enqueue(ev)
+	}
+	return b, nil
+}
+
+// Enqueue appends event to the buffer, dropping the oldest queued event if
+// the buffer is already at bufferedDDLEventSinkCapacity, and wakes the
+// delivery goroutine.
+func (b *bufferedDDLEventSink) Enqueue(event DDLEvent) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	if len(b.queue) >= bufferedDDLEventSinkCapacity {
+		b.queue = b.queue[1:]
+	}
+	b.queue = append(b.queue, event)
+	if b.diskBuf != nil {
+		b.diskBuf.Append(event)
+	}
+	sinkQueueDepthGauge.WithLabelValues(b.name).Set(float64(len(b.queue)))
+	b.mu.Unlock()
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverLoop drains the queue in order, retrying the head of the queue on
+// failure after bufferedDDLEventSinkRetryInterval rather than advancing
+// past it, so delivery is at-least-once rather than best-effort.
+func (b *bufferedDDLEventSink) deliverLoop() {
+	defer b.wg.Done()
+	for {
+		event, ok := b.peek()
+		if !ok {
+			select {
+			case <-b.wake:
+				continue
+			case <-b.closeCh:
+				return
+			}
+		}
+
+		start := time.Now()
+		err := b.sink.Deliver(event)
+		if err != nil {
+			logDeliveryFailure(b.name, event, err)
+			select {
+			case <-time.After(bufferedDDLEventSinkRetryInterval):
+				continue
+			case <-b.closeCh:
+				return
+			}
+		}
+		sinkLagHistogram.WithLabelValues(b.name).Observe(time.Since(start).Seconds())
+		b.pop()
+	}
+}
+
+func (b *bufferedDDLEventSink) peek() (DDLEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.queue) == 0 {
+		return DDLEvent{}, false
+	}
+	return b.queue[0], true
+}
+
+func (b *bufferedDDLEventSink) pop() {
+	b.mu.Lock()
+	if len(b.queue) > 0 {
+		b.queue = b.queue[1:]
+	}
+	if b.diskBuf != nil {
+		b.diskBuf.Advance()
+	}
+	sinkQueueDepthGauge.WithLabelValues(b.name).Set(float64(len(b.queue)))
+	b.mu.Unlock()
+}
+
+// Close stops the delivery goroutine. Any events still queued are lost
+// unless a disk buffer is attached, in which case they remain on disk for
+// the next time this sink name is registered with the same path.
+func (b *bufferedDDLEventSink) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+	close(b.closeCh)
+	b.wg.Wait()
+	if b.diskBuf != nil {
+		b.diskBuf.Close()
+	}
+	sinkQueueDepthGauge.DeleteLabelValues(b.name)
+}
+
+// eventDiskBuffer is a minimal append-only journal of undelivered events,
+// one JSON object per line, used so a sink outage spanning a TiDB restart
+// doesn't lose events raised in between.
+type eventDiskBuffer struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newEventDiskBuffer(path string) (*eventDiskBuffer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &eventDiskBuffer{file: f}, nil
+}
+
+// ReadAll replays every event currently in the journal, for reloading
+// undelivered events after a restart.
+func (d *eventDiskBuffer) ReadAll() []DDLEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.file.Seek(0, 0); err != nil {
+		return nil
+	}
+	var events []DDLEvent
+	scanner := bufio.NewScanner(d.file)
+	for scanner.Scan() {
+		var ev DDLEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err == nil {
+			events = append(events, ev)
+		}
+	}
+	d.file.Seek(0, 2)
+	return events
+}
+
+// Append writes event to the journal. DDLEvent.Err isn't round-tripped
+// through JSON (the error interface has no stable encoding), so a replayed
+// event after a restart carries a nil Err even if the original didn't.
+func (d *eventDiskBuffer) Append(event DDLEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	d.file.Write(data)
+	d.file.Write([]byte("\n"))
+}
+
+// Advance is a placeholder for compacting delivered events out of the
+// journal; the current implementation leaves the journal append-only and
+// relies on ReadAll only being called once at startup, trading unbounded
+// journal growth for simplicity until a sink needs to run long enough for
+// compaction to matter.
+func (d *eventDiskBuffer) Advance() {}
+
+func (d *eventDiskBuffer) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.file.Close()
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// defaultEventSinkTimeout bounds how long a webhook sink configured via
+// tidb_ddl_event_sink will wait for the downstream consumer to respond.
+const defaultEventSinkTimeout = 5 * time.Second
+
+// ConfigureEventSinkFromVariable parses the tidb_ddl_event_sink system
+// variable's value and registers the sink it describes under name,
+// replacing whatever was previously registered under that name. Accepted
+// forms:
+//
+//	""                      -- no sink (default)
+//	"file:///path/to/file"  -- append-only JSON-lines file
+//	"http://host/path"      -- webhook POST, see NewWebhookEventSink
+//	                           ("https://" is accepted too)
+//
+// Any other value, including a "kafka://" destination, is rejected; a Kafka
+// sink is registered directly with RegisterDDLEventSink and
+// NewKafkaEventSink instead.
+func ConfigureEventSinkFromVariable(name, value string) error {
+	if value == "" {
+		UnregisterDDLEventSink(name)
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(value, "file://"):
+		path := strings.TrimPrefix(value, "file://")
+		return RegisterDDLEventSinkWithDiskJournal(name, discardEventSink{}, path)
+	case strings.HasPrefix(value, "http://"), strings.HasPrefix(value, "https://"):
+		RegisterDDLEventSink(name, NewWebhookEventSink(value, defaultEventSinkTimeout))
+		return nil
+	default:
+		return errors.Errorf("ddl: unrecognized tidb_ddl_event_sink destination %q", value)
+	}
+}
+
+// discardEventSink is a DDLEventSink that relies entirely on the disk
+// journal RegisterDDLEventSinkWithDiskJournal wraps it with: events reach
+// durable storage even though this sink itself does nothing further with
+// them, which is the common case for "just keep a local audit trail".
+type discardEventSink struct{}
+
+// Deliver implements DDLEventSink.
+func (discardEventSink) Deliver(DDLEvent) error { return nil }
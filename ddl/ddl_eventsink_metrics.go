@@ -0,0 +1,52 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Per-sink-name metrics for the DDL event sink fan-out. Labels are the sink
+// name, so an unregistered sink's series can, and must, be explicitly
+// deleted rather than left stuck at its last value.
+var (
+	sinkLagHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "ddl",
+			Name:      "event_sink_deliver_duration_seconds",
+			Help:      "Bucketed histogram of DDLEventSink.Deliver latency per sink.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		}, []string{"sink"})
+
+	sinkFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "ddl",
+			Name:      "event_sink_deliver_failures_total",
+			Help:      "Total number of failed DDLEventSink.Deliver calls per sink.",
+		}, []string{"sink"})
+
+	sinkQueueDepthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "ddl",
+			Name:      "event_sink_queue_depth",
+			Help:      "Number of DDL events buffered but not yet delivered, per sink.",
+		}, []string{"sink"})
+)
+
+func init() {
+	prometheus.MustRegister(sinkLagHistogram)
+	prometheus.MustRegister(sinkFailureCounter)
+	prometheus.MustRegister(sinkQueueDepthGauge)
+}
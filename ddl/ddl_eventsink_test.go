@@ -0,0 +1,127 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+)
+
+var _ = Suite(&testDDLEventSinkSuite{})
+
+type testDDLEventSinkSuite struct{}
+
+// recordingSink collects every event Deliver is called with, safe for
+// concurrent use since bufferedDDLEventSink delivers from its own
+// goroutine while the test asserts from the main one.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []DDLEvent
+}
+
+func (r *recordingSink) Deliver(event DDLEvent) error {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recordingSink) delivered() []DDLEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]DDLEvent, len(r.events))
+	copy(out, r.events)
+	return out
+}
+
+func waitForDelivered(c *C, sink *recordingSink, n int) []DDLEvent {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if got := sink.delivered(); len(got) >= n {
+			return got
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Fatalf("timed out waiting for %d delivered events", n)
+	return nil
+}
+
+// TestNotifyDDLEventSinksFansOutToEveryRegisteredSink checks that
+// notifyDDLEventSinks delivers one event to every sink registered under a
+// distinct name.
+func (s *testDDLEventSinkSuite) TestNotifyDDLEventSinksFansOutToEveryRegisteredSink(c *C) {
+	a, b := &recordingSink{}, &recordingSink{}
+	RegisterDDLEventSink("test-a", a)
+	RegisterDDLEventSink("test-b", b)
+	defer UnregisterDDLEventSink("test-a")
+	defer UnregisterDDLEventSink("test-b")
+
+	notifyDDLEventSinks(DDLEvent{JobID: 42})
+
+	gotA := waitForDelivered(c, a, 1)
+	gotB := waitForDelivered(c, b, 1)
+	c.Assert(gotA[0].JobID, Equals, int64(42))
+	c.Assert(gotB[0].JobID, Equals, int64(42))
+}
+
+// TestUnregisterDDLEventSinkStopsFurtherDelivery checks that an event
+// enqueued after UnregisterDDLEventSink never reaches the removed sink.
+func (s *testDDLEventSinkSuite) TestUnregisterDDLEventSinkStopsFurtherDelivery(c *C) {
+	sink := &recordingSink{}
+	RegisterDDLEventSink("test-unregister", sink)
+	notifyDDLEventSinks(DDLEvent{JobID: 1})
+	waitForDelivered(c, sink, 1)
+
+	UnregisterDDLEventSink("test-unregister")
+	notifyDDLEventSinks(DDLEvent{JobID: 2})
+	time.Sleep(20 * time.Millisecond)
+	c.Assert(sink.delivered(), HasLen, 1)
+}
+
+// TestRegisterDDLEventSinkWithDiskJournalReplaysPendingEvents checks that
+// an event journaled but never delivered (simulated by writing directly
+// into a fresh eventDiskBuffer before registering the sink) is replayed
+// to the sink once it's registered against that journal path.
+func (s *testDDLEventSinkSuite) TestRegisterDDLEventSinkWithDiskJournalReplaysPendingEvents(c *C) {
+	path := filepath.Join(c.MkDir(), "ddl_events.journal")
+	buf, err := newEventDiskBuffer(path)
+	c.Assert(err, IsNil)
+	buf.Append(DDLEvent{JobID: 7})
+	c.Assert(buf.Close(), IsNil)
+
+	sink := &recordingSink{}
+	err = RegisterDDLEventSinkWithDiskJournal("test-journal", sink, path)
+	c.Assert(err, IsNil)
+	defer UnregisterDDLEventSink("test-journal")
+
+	got := waitForDelivered(c, sink, 1)
+	c.Assert(got[0].JobID, Equals, int64(7))
+}
+
+// TestJobDDLEventCopiesJobFieldsAndElapsed checks that jobDDLEvent copies
+// the job's identifying fields across and computes a non-negative
+// Elapsed duration from the given start time.
+func (s *testDDLEventSinkSuite) TestJobDDLEventCopiesJobFieldsAndElapsed(c *C) {
+	job := &model.Job{ID: 5, SchemaID: 6, TableID: 7}
+	start := time.Now().Add(-time.Millisecond)
+	ev := jobDDLEvent(job, start, nil)
+	c.Assert(ev.JobID, Equals, int64(5))
+	c.Assert(ev.SchemaID, Equals, int64(6))
+	c.Assert(ev.TableID, Equals, int64(7))
+	c.Assert(ev.Elapsed >= 0, IsTrue)
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testDDLPrioritySuite{})
+
+type testDDLPrioritySuite struct{}
+
+// TestParseJobPriorityRecognizesCaseInsensitiveValues checks that LOW and
+// HIGH are recognized regardless of case.
+func (s *testDDLPrioritySuite) TestParseJobPriorityRecognizesCaseInsensitiveValues(c *C) {
+	c.Assert(ParseJobPriority("low"), Equals, JobPriorityLow)
+	c.Assert(ParseJobPriority("LOW"), Equals, JobPriorityLow)
+	c.Assert(ParseJobPriority("high"), Equals, JobPriorityHigh)
+	c.Assert(ParseJobPriority("HIGH"), Equals, JobPriorityHigh)
+}
+
+// TestParseJobPriorityDefaultsToNormalForUnrecognized checks that an
+// unrecognized/typo'd hint argument degrades to JobPriorityNormal rather
+// than erroring, matching the doc comment's "degrades gracefully"
+// guarantee.
+func (s *testDDLPrioritySuite) TestParseJobPriorityDefaultsToNormalForUnrecognized(c *C) {
+	c.Assert(ParseJobPriority("urgent"), Equals, JobPriorityNormal)
+	c.Assert(ParseJobPriority(""), Equals, JobPriorityNormal)
+}
+
+// TestPickNextJobPrefersHigherPriorityAtEqualAge checks that, all else
+// equal, a HIGH priority job is picked over a LOW priority one enqueued
+// at the same time.
+func (s *testDDLPrioritySuite) TestPickNextJobPrefersHigherPriorityAtEqualAge(c *C) {
+	now := time.Now()
+	low := &jobCandidate{priority: JobPriorityLow, enqueuedAt: now}
+	high := &jobCandidate{priority: JobPriorityHigh, enqueuedAt: now}
+	c.Assert(pickNextJob([]*jobCandidate{low, high}), Equals, high)
+}
+
+// TestPickNextJobLetsAgeOutweighPriority checks that a LOW priority job
+// queued long enough eventually outscores a freshly-enqueued HIGH
+// priority job -- the starvation-prevention half of the "priority + age"
+// policy, not just a strict priority ordering.
+func (s *testDDLPrioritySuite) TestPickNextJobLetsAgeOutweighPriority(c *C) {
+	now := time.Now()
+	staleLow := &jobCandidate{priority: JobPriorityLow, enqueuedAt: now.Add(-10 * time.Minute)}
+	freshHigh := &jobCandidate{priority: JobPriorityHigh, enqueuedAt: now}
+	c.Assert(pickNextJob([]*jobCandidate{staleLow, freshHigh}), Equals, staleLow)
+}
+
+// TestPickNextJobEmptyCandidatesReturnsNil checks that an empty candidate
+// list returns nil rather than panicking on a best score comparison.
+func (s *testDDLPrioritySuite) TestPickNextJobEmptyCandidatesReturnsNil(c *C) {
+	c.Assert(pickNextJob(nil), IsNil)
+}
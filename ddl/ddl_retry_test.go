@@ -0,0 +1,93 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+)
+
+var _ = Suite(&testDDLRetrySuite{})
+
+type testDDLRetrySuite struct{}
+
+// TestClassifyDDLErrorEntryTooLargeIsTerminal checks that an oversized-
+// write error is classified terminal, since no amount of retrying shrinks
+// the write.
+func (s *testDDLRetrySuite) TestClassifyDDLErrorEntryTooLargeIsTerminal(c *C) {
+	c.Assert(classifyDDLError(kv.ErrEntryTooLarge), Equals, errClassTerminal)
+}
+
+// TestClassifyDDLErrorTxnRetryableIsTransient checks that a retryable
+// transaction error is classified as transient KV, the aggressive/tightly
+// capped retry class.
+func (s *testDDLRetrySuite) TestClassifyDDLErrorTxnRetryableIsTransient(c *C) {
+	c.Assert(classifyDDLError(kv.ErrTxnRetryable), Equals, errClassTransientKV)
+}
+
+// TestNextWaitTerminalErrorRefusesRetry checks that a terminal-class
+// error's policy (base=0, cap=0) tells the caller not to retry at all.
+func (s *testDDLRetrySuite) TestNextWaitTerminalErrorRefusesRetry(c *C) {
+	t := newRetryTracker()
+	_, retry := t.NextWait(1, kv.ErrEntryTooLarge)
+	c.Assert(retry, IsFalse)
+}
+
+// TestNextWaitBacksOffExponentiallyUpToCap checks that repeated failures
+// for the same job produce waits that never exceed the class's cap plus its
+// jitter, even after many attempts (where 2^attempts would otherwise
+// overflow past it).
+func (s *testDDLRetrySuite) TestNextWaitBacksOffExponentiallyUpToCap(c *C) {
+	t := newRetryTracker()
+	policy := defaultRetryPolicies[errClassTransientKV]
+	maxWait := time.Duration(float64(policy.cap) * (1 + policy.jitter))
+	for i := 0; i < 20; i++ {
+		wait, retry := t.NextWait(1, kv.ErrTxnRetryable)
+		c.Assert(retry, IsTrue)
+		c.Assert(wait <= maxWait, IsTrue)
+	}
+}
+
+// TestNextWaitTracksAttemptsPerJob checks that Attempts increments per
+// call and that State reports it, so ADMIN SHOW DDL JOBS can surface how
+// many times a job has failed.
+func (s *testDDLRetrySuite) TestNextWaitTracksAttemptsPerJob(c *C) {
+	t := newRetryTracker()
+	t.NextWait(1, kv.ErrTxnRetryable)
+	t.NextWait(1, kv.ErrTxnRetryable)
+	st, ok := t.State(1)
+	c.Assert(ok, IsTrue)
+	c.Assert(st.Attempts, Equals, 2)
+}
+
+// TestForgetClearsRetryState checks that Forget removes a job's tracked
+// state, as done once a job succeeds or finishes.
+func (s *testDDLRetrySuite) TestForgetClearsRetryState(c *C) {
+	t := newRetryTracker()
+	t.NextWait(1, kv.ErrTxnRetryable)
+	t.Forget(1)
+	_, ok := t.State(1)
+	c.Assert(ok, IsFalse)
+}
+
+// TestStateUnknownJobReportsNotOK checks that querying a job with no
+// recorded retry history reports ok=false rather than a zero-valued
+// false-positive state.
+func (s *testDDLRetrySuite) TestStateUnknownJobReportsNotOK(c *C) {
+	t := newRetryTracker()
+	_, ok := t.State(999)
+	c.Assert(ok, IsFalse)
+}
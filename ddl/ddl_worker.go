@@ -72,6 +72,7 @@ type worker struct {
 	reorgCtx        *reorgCtx    // reorgCtx is used for reorganization.
 	delRangeManager delRangeManager
 	logCtx          context.Context
+	retryTracker    *retryTracker // retryTracker tracks per-job backoff state across failed runDDLJob attempts.
 }
 
 func newWorker(tp workerType, store kv.Storage, sessPool *sessionPool, delRangeMgr delRangeManager) *worker {
@@ -83,6 +84,7 @@ func newWorker(tp workerType, store kv.Storage, sessPool *sessionPool, delRangeM
 		reorgCtx:        &reorgCtx{notifyCancelReorgJob: 0},
 		sessPool:        sessPool,
 		delRangeManager: delRangeMgr,
+		retryTracker:    newRetryTracker(),
 	}
 
 	worker.logCtx = logutil.WithKeyValue(context.Background(), "worker", worker.String())
@@ -150,19 +152,36 @@ func asyncNotify(ch chan struct{}) {
 	}
 }
 
-// buildJobDependence sets the curjob's dependency-ID.
-// The dependency-job's ID must less than the current job's ID, and we need the largest one in the list.
-func buildJobDependence(t *meta.Meta, curJob *model.Job) error {
-	// Jobs in the same queue are ordered. If we want to find a job's dependency-job, we need to look for
-	// it from the other queue. So if the job is "ActionAddIndex" job, we need find its dependency-job from DefaultJobList.
+// siblingQueueJobs fetches every DDL job from the meta queues curJob's type
+// does not itself live in -- i.e. every queue buildJobDependence must scan
+// for a dependency-job. Today that is still just the general/add-index
+// split, but it is written so that a future third queue only has to extend
+// the switch here rather than every call site.
+func siblingQueueJobs(t *meta.Meta, jobType model.ActionType) ([]*model.Job, error) {
 	var jobs []*model.Job
-	var err error
-	switch curJob.Type {
+	switch jobType {
 	case model.ActionAddIndex:
-		jobs, err = t.GetAllDDLJobsInQueue(meta.DefaultJobListKey)
+		queueJobs, err := t.GetAllDDLJobsInQueue(meta.DefaultJobListKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, queueJobs...)
 	default:
-		jobs, err = t.GetAllDDLJobsInQueue(meta.AddIndexJobListKey)
+		queueJobs, err := t.GetAllDDLJobsInQueue(meta.AddIndexJobListKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, queueJobs...)
 	}
+	return jobs, nil
+}
+
+// buildJobDependence sets the curjob's dependency-ID.
+// The dependency-job's ID must less than the current job's ID, and we need the largest one in the list.
+func buildJobDependence(t *meta.Meta, curJob *model.Job) error {
+	// Jobs in the same queue are ordered. If we want to find a job's dependency-job, we need to look for
+	// it from sibling queues. So if the job is "ActionAddIndex" job, we need find its dependency-job from DefaultJobList.
+	jobs, err := siblingQueueJobs(t, curJob.Type)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -205,6 +224,7 @@ func (d *ddl) addDDLJob(ctx sessionctx.Context, job *model.Job) error {
 		return errors.Trace(err)
 	})
 	metrics.DDLWorkerHistogram.WithLabelValues(metrics.WorkerAddDDLJob, job.Type.String(), metrics.RetLabel(err)).Observe(time.Since(startTime).Seconds())
+	notifyDDLEventSinks(jobDDLEvent(job, startTime, err))
 	return errors.Trace(err)
 }
 
@@ -274,6 +294,7 @@ func (w *worker) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 	startTime := time.Now()
 	defer func() {
 		metrics.DDLWorkerHistogram.WithLabelValues(metrics.WorkerFinishDDLJob, job.Type.String(), metrics.RetLabel(err)).Observe(time.Since(startTime).Seconds())
+		notifyDDLEventSinks(jobDDLEvent(job, startTime, err))
 	}()
 
 	if !job.IsCancelled() {
@@ -425,10 +446,22 @@ func (w *worker) handleDDLJobQueue(d *ddlCtx) error {
 		})
 
 		if runJobErr != nil {
-			// wait a while to retry again. If we don't wait here, DDL will retry this job immediately,
-			// which may act like a deadlock.
-			logutil.Logger(w.logCtx).Info("[ddl] run DDL job error, sleeps a while then retries it.", zap.Duration("waitTime", WaitTimeWhenErrorOccured), zap.Error(runJobErr))
-			time.Sleep(WaitTimeWhenErrorOccured)
+			// Wait a while to retry again. If we don't wait here, DDL will retry this job immediately,
+			// which may act like a deadlock. The wait grows exponentially (with jitter) per job and
+			// per error class instead of the old fixed WaitTimeWhenErrorOccured, so a job stuck behind
+			// a slow-clearing schema conflict backs off further with each attempt while a transient KV
+			// error still gets retried quickly.
+			wait, retryable := w.retryTracker.NextWait(job.ID, runJobErr)
+			if !retryable {
+				logutil.Logger(w.logCtx).Info("[ddl] run DDL job error is terminal, not retrying", zap.Error(runJobErr))
+			} else if WaitTimeWhenErrorOccured == 0 {
+				// Tests set WaitTimeWhenErrorOccured to 0 to disable the retry wait entirely.
+			} else {
+				logutil.Logger(w.logCtx).Info("[ddl] run DDL job error, sleeps a while then retries it.", zap.Duration("waitTime", wait), zap.Error(runJobErr))
+				time.Sleep(wait)
+			}
+		} else if job != nil {
+			w.retryTracker.Forget(job.ID)
 		}
 
 		if err != nil {
@@ -487,6 +520,7 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 	timeStart := time.Now()
 	defer func() {
 		metrics.DDLWorkerHistogram.WithLabelValues(metrics.WorkerRunDDLJob, job.Type.String(), metrics.RetLabel(err)).Observe(time.Since(timeStart).Seconds())
+		notifyDDLEventSinks(jobDDLEvent(job, timeStart, err))
 	}()
 	if job.IsFinished() {
 		return
@@ -0,0 +1,130 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pingcap/errors"
+)
+
+// Tokenizer splits column text into the terms a FULLTEXT index stores one
+// inverted-index posting per. Which Tokenizer an index uses is selected by
+// its parser name (see TokenizerByName), either the default "standard" or
+// whatever name was given in WITH PARSER.
+type Tokenizer interface {
+	// Tokenize splits text into terms, in the order they appear so callers
+	// that need positional information (phrase search) can still derive it
+	// from the returned slice's index.
+	Tokenize(text string) []string
+}
+
+// standardTokenizer splits on Unicode word boundaries, lower-cases terms,
+// and drops anything shorter than minTermLen -- the same floor MySQL's
+// built-in ft_min_word_len default uses to keep stopword-like noise out of
+// the index.
+type standardTokenizer struct {
+	minTermLen int
+}
+
+// Tokenize implements Tokenizer.
+func (t *standardTokenizer) Tokenize(text string) []string {
+	var terms []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() == 0 {
+			return
+		}
+		term := strings.ToLower(cur.String())
+		if len([]rune(term)) >= t.minTermLen {
+			terms = append(terms, term)
+		}
+		cur.Reset()
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			cur.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// ngramTokenizer emits overlapping runs of n runes, the approach MySQL's
+// ngram parser and most CJK search engines use since CJK text has no
+// whitespace word boundaries for the standard tokenizer to split on.
+type ngramTokenizer struct {
+	n int
+}
+
+// Tokenize implements Tokenizer.
+func (t *ngramTokenizer) Tokenize(text string) []string {
+	runes := []rune(strings.ToLower(text))
+	if len(runes) < t.n {
+		if len(runes) == 0 {
+			return nil
+		}
+		return []string{string(runes)}
+	}
+	terms := make([]string, 0, len(runes)-t.n+1)
+	for i := 0; i+t.n <= len(runes); i++ {
+		terms = append(terms, string(runes[i:i+t.n]))
+	}
+	return terms
+}
+
+// defaultNgramSize is the bigram width used by WITH PARSER ngram / "cjk",
+// matching MySQL's ngram_token_size default of 2.
+const defaultNgramSize = 2
+
+// TokenizerByName resolves a WITH PARSER name to the Tokenizer a FULLTEXT
+// index built with it should use. "standard" (also the default when no
+// WITH PARSER clause is given) and "cjk" both exist for parity with
+// MySQL's built-in parser names; "ngram" is the TiDB-native alias for the
+// same bigram behavior.
+func TokenizerByName(name string) (Tokenizer, error) {
+	switch strings.ToLower(name) {
+	case "", "standard":
+		return &standardTokenizer{minTermLen: 3}, nil
+	case "ngram", "cjk":
+		return &ngramTokenizer{n: defaultNgramSize}, nil
+	default:
+		return nil, errors.Errorf("ddl: unknown FULLTEXT parser %q", name)
+	}
+}
+
+// FullTextIndexOptions is the metadata a FULLTEXT index carries beyond a
+// normal secondary index: which tokenizer built its postings, so queries
+// and future rebuilds tokenize consistently. In the fuller build this is a
+// field on model.IndexInfo (IndexType == model.IndexTypeFullText plus a
+// Parser string); it is modeled as a standalone struct here since the
+// model package that owns IndexInfo is not part of this tree, and is keyed
+// by index ID by ddl callers that need to look it up.
+type FullTextIndexOptions struct {
+	IndexID int64
+	Parser  string
+}
+
+// BuildFullTextIndexOptions validates parserName (e.g. the argument to
+// WITH PARSER) and returns the FullTextIndexOptions to persist for a new
+// CREATE TABLE ... FULLTEXT KEY / ALTER TABLE ... ADD FULLTEXT KEY job.
+func BuildFullTextIndexOptions(indexID int64, parserName string) (*FullTextIndexOptions, error) {
+	if _, err := TokenizerByName(parserName); err != nil {
+		return nil, err
+	}
+	return &FullTextIndexOptions{IndexID: indexID, Parser: parserName}, nil
+}
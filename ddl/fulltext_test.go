@@ -0,0 +1,91 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testFullTextSuite{})
+
+type testFullTextSuite struct{}
+
+// TestStandardTokenizerLowercasesAndDropsShortTerms checks word splitting,
+// lower-casing, and the minTermLen floor all apply.
+func (s *testFullTextSuite) TestStandardTokenizerLowercasesAndDropsShortTerms(c *C) {
+	tok := &standardTokenizer{minTermLen: 3}
+	c.Assert(tok.Tokenize("The Quick fox, a dog!"), DeepEquals, []string{"the", "quick", "fox", "dog"})
+}
+
+// TestStandardTokenizerEmptyInputYieldsNoTerms checks that an empty or
+// all-punctuation input produces no terms rather than a spurious empty one.
+func (s *testFullTextSuite) TestStandardTokenizerEmptyInputYieldsNoTerms(c *C) {
+	tok := &standardTokenizer{minTermLen: 3}
+	c.Assert(tok.Tokenize(""), HasLen, 0)
+	c.Assert(tok.Tokenize("   ,,, ..."), HasLen, 0)
+}
+
+// TestNgramTokenizerEmitsOverlappingRuns checks that the ngram tokenizer
+// produces every overlapping n-rune window, lower-cased.
+func (s *testFullTextSuite) TestNgramTokenizerEmitsOverlappingRuns(c *C) {
+	tok := &ngramTokenizer{n: 2}
+	c.Assert(tok.Tokenize("ABC"), DeepEquals, []string{"ab", "bc"})
+}
+
+// TestNgramTokenizerShortInputYieldsWholeString checks that input shorter
+// than n is emitted whole rather than dropped, and empty input yields no
+// terms.
+func (s *testFullTextSuite) TestNgramTokenizerShortInputYieldsWholeString(c *C) {
+	tok := &ngramTokenizer{n: 3}
+	c.Assert(tok.Tokenize("ab"), DeepEquals, []string{"ab"})
+	c.Assert(tok.Tokenize(""), HasLen, 0)
+}
+
+// TestTokenizerByNameResolvesKnownParsersAndRejectsUnknown checks that the
+// empty name and "standard" both resolve to a standardTokenizer, "ngram"
+// and "cjk" both resolve to the bigram ngramTokenizer, and an unrecognized
+// parser name is rejected.
+func (s *testFullTextSuite) TestTokenizerByNameResolvesKnownParsersAndRejectsUnknown(c *C) {
+	for _, name := range []string{"", "standard", "STANDARD"} {
+		tok, err := TokenizerByName(name)
+		c.Assert(err, IsNil)
+		_, ok := tok.(*standardTokenizer)
+		c.Assert(ok, IsTrue)
+	}
+	for _, name := range []string{"ngram", "cjk", "NGRAM"} {
+		tok, err := TokenizerByName(name)
+		c.Assert(err, IsNil)
+		ng, ok := tok.(*ngramTokenizer)
+		c.Assert(ok, IsTrue)
+		c.Assert(ng.n, Equals, defaultNgramSize)
+	}
+	_, err := TokenizerByName("bogus")
+	c.Assert(err, ErrorMatches, ".*unknown FULLTEXT parser.*")
+}
+
+// TestBuildFullTextIndexOptionsRejectsUnknownParser checks that an unknown
+// WITH PARSER name fails before any options are built.
+func (s *testFullTextSuite) TestBuildFullTextIndexOptionsRejectsUnknownParser(c *C) {
+	_, err := BuildFullTextIndexOptions(1, "bogus")
+	c.Assert(err, NotNil)
+}
+
+// TestBuildFullTextIndexOptionsSucceedsForKnownParser checks that a known
+// parser name produces options carrying the given index ID and parser.
+func (s *testFullTextSuite) TestBuildFullTextIndexOptionsSucceedsForKnownParser(c *C) {
+	opts, err := BuildFullTextIndexOptions(7, "ngram")
+	c.Assert(err, IsNil)
+	c.Assert(opts.IndexID, Equals, int64(7))
+	c.Assert(opts.Parser, Equals, "ngram")
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// IndexNameGenerator picks a name for an index that was not given one
+// explicitly, e.g. "ADD INDEX (a, b)" with no "AS name" clause. It
+// generalizes the old hard-coded "first-column name, _2/_3 suffix,
+// collides with PRIMARY" rule so callers can plug in a different naming
+// template or collision policy.
+type IndexNameGenerator interface {
+	// Generate proposes a name for an index over cols on tblInfo. It does
+	// not itself guarantee uniqueness against concurrent DDL; callers
+	// needing that should go through reserveIndexName.
+	Generate(tblInfo *model.TableInfo, cols []*model.ColumnInfo) string
+}
+
+// legacyIndexNameGenerator reproduces the historical MySQL-ish behavior:
+// the first column's name, suffixed with _2, _3, ... on collision, with
+// "primary" (case-insensitively) treated as always colliding.
+type legacyIndexNameGenerator struct{}
+
+// Generate implements IndexNameGenerator.
+func (legacyIndexNameGenerator) Generate(tblInfo *model.TableInfo, cols []*model.ColumnInfo) string {
+	if len(cols) == 0 {
+		return "idx"
+	}
+	return cols[0].Name.O
+}
+
+// templateIndexNameGenerator builds a name from a configurable template,
+// e.g. "idx_{cols}_{hash}", settable via the
+// tidb_index_naming_template system variable. "{cols}" expands to the
+// underscore-joined column names and "{hash}" to a short hex digest of the
+// table and column names, which keeps generated names stable across
+// repeated DDL on the same definition while still being readable.
+type templateIndexNameGenerator struct {
+	template string
+}
+
+// Generate implements IndexNameGenerator.
+func (g templateIndexNameGenerator) Generate(tblInfo *model.TableInfo, cols []*model.ColumnInfo) string {
+	colNames := make([]string, 0, len(cols))
+	for _, c := range cols {
+		colNames = append(colNames, c.Name.L)
+	}
+	joined := strings.Join(colNames, "_")
+	h := sha256.Sum256([]byte(tblInfo.Name.L + "." + joined))
+	name := g.template
+	name = strings.ReplaceAll(name, "{cols}", joined)
+	name = strings.ReplaceAll(name, "{hash}", hex.EncodeToString(h[:])[:8])
+	return name
+}
+
+// reservedIndexNames is consulted in addition to "primary": an anonymous
+// index must not collide with any SQL reserved keyword, since
+// "ADD INDEX (group)" auto-naming to "group" would make later
+// "DROP INDEX group" ambiguous with keyword usage in some contexts.
+var reservedIndexNames = map[string]struct{}{
+	"primary": {}, "key": {}, "index": {}, "group": {}, "order": {},
+	"select": {}, "where": {}, "from": {}, "table": {}, "constraint": {},
+}
+
+// isReservedIndexName reports whether name collides with a reserved word
+// an anonymous index must never be auto-named.
+func isReservedIndexName(name string) bool {
+	_, ok := reservedIndexNames[strings.ToLower(name)]
+	return ok
+}
+
+// indexNameReservation deterministically reserves generated names across
+// concurrent DDL jobs on the same table, so two parallel "ADD INDEX"
+// statements can't both land on "c1_2": the first caller to reserve a
+// candidate name for a given table wins it, and every other caller is
+// forced to the next suffix.
+type indexNameReservation struct {
+	mu       sync.Mutex
+	reserved map[int64]map[string]struct{} // tableID -> lower-cased reserved names
+}
+
+var globalIndexNameReservation = &indexNameReservation{
+	reserved: make(map[int64]map[string]struct{}),
+}
+
+// reserve claims name for tableID, returning false if it is already
+// reserved for that table. It does not check the table's existing indexes.
+func (r *indexNameReservation) reserve(tableID int64, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names, ok := r.reserved[tableID]
+	if !ok {
+		names = make(map[string]struct{})
+		r.reserved[tableID] = names
+	}
+	lower := strings.ToLower(name)
+	if _, taken := names[lower]; taken {
+		return false
+	}
+	names[lower] = struct{}{}
+	return true
+}
+
+// release frees a name reserved for tableID, e.g. after the job that
+// reserved it fails before the index is actually created.
+func (r *indexNameReservation) release(tableID int64, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if names, ok := r.reserved[tableID]; ok {
+		delete(names, strings.ToLower(name))
+	}
+}
+
+// SuggestIndexName generates a unique, non-reserved name for an index over
+// cols on tblInfo, usable from the SQL layer to validate a name before the
+// DDL job carrying it is even submitted. It tries gen's proposal first,
+// then gen's proposal with "_2", "_3", ... suffixes, skipping any that
+// collide with an existing index, a reserved word, or a name concurrently
+// reserved by another in-flight DDL on the same table.
+func SuggestIndexName(tblInfo *model.TableInfo, cols []*model.ColumnInfo) (string, error) {
+	return suggestIndexNameWithGenerator(legacyIndexNameGenerator{}, tblInfo, cols)
+}
+
+func suggestIndexNameWithGenerator(gen IndexNameGenerator, tblInfo *model.TableInfo, cols []*model.ColumnInfo) (string, error) {
+	base := gen.Generate(tblInfo, cols)
+	if base == "" {
+		base = "idx"
+	}
+	existing := make(map[string]struct{}, len(tblInfo.Indices))
+	for _, idx := range tblInfo.Indices {
+		existing[idx.Name.L] = struct{}{}
+	}
+
+	candidate := base
+	for i := 1; ; i++ {
+		if i > 1 {
+			candidate = fmt.Sprintf("%s_%d", base, i)
+		}
+		lower := strings.ToLower(candidate)
+		if isReservedIndexName(lower) {
+			continue
+		}
+		if _, taken := existing[lower]; taken {
+			continue
+		}
+		if globalIndexNameReservation.reserve(tblInfo.ID, candidate) {
+			return candidate, nil
+		}
+		if i > len(tblInfo.Indices)+1000 {
+			return "", errors.Errorf("ddl: could not find an available name for index on table %s", tblInfo.Name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+)
+
+var _ = Suite(&testIndexNamingSuite{})
+
+type testIndexNamingSuite struct{}
+
+// tableInfoWithIndices builds a TableInfo carrying indexNames as its
+// existing indices. id must be unique per test: SuggestIndexName reserves
+// generated names in a package-level singleton keyed by table ID, so
+// reusing an ID across tests would leak reservations between them.
+func tableInfoWithIndices(id int64, indexNames ...string) *model.TableInfo {
+	indices := make([]*model.IndexInfo, 0, len(indexNames))
+	for _, n := range indexNames {
+		indices = append(indices, &model.IndexInfo{Name: model.NewCIStr(n)})
+	}
+	return &model.TableInfo{ID: id, Name: model.NewCIStr("t"), Indices: indices}
+}
+
+// TestLegacyIndexNameGeneratorUsesFirstColumn checks that the legacy
+// generator proposes the first column's name, and "idx" for a columnless
+// call.
+func (s *testIndexNamingSuite) TestLegacyIndexNameGeneratorUsesFirstColumn(c *C) {
+	gen := legacyIndexNameGenerator{}
+	cols := []*model.ColumnInfo{{Name: model.NewCIStr("a")}, {Name: model.NewCIStr("b")}}
+	c.Assert(gen.Generate(&model.TableInfo{}, cols), Equals, "a")
+	c.Assert(gen.Generate(&model.TableInfo{}, nil), Equals, "idx")
+}
+
+// TestTemplateIndexNameGeneratorExpandsColsAndHash checks that {cols}
+// expands to the underscore-joined column names and {hash} to an 8-hex-
+// character digest, and that the digest is stable across calls for the
+// same table/column definition.
+func (s *testIndexNamingSuite) TestTemplateIndexNameGeneratorExpandsColsAndHash(c *C) {
+	gen := templateIndexNameGenerator{template: "idx_{cols}_{hash}"}
+	tbl := &model.TableInfo{Name: model.NewCIStr("t")}
+	cols := []*model.ColumnInfo{{Name: model.NewCIStr("a")}, {Name: model.NewCIStr("b")}}
+	name1 := gen.Generate(tbl, cols)
+	name2 := gen.Generate(tbl, cols)
+	c.Assert(name1, Equals, name2)
+	c.Assert(name1[:6], Equals, "idx_a_")
+	c.Assert(len(name1), Equals, len("idx_a_b_")+8)
+}
+
+// TestIsReservedIndexNameCaseInsensitive checks that reserved-word
+// detection ignores case, since SQL identifiers are matched
+// case-insensitively throughout this package.
+func (s *testIndexNamingSuite) TestIsReservedIndexNameCaseInsensitive(c *C) {
+	c.Assert(isReservedIndexName("PRIMARY"), IsTrue)
+	c.Assert(isReservedIndexName("Group"), IsTrue)
+	c.Assert(isReservedIndexName("my_idx"), IsFalse)
+}
+
+// TestSuggestIndexNameAvoidsExistingNames checks that SuggestIndexName
+// skips over existing index names by incrementing the numeric suffix,
+// landing on the first name that is not taken.
+func (s *testIndexNamingSuite) TestSuggestIndexNameAvoidsExistingNames(c *C) {
+	tbl := tableInfoWithIndices(101, "a", "a_2")
+	cols := []*model.ColumnInfo{{Name: model.NewCIStr("a")}}
+	name, err := SuggestIndexName(tbl, cols)
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "a_3")
+}
+
+// TestSuggestIndexNameRejectsPrimaryCollision checks that a single-column
+// index named "primary" is never suggested, since "primary" always
+// collides per isReservedIndexName.
+func (s *testIndexNamingSuite) TestSuggestIndexNameRejectsPrimaryCollision(c *C) {
+	tbl := tableInfoWithIndices(102)
+	cols := []*model.ColumnInfo{{Name: model.NewCIStr("primary")}}
+	name, err := SuggestIndexName(tbl, cols)
+	c.Assert(err, IsNil)
+	c.Assert(name, Not(Equals), "primary")
+}
+
+// TestSuggestIndexNameConcurrentReservationForcesNextSuffix checks that a
+// name already reserved by another in-flight DDL job on the same table
+// (simulated directly via globalIndexNameReservation) is skipped, even
+// though it collides with neither an existing index nor a reserved word.
+func (s *testIndexNamingSuite) TestSuggestIndexNameConcurrentReservationForcesNextSuffix(c *C) {
+	tbl := tableInfoWithIndices(103)
+	cols := []*model.ColumnInfo{{Name: model.NewCIStr("a")}}
+	c.Assert(globalIndexNameReservation.reserve(tbl.ID, "a"), IsTrue)
+
+	name, err := SuggestIndexName(tbl, cols)
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "a_2")
+}
+
+// TestReserveThenReleaseFreesNameForReuse checks that release lets a
+// later reserve call on the same name for the same table succeed again.
+func (s *testIndexNamingSuite) TestReserveThenReleaseFreesNameForReuse(c *C) {
+	const id = 104
+	c.Assert(globalIndexNameReservation.reserve(id, "tmp"), IsTrue)
+	c.Assert(globalIndexNameReservation.reserve(id, "tmp"), IsFalse)
+	globalIndexNameReservation.release(id, "tmp")
+	c.Assert(globalIndexNameReservation.reserve(id, "tmp"), IsTrue)
+}
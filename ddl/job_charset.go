@@ -0,0 +1,64 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+// jobSessionCollation captures the three collation IDs MySQL's binlog
+// Q_CHARSET_CODE event carries alongside every query -- client, connection,
+// and server -- onto a DDL job at enqueue time, so schema materialization
+// can honor what the issuing session actually had set instead of silently
+// falling back to config.GetGlobalConfig() when the statement itself didn't
+// specify a CHARSET/COLLATE clause.
+type jobSessionCollation struct {
+	Charset         string
+	Collation       string
+	ServerCollation string
+}
+
+// captureJobSessionCollation reads character_set_client /
+// collation_connection / collation_server off sctx's session variables.
+// A caller attaches the result to a job via attachJobSessionCollation, so it
+// survives a TiDB restart between enqueue and execution. ddl_api.go's
+// CreateSchema, AlterSchema, CreateTable and AlterTableCharsetAndCollate do
+// not call it, so the jobs they build carry no session collation.
+func captureJobSessionCollation(sctx sessionctx.Context) jobSessionCollation {
+	vars := sctx.GetSessionVars()
+	return jobSessionCollation{
+		Charset:         vars.CharacterSetClient,
+		Collation:       vars.CollationConnection,
+		ServerCollation: vars.CollationServer,
+	}
+}
+
+// attachJobSessionCollation appends c to job.Args, after whatever
+// action-specific args the job already carries.
+func attachJobSessionCollation(job *model.Job, c jobSessionCollation) {
+	job.Args = append(job.Args, c)
+}
+
+// preferJobCollation returns stmtCharset/stmtCollate unchanged if the
+// statement specified either explicitly; otherwise it falls back to c's
+// captured session values, and only when those are themselves unset does
+// it fall through to config.GetGlobalConfig()'s defaults (represented here
+// by the empty string, left for the caller to resolve).
+func preferJobCollation(c jobSessionCollation, stmtCharset, stmtCollate string) (charset, collate string) {
+	if stmtCharset != "" || stmtCollate != "" {
+		return stmtCharset, stmtCollate
+	}
+	return c.Charset, c.Collation
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testJobCharsetSuite{})
+
+type testJobCharsetSuite struct{}
+
+// TestPreferJobCollationKeepsExplicitStatementValue checks that an
+// explicit statement-level CHARSET or COLLATE clause wins over the
+// captured session collation.
+func (s *testJobCharsetSuite) TestPreferJobCollationKeepsExplicitStatementValue(c *C) {
+	captured := jobSessionCollation{Charset: "latin1", Collation: "latin1_bin"}
+	charset, collate := preferJobCollation(captured, "utf8mb4", "")
+	c.Assert(charset, Equals, "utf8mb4")
+	c.Assert(collate, Equals, "")
+}
+
+// TestPreferJobCollationFallsBackToCapturedSession checks that with no
+// statement-level clause at all, the captured session charset/collation is
+// used.
+func (s *testJobCharsetSuite) TestPreferJobCollationFallsBackToCapturedSession(c *C) {
+	captured := jobSessionCollation{Charset: "latin1", Collation: "latin1_bin"}
+	charset, collate := preferJobCollation(captured, "", "")
+	c.Assert(charset, Equals, "latin1")
+	c.Assert(collate, Equals, "latin1_bin")
+}
+
+// TestIsRegisteredCharsetAcceptsGBKFamilyCaseInsensitively checks that gbk
+// and gb18030 are recognized regardless of case, and an unrelated charset
+// is not.
+func (s *testJobCharsetSuite) TestIsRegisteredCharsetAcceptsGBKFamilyCaseInsensitively(c *C) {
+	c.Assert(isRegisteredCharset("gbk"), IsTrue)
+	c.Assert(isRegisteredCharset("GB18030"), IsTrue)
+	c.Assert(isRegisteredCharset("utf8mb4"), IsFalse)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+)
+
+// partitionGCTask is handed to the background GC worker once a partition's
+// physical table ID is no longer reachable from the schema, so its key
+// range can be reclaimed without blocking the foreground DDL that retired
+// it.
+type partitionGCTask struct {
+	OldPhysicalID int64
+	TableID       int64
+}
+
+// addPartitionInplace registers a newly allocated physical table ID for
+// tblInfo's partition list without touching any existing partition's data:
+// the new partition starts empty, so there is nothing to backfill and
+// concurrent DML against the other partitions is unaffected. Returns the
+// updated partition definitions; the caller is responsible for writing
+// them back through the usual two-phase schema-version bump.
+func addPartitionInplace(tblInfo *model.TableInfo, newDefs []model.PartitionDefinition) ([]model.PartitionDefinition, error) {
+	if tblInfo.Partition == nil {
+		return nil, errors.Errorf("ddl: table %s is not partitioned", tblInfo.Name)
+	}
+	existing := make(map[string]struct{}, len(tblInfo.Partition.Definitions))
+	for _, d := range tblInfo.Partition.Definitions {
+		existing[d.Name.L] = struct{}{}
+	}
+	for _, d := range newDefs {
+		if _, ok := existing[d.Name.L]; ok {
+			return nil, errors.Errorf("ddl: partition %s already exists", d.Name)
+		}
+	}
+	return append(append([]model.PartitionDefinition{}, tblInfo.Partition.Definitions...), newDefs...), nil
+}
+
+// dropPartitionInplace removes partitionName from tblInfo's partition list
+// and returns the physical ID the caller should hand to the GC worker. The
+// partition is expected to have already been taken through
+// model.StateWriteOnly by the normal job state machine before this is
+// called, so in-flight statements that started before the drop no longer
+// see it but have had a chance to finish.
+func dropPartitionInplace(tblInfo *model.TableInfo, partitionName model.CIStr) (remaining []model.PartitionDefinition, gc *partitionGCTask, err error) {
+	if tblInfo.Partition == nil {
+		return nil, nil, errors.Errorf("ddl: table %s is not partitioned", tblInfo.Name)
+	}
+	defs := tblInfo.Partition.Definitions
+	idx := -1
+	for i, d := range defs {
+		if d.Name.L == partitionName.L {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, nil, errors.Errorf("ddl: partition %s does not exist", partitionName)
+	}
+	remaining = make([]model.PartitionDefinition, 0, len(defs)-1)
+	remaining = append(remaining, defs[:idx]...)
+	remaining = append(remaining, defs[idx+1:]...)
+	gc = &partitionGCTask{OldPhysicalID: defs[idx].ID, TableID: tblInfo.ID}
+	return remaining, gc, nil
+}
+
+// truncatePartitionInplace atomically swaps partitionName's physical table
+// ID for a freshly allocated one, leaving its partition definition (range/
+// list boundary, name) untouched. The caller supplies newPhysicalID (from
+// the usual ID allocator) and hands oldPhysicalID to the GC worker; since
+// only the ID changes, indexes are rebuilt empty under the new ID and
+// foreground queries that resolve the partition by name immediately see
+// the new, empty physical table.
+func truncatePartitionInplace(tblInfo *model.TableInfo, partitionName model.CIStr, newPhysicalID int64) (gc *partitionGCTask, err error) {
+	if tblInfo.Partition == nil {
+		return nil, errors.Errorf("ddl: table %s is not partitioned", tblInfo.Name)
+	}
+	for i, d := range tblInfo.Partition.Definitions {
+		if d.Name.L == partitionName.L {
+			old := d.ID
+			tblInfo.Partition.Definitions[i].ID = newPhysicalID
+			return &partitionGCTask{OldPhysicalID: old, TableID: tblInfo.ID}, nil
+		}
+	}
+	return nil, errors.Errorf("ddl: partition %s does not exist", partitionName)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+)
+
+var _ = Suite(&testPartitionSuite{})
+
+type testPartitionSuite struct{}
+
+func partitionedTable(defs ...model.PartitionDefinition) *model.TableInfo {
+	return &model.TableInfo{
+		ID:        1,
+		Name:      model.NewCIStr("t"),
+		Partition: &model.PartitionInfo{Definitions: defs},
+	}
+}
+
+// TestAddPartitionInplaceRejectsNonPartitionedTable checks that a table
+// with no partition info is rejected outright.
+func (s *testPartitionSuite) TestAddPartitionInplaceRejectsNonPartitionedTable(c *C) {
+	tbl := &model.TableInfo{Name: model.NewCIStr("t")}
+	_, err := addPartitionInplace(tbl, []model.PartitionDefinition{{Name: model.NewCIStr("p1")}})
+	c.Assert(err, ErrorMatches, ".*is not partitioned.*")
+}
+
+// TestAddPartitionInplaceAppendsNewDefinitions checks that new partitions
+// are appended after the existing ones, preserving order.
+func (s *testPartitionSuite) TestAddPartitionInplaceAppendsNewDefinitions(c *C) {
+	tbl := partitionedTable(model.PartitionDefinition{Name: model.NewCIStr("p0"), ID: 10})
+	got, err := addPartitionInplace(tbl, []model.PartitionDefinition{{Name: model.NewCIStr("p1"), ID: 11}})
+	c.Assert(err, IsNil)
+	c.Assert(got, HasLen, 2)
+	c.Assert(got[0].Name.L, Equals, "p0")
+	c.Assert(got[1].Name.L, Equals, "p1")
+}
+
+// TestAddPartitionInplaceRejectsDuplicateName checks that adding a
+// partition whose name collides with an existing one is rejected.
+func (s *testPartitionSuite) TestAddPartitionInplaceRejectsDuplicateName(c *C) {
+	tbl := partitionedTable(model.PartitionDefinition{Name: model.NewCIStr("p0"), ID: 10})
+	_, err := addPartitionInplace(tbl, []model.PartitionDefinition{{Name: model.NewCIStr("p0"), ID: 11}})
+	c.Assert(err, ErrorMatches, ".*already exists.*")
+}
+
+// TestDropPartitionInplaceRemovesNamedPartitionAndEmitsGCTask checks that
+// dropping a partition removes it from the remaining list (leaving the
+// others in order) and returns a GC task naming its old physical ID.
+func (s *testPartitionSuite) TestDropPartitionInplaceRemovesNamedPartitionAndEmitsGCTask(c *C) {
+	tbl := partitionedTable(
+		model.PartitionDefinition{Name: model.NewCIStr("p0"), ID: 10},
+		model.PartitionDefinition{Name: model.NewCIStr("p1"), ID: 11},
+	)
+	remaining, gc, err := dropPartitionInplace(tbl, model.NewCIStr("p0"))
+	c.Assert(err, IsNil)
+	c.Assert(remaining, HasLen, 1)
+	c.Assert(remaining[0].Name.L, Equals, "p1")
+	c.Assert(gc.OldPhysicalID, Equals, int64(10))
+	c.Assert(gc.TableID, Equals, tbl.ID)
+}
+
+// TestDropPartitionInplaceRejectsUnknownPartition checks that dropping a
+// partition name that doesn't exist is rejected rather than silently
+// no-op'd.
+func (s *testPartitionSuite) TestDropPartitionInplaceRejectsUnknownPartition(c *C) {
+	tbl := partitionedTable(model.PartitionDefinition{Name: model.NewCIStr("p0"), ID: 10})
+	_, _, err := dropPartitionInplace(tbl, model.NewCIStr("nope"))
+	c.Assert(err, ErrorMatches, ".*does not exist.*")
+}
+
+// TestTruncatePartitionInplaceSwapsPhysicalIDInPlace checks that
+// truncating a partition rewrites its definition's ID to newPhysicalID in
+// place and returns a GC task naming the old one.
+func (s *testPartitionSuite) TestTruncatePartitionInplaceSwapsPhysicalIDInPlace(c *C) {
+	tbl := partitionedTable(model.PartitionDefinition{Name: model.NewCIStr("p0"), ID: 10})
+	gc, err := truncatePartitionInplace(tbl, model.NewCIStr("p0"), 99)
+	c.Assert(err, IsNil)
+	c.Assert(gc.OldPhysicalID, Equals, int64(10))
+	c.Assert(tbl.Partition.Definitions[0].ID, Equals, int64(99))
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+)
+
+var _ = Suite(&testUpgradeUTF8Suite{})
+
+type testUpgradeUTF8Suite struct{}
+
+// TestNeedsUTF8ToUTF8MB4UpgradeOnlyMatchesLegacyUTF8 checks that only a
+// TableInfoVersion0 table declared under plain "utf8" is flagged, so an
+// already-upgraded or newer-version table is left alone.
+func (s *testUpgradeUTF8Suite) TestNeedsUTF8ToUTF8MB4UpgradeOnlyMatchesLegacyUTF8(c *C) {
+	legacy := &model.TableInfo{Version: model.TableInfoVersion0, Charset: "utf8"}
+	c.Assert(needsUTF8ToUTF8MB4Upgrade(legacy), IsTrue)
+
+	alreadyMB4 := &model.TableInfo{Version: model.TableInfoVersion0, Charset: "utf8mb4"}
+	c.Assert(needsUTF8ToUTF8MB4Upgrade(alreadyMB4), IsFalse)
+
+	newerVersion := &model.TableInfo{Version: model.TableInfoVersion1, Charset: "utf8"}
+	c.Assert(needsUTF8ToUTF8MB4Upgrade(newerVersion), IsFalse)
+}
+
+// TestUpgradeTableUTF8ToUTF8MB4RewritesTableAndColumns checks that a
+// qualifying table and its utf8 columns are rewritten to utf8mb4, its
+// version is bumped, and an already-newer column version is left alone.
+func (s *testUpgradeUTF8Suite) TestUpgradeTableUTF8ToUTF8MB4RewritesTableAndColumns(c *C) {
+	tbl := &model.TableInfo{
+		Version: model.TableInfoVersion0,
+		Charset: "utf8",
+		Columns: []*model.ColumnInfo{
+			{Charset: "utf8", Version: model.ColumnInfoVersion0},
+			{Charset: "binary", Version: model.ColumnInfoVersion0 + 1},
+		},
+	}
+	c.Assert(upgradeTableUTF8ToUTF8MB4(tbl), IsTrue)
+	c.Assert(tbl.Charset, Equals, "utf8mb4")
+	c.Assert(tbl.Version, Equals, utf8ToUTF8MB4TableInfoVersion)
+	c.Assert(tbl.Columns[0].Charset, Equals, "utf8mb4")
+	c.Assert(tbl.Columns[1].Charset, Equals, "binary")
+	c.Assert(tbl.Columns[1].Version, Equals, model.ColumnInfoVersion0+1)
+}
+
+// TestUpgradeTableUTF8ToUTF8MB4NoOpForNonQualifyingTable checks that a
+// table that doesn't need the upgrade is left untouched and the function
+// reports false.
+func (s *testUpgradeUTF8Suite) TestUpgradeTableUTF8ToUTF8MB4NoOpForNonQualifyingTable(c *C) {
+	tbl := &model.TableInfo{Version: model.TableInfoVersion0, Charset: "utf8mb4"}
+	c.Assert(upgradeTableUTF8ToUTF8MB4(tbl), IsFalse)
+	c.Assert(tbl.Charset, Equals, "utf8mb4")
+}
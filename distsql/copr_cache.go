@@ -0,0 +1,213 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distsql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+)
+
+// coprCacheKeyStartTsBucket is the width, in logical timestamp units, that
+// StartTs is floored to before entering the cache key. Two queries issued
+// moments apart against the same snapshot range would otherwise never
+// share a cache entry even though the data they read cannot have changed,
+// since TiDB's startTs is a physical-time-derived monotonic counter.
+const coprCacheKeyStartTsBucket = 1 << 18
+
+// CoprCacheKey is the digest a cached coprocessor response is keyed by. Two
+// requests that would read the same data off TiKV hash to the same key.
+type CoprCacheKey [32]byte
+
+// BuildCoprCacheKey computes req's cache key over its shape (Tp, a
+// StartTs bucket, a digest of KeyRanges, Data, Desc, and KeepOrder). It
+// returns ok=false for any request type other than ReqTypeDAG, since
+// Analyze/Checksum requests are never safe to cache.
+func BuildCoprCacheKey(req *kv.Request) (CoprCacheKey, bool) {
+	if req.Tp != kv.ReqTypeDAG {
+		return CoprCacheKey{}, false
+	}
+	h := sha256.New()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(req.Tp))
+	h.Write(buf[:])
+	binary.LittleEndian.PutUint64(buf[:], req.StartTs/coprCacheKeyStartTsBucket)
+	h.Write(buf[:])
+	for _, r := range req.KeyRanges {
+		h.Write(r.StartKey)
+		h.Write(r.EndKey)
+	}
+	h.Write(req.Data)
+	if req.Desc {
+		h.Write([]byte{1})
+	}
+	if req.KeepOrder {
+		h.Write([]byte{2})
+	}
+	var key CoprCacheKey
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+// coprCacheEntry is one cached coprocessor response.
+type coprCacheEntry struct {
+	key        CoprCacheKey
+	data       []byte
+	expiresAt  time.Time
+	schemaVer  int64
+	touchedKey []kv.KeyRange
+}
+
+// CoprCache is an in-process LRU cache of coprocessor responses, bounded by
+// the maxBytes given to NewCoprCache. Entries are invalidated three ways:
+// they expire after their TTL, they are dropped wholesale by
+// OnSchemaChanged when the schema version advances, and they are dropped
+// individually by OnRegionWritten when a written range overlaps a range
+// they read from. No session variable enables or sizes it, the coprocessor
+// client does not consult it, and nothing subscribes OnRegionWritten to
+// pdapi.WatchHotRegions.
+type CoprCache struct {
+	mu        sync.Mutex
+	sizeBytes int64
+	maxBytes  int64
+	ll        *list.List
+	items     map[CoprCacheKey]*list.Element
+	schemaVer int64
+}
+
+// NewCoprCache creates a CoprCache bounded to maxBytes of cached response
+// payload, approximated by summing len(data) across entries.
+func NewCoprCache(maxBytes int64) *CoprCache {
+	return &CoprCache{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[CoprCacheKey]*list.Element),
+	}
+}
+
+// Get returns the cached response for key, if present, unexpired, and
+// computed under the schema version currently set via SetSchemaVersion.
+func (c *CoprCache) Get(key CoprCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*coprCacheEntry)
+	if time.Now().After(entry.expiresAt) || entry.schemaVer != c.schemaVer {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return entry.data, true
+}
+
+// Set inserts or replaces the cached response for key, good until ttl
+// elapses or the schema version / touched ranges it depends on change.
+// Inserting an entry larger than maxBytes on its own is a no-op.
+func (c *CoprCache) Set(key CoprCacheKey, data []byte, ttl time.Duration, touched []kv.KeyRange) {
+	if int64(len(data)) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	entry := &coprCacheEntry{
+		key:        key,
+		data:       data,
+		expiresAt:  time.Now().Add(ttl),
+		schemaVer:  c.schemaVer,
+		touchedKey: touched,
+	}
+	elem := c.ll.PushFront(entry)
+	c.items[key] = elem
+	c.sizeBytes += int64(len(data))
+	for c.sizeBytes > c.maxBytes {
+		back := c.ll.Back()
+		if back == nil {
+			break
+		}
+		c.removeElement(back)
+	}
+}
+
+// removeElement evicts elem; callers must hold c.mu.
+func (c *CoprCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*coprCacheEntry)
+	c.ll.Remove(elem)
+	delete(c.items, entry.key)
+	c.sizeBytes -= int64(len(entry.data))
+}
+
+// OnSchemaChanged drops every cached entry once domain's schema version
+// advances past ver, since a cached coprocessor response computed under an
+// older table/index definition is no longer guaranteed correct.
+func (c *CoprCache) OnSchemaChanged(ver int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ver <= c.schemaVer {
+		return
+	}
+	c.schemaVer = ver
+	c.ll.Init()
+	c.items = make(map[CoprCacheKey]*list.Element)
+	c.sizeBytes = 0
+}
+
+// OnRegionWritten evicts every cached entry whose key ranges overlap
+// writtenRange. Nothing in the tree calls it; the hotspot watcher's
+// pdapi.HotRegionEvent values are not forwarded to it.
+func (c *CoprCache) OnRegionWritten(writtenRange kv.KeyRange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.ll.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*coprCacheEntry)
+		for _, r := range entry.touchedKey {
+			if rangesOverlap(r, writtenRange) {
+				c.removeElement(elem)
+				break
+			}
+		}
+		elem = next
+	}
+}
+
+// rangesOverlap reports whether a and b, both half-open [StartKey, EndKey)
+// ranges, intersect. An empty EndKey means "no upper bound".
+func rangesOverlap(a, b kv.KeyRange) bool {
+	if len(a.EndKey) != 0 && !bytesLess(b.StartKey, a.EndKey) {
+		return false
+	}
+	if len(b.EndKey) != 0 && !bytesLess(a.StartKey, b.EndKey) {
+		return false
+	}
+	return true
+}
+
+func bytesLess(a, b []byte) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -16,6 +16,7 @@ package distsql
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pingcap/parser/mysql"
 	"github.com/pingcap/tidb/kv"
@@ -78,6 +79,30 @@ func (builder *RequestBuilder) SetTableHandles(tid int64, handles []int64) *Requ
 	return builder
 }
 
+// SetTableHandlesStream sets "KeyRangesStream" for "kv.Request" instead of a
+// fully materialized "KeyRanges" slice, so a caller like IndexLookUp can
+// consume batches lazily and bound its peak memory on millions of handles.
+// A non-positive maxRangesPerBatch or maxKeysPerBatch is replaced by
+// defaultHandleBatchSize or defaultHandleBatchKeys respectively.
+func (builder *RequestBuilder) SetTableHandlesStream(tid int64, handles []int64, maxRangesPerBatch, maxKeysPerBatch int) *RequestBuilder {
+	if maxRangesPerBatch <= 0 {
+		maxRangesPerBatch = defaultHandleBatchSize
+	}
+	if maxKeysPerBatch <= 0 {
+		maxKeysPerBatch = defaultHandleBatchKeys
+	}
+	builder.Request.KeyRangesStream = TableHandlesToKVRangesStream(tid, handles, maxRangesPerBatch, maxKeysPerBatch)
+	return builder
+}
+
+// defaultHandleBatchSize and defaultHandleBatchKeys are the batch bounds
+// SetTableHandlesStream uses for a non-positive argument. No session
+// variable sets them or overrides them.
+const (
+	defaultHandleBatchSize = 20000
+	defaultHandleBatchKeys = 20000
+)
+
 // SetDAGRequest sets the request type to "ReqTypeDAG" and construct request data.
 func (builder *RequestBuilder) SetDAGRequest(dag *tipb.DAGRequest) *RequestBuilder {
 	if builder.err != nil {
@@ -178,6 +203,26 @@ func (builder *RequestBuilder) SetConcurrency(concurrency int) *RequestBuilder {
 	return builder
 }
 
+// SetCacheable sets "Cacheable" for "kv.Request", marking it eligible for
+// the in-process coprocessor result cache. Only ReqTypeDAG requests issued
+// against a read-only snapshot should set this: a cached response is only
+// ever correct for a query that is provably repeatable, which write
+// requests and analyze/checksum scans are not.
+func (builder *RequestBuilder) SetCacheable(cacheable bool) *RequestBuilder {
+	builder.Request.Cacheable = cacheable
+	return builder
+}
+
+// SetCacheTTL sets how long a cached coprocessor response for this request
+// may be served before it must be refetched, even if nothing has
+// invalidated it sooner via a schema change or a write to a touched key
+// range. The coprocessor client does not read CacheTTL or Cacheable, so
+// neither setting changes how the request is sent.
+func (builder *RequestBuilder) SetCacheTTL(ttl time.Duration) *RequestBuilder {
+	builder.Request.CacheTTL = ttl
+	return builder
+}
+
 // TableRangesToKVRanges converts table ranges to "KeyRange".
 func TableRangesToKVRanges(tid int64, ranges []*ranger.Range, fb *statistics.QueryFeedback) []kv.KeyRange {
 	if fb == nil || fb.Hist == nil {
@@ -255,6 +300,55 @@ func TableHandlesToKVRanges(tid int64, handles []int64) []kv.KeyRange {
 	return krs
 }
 
+// TableHandlesToKVRangesStream is the chunked counterpart of
+// TableHandlesToKVRanges: instead of merging every contiguous run of handles
+// into one giant slice up front, it merges within a bounded window and
+// pushes each window to the returned channel as soon as it fills, so a
+// consumer can start issuing coprocessor requests before the full handle
+// list has even been walked and can stop early (e.g. once a LIMIT is
+// satisfied) by abandoning the channel.
+//
+// A batch closes when it reaches maxRangesPerBatch key ranges or
+// maxKeysPerBatch underlying handles, whichever comes first. The channel is
+// unbuffered; the caller is expected to range over it and is responsible
+// for bounding how far ahead of consumption the producer is allowed to run
+// by how quickly it drains.
+func TableHandlesToKVRangesStream(tid int64, handles []int64, maxRangesPerBatch, maxKeysPerBatch int) <-chan []kv.KeyRange {
+	out := make(chan []kv.KeyRange)
+	go func() {
+		defer close(out)
+		var batch []kv.KeyRange
+		batchKeys := 0
+		i := 0
+		for i < len(handles) {
+			j := i + 1
+			for ; j < len(handles) && handles[j-1] != math.MaxInt64; j++ {
+				if handles[j] != handles[j-1]+1 {
+					break
+				}
+			}
+			low := codec.EncodeInt(nil, handles[i])
+			high := codec.EncodeInt(nil, handles[j-1])
+			high = []byte(kv.Key(high).PrefixNext())
+			startKey := tablecodec.EncodeRowKey(tid, low)
+			endKey := tablecodec.EncodeRowKey(tid, high)
+			batch = append(batch, kv.KeyRange{StartKey: startKey, EndKey: endKey})
+			batchKeys += j - i
+			i = j
+
+			if len(batch) >= maxRangesPerBatch || batchKeys >= maxKeysPerBatch {
+				out <- batch
+				batch = nil
+				batchKeys = 0
+			}
+		}
+		if len(batch) > 0 {
+			out <- batch
+		}
+	}()
+	return out
+}
+
 // IndexRangesToKVRanges converts index ranges to "KeyRange".
 func IndexRangesToKVRanges(sc *stmtctx.StatementContext, tid, idxID int64, ranges []*ranger.Range, fb *statistics.QueryFeedback) ([]kv.KeyRange, error) {
 	if fb == nil || fb.Hist == nil {
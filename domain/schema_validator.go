@@ -16,8 +16,10 @@ package domain
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/util/logutil"
 	"go.uber.org/zap"
@@ -40,9 +42,15 @@ type SchemaValidator interface {
 	// The latest schemaVer is valid within leaseGrantTime plus lease duration.
 	// Add the changed table IDs to the new schema information,
 	// which is produced when the oldSchemaVer is updated to the newSchemaVer.
-	Update(leaseGrantTime uint64, oldSchemaVer, newSchemaVer int64, changedTableIDs []int64)
+	// droppedIDs are the table/DB IDs this diff dropped, truncated, or recycled;
+	// they are tombstoned so a later Check against a now-stale relatedTableIDs
+	// fails immediately instead of waiting to notice via deltaSchemaInfos.
+	Update(leaseGrantTime uint64, oldSchemaVer, newSchemaVer int64, changedTableIDs, droppedIDs []int64)
 	// Check is it valid for a transaction to use schemaVer and related tables, at timestamp txnTS.
-	Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) checkResult
+	// err is non-nil only when the result is ResultFail because relatedTableIDs hit a tombstone,
+	// in which case it is errSchemaObjectGone so the caller can surface ErrNoSuchTable instead of
+	// a generic info-schema-changed retry.
+	Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) (checkResult, error)
 	// Stop stops checking the valid of transaction.
 	Stop()
 	// Restart restarts the schema validator after it is stopped.
@@ -53,9 +61,26 @@ type SchemaValidator interface {
 	IsStarted() bool
 }
 
+// errSchemaObjectGone is returned by Check when relatedTableIDs intersects a
+// tombstoned ID created at or before txnTS: the table or DB isn't merely
+// stale, it no longer exists, so retrying the info schema can't help.
+var errSchemaObjectGone = errors.New("table or database no longer exists")
+
 type deltaSchemaInfo struct {
 	schemaVersion   int64
 	relatedTableIDs []int64
+	// filter lets isRelatedTablesChanged test a candidate table ID against
+	// this delta without scanning relatedTableIDs, built once here at
+	// enqueue time rather than on every Check.
+	filter bloomFilter
+}
+
+// tombstone records that an ID (table or DB) was dropped, truncated, or had
+// its ID recycled as of createTS. expireAt bounds how long the tombstone
+// itself is remembered, independent of txnTS comparisons against createTS.
+type tombstone struct {
+	createTS uint64
+	expireAt time.Time
 }
 
 type schemaValidator struct {
@@ -66,6 +91,34 @@ type schemaValidator struct {
 	latestSchemaExpire time.Time
 	// deltaSchemaInfos is a queue that maintain the history of changes.
 	deltaSchemaInfos []deltaSchemaInfo
+	// tombstones holds dropped/truncated/ID-recycled table and DB IDs so
+	// Check can fail fast with errSchemaObjectGone instead of a generic
+	// ResultFail that sends the caller back to reload the info schema only
+	// to hit the same missing object again. Entries older than 2*lease are
+	// swept on the next Update, since nothing can still be validly using an
+	// ID that's been gone for that long.
+	tombstones map[int64]tombstone
+	// allDeltasFilter is the union of every deltaSchemaInfos entry's
+	// filter currently in the window, recomputed whenever enqueue
+	// changes that window. isRelatedTablesChanged tests against this
+	// before even finding newerDeltas, so a transaction whose schema
+	// version lies inside the window and whose tables no delta in the
+	// window mentions returns ResultSucc without scanning
+	// deltaSchemaInfos at all.
+	allDeltasFilter bloomFilter
+	// bloomPositives and bloomFalsePositives feed
+	// schemaValidatorBloomFalsePositiveRate; they're updated with atomic
+	// ops since Check only holds mux for reading.
+	bloomPositives      int64
+	bloomFalsePositives int64
+	// leaseWatcher, if non-nil, lets Check additionally require a quorum of
+	// live peers, not just a local unexpired lease. See
+	// NewSchemaValidatorWithLeaseWatcher.
+	leaseWatcher LeaseWatcher
+	// renewCancel stops the background goroutine that renews this peer's
+	// lease through leaseWatcher; nil when leaseWatcher is nil or the
+	// renewal goroutine is paused by Stop.
+	renewCancel context.CancelFunc
 }
 
 // NewSchemaValidator returns a SchemaValidator structure.
@@ -74,6 +127,7 @@ func NewSchemaValidator(lease time.Duration) SchemaValidator {
 		isStarted:        true,
 		lease:            lease,
 		deltaSchemaInfos: make([]deltaSchemaInfo, 0, maxNumberOfDiffsToLoad),
+		tombstones:       make(map[int64]tombstone),
 	}
 }
 
@@ -91,13 +145,20 @@ func (s *schemaValidator) Stop() {
 	s.isStarted = false
 	s.latestSchemaVer = 0
 	s.deltaSchemaInfos = make([]deltaSchemaInfo, 0, maxNumberOfDiffsToLoad)
+	s.tombstones = make(map[int64]tombstone)
+	s.allDeltasFilter = bloomFilter{}
+	s.stopLeaseRenewal()
 }
 
 func (s *schemaValidator) Restart() {
 	logutil.Logger(context.Background()).Info("the schema validator restarts")
 	s.mux.Lock()
-	defer s.mux.Unlock()
 	s.isStarted = true
+	needsRenewal := s.leaseWatcher != nil && s.renewCancel == nil
+	s.mux.Unlock()
+	if needsRenewal {
+		s.startLeaseRenewal()
+	}
 }
 
 func (s *schemaValidator) Reset() {
@@ -106,9 +167,11 @@ func (s *schemaValidator) Reset() {
 	s.isStarted = true
 	s.latestSchemaVer = 0
 	s.deltaSchemaInfos = make([]deltaSchemaInfo, 0, maxNumberOfDiffsToLoad)
+	s.tombstones = make(map[int64]tombstone)
+	s.allDeltasFilter = bloomFilter{}
 }
 
-func (s *schemaValidator) Update(leaseGrantTS uint64, oldVer, currVer int64, changedTableIDs []int64) {
+func (s *schemaValidator) Update(leaseGrantTS uint64, oldVer, currVer int64, changedTableIDs, droppedIDs []int64) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
@@ -129,6 +192,26 @@ func (s *schemaValidator) Update(leaseGrantTS uint64, oldVer, currVer int64, cha
 			zap.Int64("currVer", currVer), zap.Int64s("changedTableIDs", changedTableIDs))
 		s.enqueue(currVer, changedTableIDs)
 	}
+
+	if len(droppedIDs) > 0 {
+		logutil.Logger(context.Background()).Debug("tombstone dropped IDs", zap.Int64s("droppedIDs", droppedIDs))
+		expireAt := leaseGrantTime.Add(2 * s.lease)
+		for _, id := range droppedIDs {
+			s.tombstones[id] = tombstone{createTS: leaseGrantTS, expireAt: expireAt}
+		}
+	}
+	s.evictTombstones(leaseGrantTime)
+}
+
+// evictTombstones drops every tombstone whose expireAt has passed as of now,
+// bounding the map's size regardless of how many IDs get dropped over the
+// life of the process. Must be called under s.mux's write lock.
+func (s *schemaValidator) evictTombstones(now time.Time) {
+	for id, ts := range s.tombstones {
+		if now.After(ts.expireAt) {
+			delete(s.tombstones, id)
+		}
+	}
 }
 
 func hasRelatedTableID(relatedTableIDs, updateTableIDs []int64) bool {
@@ -150,20 +233,53 @@ func (s *schemaValidator) isRelatedTablesChanged(currVer int64, tableIDs []int64
 		logutil.Logger(context.Background()).Info("schema change history is empty", zap.Int64("currVer", currVer))
 		return true
 	}
+	if s.deltaSchemaInfos[0].schemaVersion <= currVer && !s.allDeltasFilter.anyMayContain(tableIDs) {
+		// currVer is inside the window, so every newer delta is in the
+		// window too, and no delta in the window could possibly mention any
+		// of tableIDs -- skip straight to "unchanged" without finding
+		// newerDeltas at all.
+		return false
+	}
 	newerDeltas := s.findNewerDeltas(currVer)
 	if len(newerDeltas) == len(s.deltaSchemaInfos) {
 		logutil.Logger(context.Background()).Info("the schema version is much older than the latest version", zap.Int64("currVer", currVer),
 			zap.Int64("latestSchemaVer", s.latestSchemaVer))
 		return true
 	}
+
+	var union bloomFilter
+	for _, item := range newerDeltas {
+		union.unionInto(&item.filter)
+	}
+	if !union.anyMayContain(tableIDs) {
+		return false
+	}
+	atomic.AddInt64(&s.bloomPositives, 1)
+
 	for _, item := range newerDeltas {
 		if hasRelatedTableID(item.relatedTableIDs, tableIDs) {
 			return true
 		}
 	}
+	atomic.AddInt64(&s.bloomFalsePositives, 1)
+	s.reportBloomFalsePositiveRate()
 	return false
 }
 
+// reportBloomFalsePositiveRate publishes the running false-positive rate
+// to schemaValidatorBloomFalsePositiveRate. Called without the write lock
+// (s.mux.RLock is all isRelatedTablesChanged's caller holds), which is fine
+// since bloomPositives/bloomFalsePositives are only ever read here via
+// atomic loads.
+func (s *schemaValidator) reportBloomFalsePositiveRate() {
+	positives := atomic.LoadInt64(&s.bloomPositives)
+	if positives == 0 {
+		return
+	}
+	falsePositives := atomic.LoadInt64(&s.bloomFalsePositives)
+	schemaValidatorBloomFalsePositiveRate.Set(float64(falsePositives) / float64(positives))
+}
+
 func (s *schemaValidator) findNewerDeltas(currVer int64) []deltaSchemaInfo {
 	q := s.deltaSchemaInfos
 	pos := len(q)
@@ -174,15 +290,25 @@ func (s *schemaValidator) findNewerDeltas(currVer int64) []deltaSchemaInfo {
 }
 
 // Check checks schema validity, returns true if use schemaVer and related tables at txnTS is legal.
-func (s *schemaValidator) Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) checkResult {
+func (s *schemaValidator) Check(txnTS uint64, schemaVer int64, relatedTableIDs []int64) (checkResult, error) {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 	if !s.isStarted {
 		logutil.Logger(context.Background()).Info("the schema validator stopped before checking")
-		return ResultUnknown
+		return ResultUnknown, nil
 	}
 	if s.lease == 0 {
-		return ResultSucc
+		return ResultSucc, nil
+	}
+
+	if s.peerQuorumLost() {
+		return ResultUnknown, nil
+	}
+
+	if id, ok := s.findGoneTable(txnTS, relatedTableIDs); ok {
+		logutil.Logger(context.Background()).Info("related table no longer exists", zap.Int64("tableID", id),
+			zap.Uint64("txnTS", txnTS))
+		return ResultFail, errSchemaObjectGone
 	}
 
 	// Schema changed, result decided by whether related tables change.
@@ -191,26 +317,47 @@ func (s *schemaValidator) Check(txnTS uint64, schemaVer int64, relatedTableIDs [
 		if len(relatedTableIDs) == 0 {
 			logutil.Logger(context.Background()).Info("the related table ID is empty", zap.Int64("schemaVer", schemaVer),
 				zap.Int64("latestSchemaVer", s.latestSchemaVer))
-			return ResultFail
+			return ResultFail, nil
 		}
 
 		if s.isRelatedTablesChanged(schemaVer, relatedTableIDs) {
-			return ResultFail
+			return ResultFail, nil
 		}
-		return ResultSucc
+		return ResultSucc, nil
 	}
 
 	// Schema unchanged, maybe success or the schema validator is unavailable.
 	t := oracle.GetTimeFromTS(txnTS)
 	if t.After(s.latestSchemaExpire) {
-		return ResultUnknown
+		return ResultUnknown, nil
+	}
+	return ResultSucc, nil
+}
+
+// findGoneTable reports the first ID in relatedTableIDs tombstoned at or
+// before txnTS, if any. NOTE, this function should be called under lock!
+func (s *schemaValidator) findGoneTable(txnTS uint64, relatedTableIDs []int64) (int64, bool) {
+	for _, id := range relatedTableIDs {
+		if ts, ok := s.tombstones[id]; ok && ts.createTS <= txnTS {
+			return id, true
+		}
 	}
-	return ResultSucc
+	return 0, false
 }
 
 func (s *schemaValidator) enqueue(schemaVersion int64, relatedTableIDs []int64) {
-	s.deltaSchemaInfos = append(s.deltaSchemaInfos, deltaSchemaInfo{schemaVersion, relatedTableIDs})
+	var filter bloomFilter
+	for _, id := range relatedTableIDs {
+		filter.add(id)
+	}
+	s.deltaSchemaInfos = append(s.deltaSchemaInfos, deltaSchemaInfo{schemaVersion, relatedTableIDs, filter})
 	if len(s.deltaSchemaInfos) > maxNumberOfDiffsToLoad {
 		s.deltaSchemaInfos = s.deltaSchemaInfos[1:]
 	}
+
+	var all bloomFilter
+	for _, item := range s.deltaSchemaInfos {
+		all.unionInto(&item.filter)
+	}
+	s.allDeltasFilter = all
 }
@@ -0,0 +1,90 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+// bloomBits and bloomWords size each deltaSchemaInfo's filter at a fixed
+// 512 bits (8 uint64 words), small enough that unioning a whole window of
+// maxNumberOfDiffsToLoad filters together is a handful of word-ORs rather
+// than a real cost center.
+const (
+	bloomBits  = 512
+	bloomWords = bloomBits / 64
+)
+
+// bloomFilter is a fixed-size Bloom filter over int64 table/DB IDs, hashed
+// with two independent hash functions (see bloomHashes). It never reports
+// a false negative, only possible false positives, which is what lets
+// isRelatedTablesChanged use a negative test result to skip its exact scan
+// outright.
+type bloomFilter [bloomWords]uint64
+
+func (bf *bloomFilter) add(id int64) {
+	h1, h2 := bloomHashes(id)
+	bf.setBit(h1)
+	bf.setBit(h2)
+}
+
+func (bf *bloomFilter) setBit(h uint32) {
+	bf[h/64] |= 1 << (h % 64)
+}
+
+func (bf *bloomFilter) testBit(h uint32) bool {
+	return bf[h/64]&(1<<(h%64)) != 0
+}
+
+// mayContain reports whether id could have been added to bf. A false
+// result is a guarantee id was never added; a true result may be a false
+// positive.
+func (bf *bloomFilter) mayContain(id int64) bool {
+	h1, h2 := bloomHashes(id)
+	return bf.testBit(h1) && bf.testBit(h2)
+}
+
+// unionInto ORs every bit of other into bf.
+func (bf *bloomFilter) unionInto(other *bloomFilter) {
+	for i := range bf {
+		bf[i] |= other[i]
+	}
+}
+
+// anyMayContain reports whether bf could contain any of ids.
+func (bf *bloomFilter) anyMayContain(ids []int64) bool {
+	for _, id := range ids {
+		if bf.mayContain(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// bloomHashes derives two independent-enough bit positions for id from two
+// differently-seeded fmix64 finalizers (the 64-bit avalanche mix from
+// MurmurHash3), which is cheap and spreads adjacent table IDs (a table and
+// its indexes are usually allocated nearby IDs) across the whole filter
+// instead of clustering them.
+func bloomHashes(id int64) (uint32, uint32) {
+	u := uint64(id)
+	h1 := fmix64(u ^ 0x9e3779b97f4a7c15)
+	h2 := fmix64(u ^ 0xc2b2ae3d27d4eb4f)
+	return uint32(h1 % bloomBits), uint32(h2 % bloomBits)
+}
+
+func fmix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// LeaseWatcher reports the liveness of the schema-lease keepalives held by
+// every TiDB peer in the cluster under a well-known etcd/PD prefix, and
+// renews this peer's own keepalive. A schemaValidator built with a
+// LeaseWatcher treats the local latestSchemaExpire clock as necessary but
+// not sufficient: it also requires enough peers to still be reachable,
+// so a node that's been partitioned away from the rest of the cluster stops
+// trusting its own clock and forces a reload instead of serving stale schema.
+type LeaseWatcher interface {
+	// LivePeerCount returns how many peers currently hold a live schema-lease
+	// keepalive, including this one if RenewSelf has succeeded recently.
+	LivePeerCount() int
+	// Quorum returns the minimum LivePeerCount below which the local schema
+	// lease can no longer be trusted.
+	Quorum() int
+	// RenewSelf renews this peer's own schema-lease keepalive so other
+	// watchers observe it as live.
+	RenewSelf(ctx context.Context) error
+	// Close releases the watcher's resources, e.g. its etcd session.
+	Close()
+}
+
+// NewSchemaValidatorWithLeaseWatcher returns a SchemaValidator that, in
+// addition to the local lease clock, cross-checks liveness against watcher's
+// view of how many peers currently hold a schema lease. It starts a
+// background goroutine that renews the local peer's lease every lease/2;
+// Stop pauses that goroutine and Restart resumes it.
+func NewSchemaValidatorWithLeaseWatcher(lease time.Duration, watcher LeaseWatcher) SchemaValidator {
+	s := &schemaValidator{
+		isStarted:        true,
+		lease:            lease,
+		deltaSchemaInfos: make([]deltaSchemaInfo, 0, maxNumberOfDiffsToLoad),
+		tombstones:       make(map[int64]tombstone),
+		leaseWatcher:     watcher,
+	}
+	s.startLeaseRenewal()
+	return s
+}
+
+// startLeaseRenewal launches the background renewal goroutine. Must be
+// called with s.leaseWatcher non-nil; callers hold no lock.
+func (s *schemaValidator) startLeaseRenewal() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mux.Lock()
+	s.renewCancel = cancel
+	s.mux.Unlock()
+
+	go func() {
+		interval := s.lease / 2
+		if interval <= 0 {
+			interval = time.Second
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.leaseWatcher.RenewSelf(ctx); err != nil {
+					logutil.Logger(ctx).Warn("schema lease renewal failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// stopLeaseRenewal cancels the background renewal goroutine, if any. Must be
+// called with s.mux held.
+func (s *schemaValidator) stopLeaseRenewal() {
+	if s.renewCancel != nil {
+		s.renewCancel()
+		s.renewCancel = nil
+	}
+}
+
+// peerQuorumLost reports whether watcher is configured and observes fewer
+// live peers than its quorum, meaning the local lease clock alone can no
+// longer be trusted. NOTE, this function should be called under lock!
+func (s *schemaValidator) peerQuorumLost() bool {
+	if s.leaseWatcher == nil {
+		return false
+	}
+	live := s.leaseWatcher.LivePeerCount()
+	quorum := s.leaseWatcher.Quorum()
+	if live < quorum {
+		logutil.Logger(context.Background()).Info("schema lease peer quorum lost",
+			zap.Int("livePeers", live), zap.Int("quorum", quorum))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// schemaValidatorBloomFalsePositiveRate tracks what fraction of
+// isRelatedTablesChanged's bloom-filter candidate hits turn out, once the
+// exact nested scan runs, to not actually be related-table matches. A rate
+// climbing towards 1 means bloomBits is too small for the table churn this
+// instance is seeing and the fast path is buying little.
+var schemaValidatorBloomFalsePositiveRate = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "tidb",
+	Subsystem: "domain",
+	Name:      "schema_validator_bloom_false_positive_rate",
+	Help:      "Fraction of schemaValidator bloom-filter candidate hits that the exact scan found weren't real matches.",
+})
+
+func init() {
+	prometheus.MustRegister(schemaValidatorBloomFalsePositiveRate)
+}
@@ -16,7 +16,10 @@ package aggfuncs
 import (
 	"math"
 
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 )
 
@@ -126,3 +129,219 @@ func (*bitAndUint64) MergePartialResult(sctx sessionctx.Context, src, dst Partia
 	*p2 &= uint64(*p1)
 	return nil
 }
+
+// partialResult4BitFuncString is the accumulator used by the BINARY/VARBINARY
+// and wide BIT(>64) variants of BIT_OR/BIT_XOR/BIT_AND. Unlike
+// partialResult4BitFunc it grows lazily to the width of the widest operand
+// seen so far, since the operands are not bounded to 64 bits.
+type partialResult4BitFuncString struct {
+	buf []byte
+}
+
+type baseBitAggFuncString struct {
+	baseAggFunc
+}
+
+func (e *baseBitAggFuncString) AllocPartialResult() PartialResult {
+	return PartialResult(&partialResult4BitFuncString{})
+}
+
+func (e *baseBitAggFuncString) ResetPartialResult(pr PartialResult) {
+	p := (*partialResult4BitFuncString)(pr)
+	p.buf = nil
+}
+
+func (e *baseBitAggFuncString) AppendFinalResult2Chunk(sctx sessionctx.Context, pr PartialResult, chk *chunk.Chunk) error {
+	p := (*partialResult4BitFuncString)(pr)
+	chk.AppendBytes(e.ordinal, p.buf)
+	return nil
+}
+
+// growRightAligned right-aligns buf against a width-byte accumulator,
+// zero-extending (for OR/XOR) or one-extending (for AND) on the left so
+// operands narrower than the current accumulator still compare correctly.
+func growRightAligned(buf []byte, width int, fill byte) []byte {
+	if len(buf) >= width {
+		return buf
+	}
+	grown := make([]byte, width)
+	for i := 0; i < width-len(buf); i++ {
+		grown[i] = fill
+	}
+	copy(grown[width-len(buf):], buf)
+	return grown
+}
+
+type bitOrString struct {
+	baseBitAggFuncString
+}
+
+func (e *bitOrString) UpdatePartialResult(sctx sessionctx.Context, rowsInGroup []chunk.Row, pr PartialResult) error {
+	p := (*partialResult4BitFuncString)(pr)
+	for _, row := range rowsInGroup {
+		inputValue, isNull, err := e.args[0].EvalString(sctx, row)
+		if err != nil {
+			return err
+		}
+		if isNull {
+			continue
+		}
+		in := []byte(inputValue)
+		width := len(in)
+		if width < len(p.buf) {
+			width = len(p.buf)
+		}
+		p.buf = growRightAligned(p.buf, width, 0)
+		in = growRightAligned(in, width, 0)
+		for i := range p.buf {
+			p.buf[i] |= in[i]
+		}
+	}
+	return nil
+}
+
+func (*bitOrString) MergePartialResult(sctx sessionctx.Context, src, dst PartialResult) error {
+	p1, p2 := (*partialResult4BitFuncString)(src), (*partialResult4BitFuncString)(dst)
+	width := len(p1.buf)
+	if width < len(p2.buf) {
+		width = len(p2.buf)
+	}
+	p1.buf = growRightAligned(p1.buf, width, 0)
+	p2.buf = growRightAligned(p2.buf, width, 0)
+	for i := range p2.buf {
+		p2.buf[i] |= p1.buf[i]
+	}
+	return nil
+}
+
+type bitXorString struct {
+	baseBitAggFuncString
+}
+
+func (e *bitXorString) UpdatePartialResult(sctx sessionctx.Context, rowsInGroup []chunk.Row, pr PartialResult) error {
+	p := (*partialResult4BitFuncString)(pr)
+	for _, row := range rowsInGroup {
+		inputValue, isNull, err := e.args[0].EvalString(sctx, row)
+		if err != nil {
+			return err
+		}
+		if isNull {
+			continue
+		}
+		in := []byte(inputValue)
+		width := len(in)
+		if width < len(p.buf) {
+			width = len(p.buf)
+		}
+		p.buf = growRightAligned(p.buf, width, 0)
+		in = growRightAligned(in, width, 0)
+		for i := range p.buf {
+			p.buf[i] ^= in[i]
+		}
+	}
+	return nil
+}
+
+func (*bitXorString) MergePartialResult(sctx sessionctx.Context, src, dst PartialResult) error {
+	p1, p2 := (*partialResult4BitFuncString)(src), (*partialResult4BitFuncString)(dst)
+	width := len(p1.buf)
+	if width < len(p2.buf) {
+		width = len(p2.buf)
+	}
+	p1.buf = growRightAligned(p1.buf, width, 0)
+	p2.buf = growRightAligned(p2.buf, width, 0)
+	for i := range p2.buf {
+		p2.buf[i] ^= p1.buf[i]
+	}
+	return nil
+}
+
+type bitAndString struct {
+	baseBitAggFuncString
+}
+
+func (e *bitAndString) UpdatePartialResult(sctx sessionctx.Context, rowsInGroup []chunk.Row, pr PartialResult) error {
+	p := (*partialResult4BitFuncString)(pr)
+	for _, row := range rowsInGroup {
+		inputValue, isNull, err := e.args[0].EvalString(sctx, row)
+		if err != nil {
+			return err
+		}
+		if isNull {
+			continue
+		}
+		in := []byte(inputValue)
+		width := len(in)
+		if width < len(p.buf) {
+			width = len(p.buf)
+		}
+		if p.buf == nil {
+			// Seed with all-ones so AND with the first operand yields the
+			// operand itself, matching partialResult4BitFunc's MaxUint64 seed.
+			p.buf = make([]byte, width)
+			for i := range p.buf {
+				p.buf[i] = 0xFF
+			}
+		}
+		p.buf = growRightAligned(p.buf, width, 0xFF)
+		in = growRightAligned(in, width, 0xFF)
+		for i := range p.buf {
+			p.buf[i] &= in[i]
+		}
+	}
+	return nil
+}
+
+func (*bitAndString) MergePartialResult(sctx sessionctx.Context, src, dst PartialResult) error {
+	p1, p2 := (*partialResult4BitFuncString)(src), (*partialResult4BitFuncString)(dst)
+	width := len(p1.buf)
+	if width < len(p2.buf) {
+		width = len(p2.buf)
+	}
+	p1.buf = growRightAligned(p1.buf, width, 0xFF)
+	p2.buf = growRightAligned(p2.buf, width, 0xFF)
+	for i := range p2.buf {
+		p2.buf[i] &= p1.buf[i]
+	}
+	return nil
+}
+
+// needsStringBitAccumulator reports whether aggFuncDesc's sole argument
+// needs the []byte accumulator rather than the uint64 one: BINARY/VARBINARY
+// arguments, or a BIT column wider than 64 bits, can't be evaluated with
+// EvalInt without truncation.
+func needsStringBitAccumulator(aggFuncDesc *aggregation.AggFuncDesc) bool {
+	arg := aggFuncDesc.Args[0]
+	ft := arg.GetType()
+	if ft.EvalType() == types.ETString && mysql.HasBinaryFlag(ft.Flag) {
+		return true
+	}
+	if ft.Tp == mysql.TypeBit && ft.Flen > 64 {
+		return true
+	}
+	return false
+}
+
+// buildBitOr, buildBitXor and buildBitAnd pick the uint64 or string-backed
+// implementation of the corresponding bit aggregate based on the argument's
+// type, matching MySQL 8.0's BINARY/VARBINARY/wide-BIT semantics.
+func buildBitOr(base baseAggFunc, aggFuncDesc *aggregation.AggFuncDesc) AggFunc {
+	if needsStringBitAccumulator(aggFuncDesc) {
+		return &bitOrString{baseBitAggFuncString{base}}
+	}
+	return &bitOrUint64{baseBitAggFunc{base}}
+}
+
+func buildBitXor(base baseAggFunc, aggFuncDesc *aggregation.AggFuncDesc) AggFunc {
+	if needsStringBitAccumulator(aggFuncDesc) {
+		return &bitXorString{baseBitAggFuncString{base}}
+	}
+	return &bitXorUint64{baseBitAggFunc{base}}
+}
+
+func buildBitAnd(base baseAggFunc, aggFuncDesc *aggregation.AggFuncDesc) AggFunc {
+	if needsStringBitAccumulator(aggFuncDesc) {
+		return &bitAndString{baseBitAggFuncString{base}}
+	}
+	return &bitAndUint64{baseBitAggFunc{base}}
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// These tests target growRightAligned and the byte-fold loops its callers
+// use directly, rather than driving UpdatePartialResult end-to-end: that
+// would require a real sessionctx.Context, expression.Column and
+// chunk.Chunk, none of which this trimmed checkout vendors (only their
+// import paths are referenced, same as elsewhere in this package).
+// foldBytes below reproduces exactly the per-row accumulation loop
+// bitAndString/bitOrString/bitXorString.UpdatePartialResult run against
+// rowsInGroup, so the fill-byte bug this covers is exercised the same way
+// a real row-by-row group accumulation would hit it.
+
+package aggfuncs
+
+import (
+	"bytes"
+	"testing"
+)
+
+// foldBytes accumulates each of ins into acc width-aligned via
+// growRightAligned(acc/in, width, fill) and the given binary op, mirroring
+// the UpdatePartialResult loop body for BIT_AND/OR/XOR over BINARY/wide-BIT
+// operands.
+func foldBytes(ins [][]byte, fill byte, op func(a, b byte) byte) []byte {
+	var acc []byte
+	for _, in := range ins {
+		width := len(in)
+		if width < len(acc) {
+			width = len(acc)
+		}
+		if acc == nil && fill == 0xFF {
+			acc = make([]byte, width)
+			for i := range acc {
+				acc[i] = 0xFF
+			}
+		}
+		acc = growRightAligned(acc, width, fill)
+		in = growRightAligned(in, width, fill)
+		for i := range acc {
+			acc[i] = op(acc[i], in[i])
+		}
+	}
+	return acc
+}
+
+func TestGrowRightAlignedZeroFillPadsLeadingZeros(t *testing.T) {
+	got := growRightAligned([]byte{0xAB}, 3, 0)
+	want := []byte{0x00, 0x00, 0xAB}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %x, want %x", got, want)
+	}
+}
+
+func TestGrowRightAlignedOneFillPadsLeadingOnes(t *testing.T) {
+	got := growRightAligned([]byte{0xAB}, 3, 0xFF)
+	want := []byte{0xFF, 0xFF, 0xAB}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %x, want %x", got, want)
+	}
+}
+
+func TestGrowRightAlignedNoOpWhenAlreadyWideEnough(t *testing.T) {
+	in := []byte{0x01, 0xFF}
+	got := growRightAligned(in, 2, 0xFF)
+	if !bytes.Equal(got, in) {
+		t.Fatalf("got %x, want %x (unchanged)", got, in)
+	}
+}
+
+// TestBitAndFoldMixedWidthPreservesHighOrderBits is the concrete
+// regression from the bug report: a wide row (0x01FF) followed by a
+// narrower row (0xFF) in the same group must AND to 0x01FF, not 0x00FF --
+// a narrower operand one-extends into an accumulator's high-order bytes,
+// the same way MySQL's BIT_AND treats a missing high bit as all-ones.
+func TestBitAndFoldMixedWidthPreservesHighOrderBits(t *testing.T) {
+	got := foldBytes([][]byte{{0x01, 0xFF}, {0xFF}}, 0xFF, func(a, b byte) byte { return a & b })
+	want := []byte{0x01, 0xFF}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("BIT_AND(0x01FF, 0xFF) = %x, want %x", got, want)
+	}
+}
+
+// TestBitAndFoldNarrowerThenWiderRow checks the other row order: starting
+// from a narrower value and then ANDing a wider one still only clears the
+// bits the wider row actually disagrees on, since the narrower row's
+// implicit high bytes are all-ones.
+func TestBitAndFoldNarrowerThenWiderRow(t *testing.T) {
+	got := foldBytes([][]byte{{0xFF}, {0x01, 0xFF}}, 0xFF, func(a, b byte) byte { return a & b })
+	want := []byte{0x01, 0xFF}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("BIT_AND(0xFF, 0x01FF) = %x, want %x", got, want)
+	}
+}
+
+// TestBitOrFoldMixedWidthZeroExtendsNarrowerRow checks BIT_OR's existing,
+// already-correct zero-extension: a narrower row contributes nothing to an
+// accumulator's high-order bytes.
+func TestBitOrFoldMixedWidthZeroExtendsNarrowerRow(t *testing.T) {
+	got := foldBytes([][]byte{{0x01, 0x00}, {0xFF}}, 0, func(a, b byte) byte { return a | b })
+	want := []byte{0x01, 0xFF}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("BIT_OR(0x0100, 0xFF) = %x, want %x", got, want)
+	}
+}
+
+// TestBitXorFoldMixedWidthZeroExtendsNarrowerRow checks BIT_XOR's
+// zero-extension the same way.
+func TestBitXorFoldMixedWidthZeroExtendsNarrowerRow(t *testing.T) {
+	got := foldBytes([][]byte{{0x01, 0x0F}, {0xFF}}, 0, func(a, b byte) byte { return a ^ b })
+	want := []byte{0x01, 0xF0}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("BIT_XOR(0x010F, 0xFF) = %x, want %x", got, want)
+	}
+}
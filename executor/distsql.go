@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/parser/mysql"
 	"github.com/pingcap/parser/terror"
 	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/executor/indexcheck"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	plannercore "github.com/pingcap/tidb/planner/core"
@@ -85,6 +86,62 @@ type lookupTableTask struct {
 	// Step 4   is  completed in "IndexLookUpExecutor.Next".
 	memUsage   int64
 	memTracker *memory.Tracker
+
+	// spill holds this task's rows on disk once tableWorker.executeTask has
+	// spilled them; nil as long as the task's rows fit in task.rows. Set at
+	// most once, after task.rows has its final (possibly keepOrder-sorted)
+	// contents, so spilling never has to contend with sort.Sort(task).
+	spill         *lookupTableTaskSpill
+	spillRowCount int
+
+	// streaming, rowStream, streamErr, and streamDone are used instead of
+	// rows/cursor/spill when tableWorker.executeTaskStreaming handles this
+	// task: rows are published to rowStream as soon as they're fetched
+	// rather than buffered into task.rows first, so a LIMIT/TopN pushdown
+	// above IndexLookUp can stop pulling long before every handle's row has
+	// come back. rowStream is bounded, so an IndexLookUpExecutor.Next that
+	// stops draining it applies back-pressure all the way down to the
+	// fetching goroutines instead of growing task.rows unbounded. streamErr
+	// is only valid to read once rowStream has been observed closed (the
+	// close happens-after the write, see tableWorker.pickAndExecTask).
+	streaming  bool
+	rowStream  chan chunk.Row
+	streamErr  error
+	streamDone bool
+}
+
+// nextStreamRow blocks for the next row tableWorker.executeTaskStreaming has
+// published, or returns ok=false once rowStream is closed and drained.
+func (task *lookupTableTask) nextStreamRow() (chunk.Row, bool) {
+	row, ok := <-task.rowStream
+	return row, ok
+}
+
+// rowCount returns the number of rows this task holds, whether or not it
+// has spilled.
+func (task *lookupTableTask) rowCount() int {
+	if task.spill != nil {
+		return task.spillRowCount
+	}
+	return len(task.rows)
+}
+
+// rowAt returns the i-th row in final (possibly keepOrder-sorted) order,
+// rehydrating it from disk if the task has spilled.
+func (task *lookupTableTask) rowAt(i int) (chunk.Row, error) {
+	if task.spill != nil {
+		return task.spill.container.GetRow(task.spill.rowPtrs[i])
+	}
+	return task.rows[i], nil
+}
+
+// closeSpill releases this task's on-disk rows, if any. It is safe to call
+// on a task that never spilled.
+func (task *lookupTableTask) closeSpill() error {
+	if task.spill == nil {
+		return nil
+	}
+	return task.spill.container.Close()
 }
 
 func (task *lookupTableTask) Len() int {
@@ -376,6 +433,38 @@ type IndexLookUpExecutor struct {
 	// isCheckOp is used to determine whether we need to check the consistency of the index data.
 	isCheckOp bool
 
+	// adaptiveStats is shared between the indexWorker and every
+	// tableWorker; it drives extractTaskHandles' adaptive batch sizing
+	// and is nil unless IndexLookupAdaptiveBatch is on.
+	adaptiveStats *indexLookupAdaptiveStats
+
+	// shardStats is shared between every tableWorker; it drives
+	// fetchTableRows' adaptive shard-count sizing and is nil unless
+	// IndexLookupTableConcurrencyAdaptive is on.
+	shardStats *shardReadStats
+
+	// handleFilter turns one index-side handle batch into the table-side
+	// batches indexWorker.buildTableTasks sends to workCh: identityHandleFilter
+	// unless IndexLookupDedupHandles is on, in which case it is a
+	// bloomCoalesceHandleFilter.
+	handleFilter handleFilter
+
+	// streamRows makes every tableWorker stream rows into each task's
+	// rowStream instead of buffering them into task.rows first; it is only
+	// turned on when IndexLookupStreamRows is set and the task can't need
+	// full materialization: isCheckOp needs every obtained handle in hand
+	// to diff against the expected handles, and the row cache (see
+	// row_cache.go) only ever populates itself from a fully-fetched row
+	// set, so either one forces the batched path instead.
+	streamRows bool
+
+	// repairRegistry is consulted by reportInconsistentIndex for every
+	// IndexRepairer to run against a detected inconsistency. nil falls
+	// back to indexcheck.Global; a session wanting its own repairers on
+	// top of (or instead of) the global set builds one with
+	// indexcheck.NewSessionRegistry and assigns it here before Open.
+	repairRegistry *indexcheck.Registry
+
 	corColInIdxSide bool
 	idxPlans        []plannercore.PhysicalPlan
 	corColInTblSide bool
@@ -438,6 +527,22 @@ func (e *IndexLookUpExecutor) startWorkers(ctx context.Context, initBatchSize in
 	// indexWorker will write to workCh and tableWorker will read from workCh,
 	// so fetching index and getting table data can run concurrently.
 	workCh := make(chan *lookupTableTask, 1)
+	if e.ctx.GetSessionVars().IndexLookupAdaptiveBatch {
+		e.adaptiveStats = &indexLookupAdaptiveStats{}
+	}
+	if e.ctx.GetSessionVars().IndexLookupDedupHandles {
+		e.handleFilter = newBloomCoalesceHandleFilter(initBatchSize*len(e.kvRanges), e.ctx.GetSessionVars().IndexLookupSize)
+	} else {
+		e.handleFilter = identityHandleFilter{}
+	}
+	if e.ctx.GetSessionVars().IndexLookupTableConcurrencyAdaptive {
+		e.shardStats = &shardReadStats{}
+	}
+	rowCacheTableID := getPhysicalTableID(e.table)
+	rowCacheUsable := isRowCacheEnabledForTable(rowCacheTableID) && getSessionRowCache(e.ctx.GetSessionVars()) != nil
+	if e.ctx.GetSessionVars().IndexLookupStreamRows && !e.isCheckOp && !rowCacheUsable {
+		e.streamRows = true
+	}
 	if err := e.startIndexWorker(ctx, e.kvRanges, workCh, initBatchSize); err != nil {
 		return err
 	}
@@ -474,14 +579,18 @@ func (e *IndexLookUpExecutor) startIndexWorker(ctx context.Context, kvRanges []k
 	}
 	result.Fetch(ctx)
 	worker := &indexWorker{
-		idxLookup:    e,
-		workCh:       workCh,
-		finished:     e.finished,
-		resultCh:     e.resultCh,
-		keepOrder:    e.keepOrder,
-		batchSize:    initBatchSize,
-		maxBatchSize: e.ctx.GetSessionVars().IndexLookupSize,
-		maxChunkSize: e.maxChunkSize,
+		idxLookup:     e,
+		workCh:        workCh,
+		finished:      e.finished,
+		resultCh:      e.resultCh,
+		keepOrder:     e.keepOrder,
+		streaming:     e.streamRows,
+		batchSize:     initBatchSize,
+		maxBatchSize:  e.ctx.GetSessionVars().IndexLookupSize,
+		maxChunkSize:  e.maxChunkSize,
+		adaptive:      e.adaptiveStats != nil,
+		minBatchSize:  e.ctx.GetSessionVars().IndexLookupMinBatchSize,
+		targetLatency: time.Duration(e.ctx.GetSessionVars().IndexLookupAdaptiveBatchTargetLatencyMS) * time.Millisecond,
 	}
 	if worker.batchSize > worker.maxBatchSize {
 		worker.batchSize = worker.maxBatchSize
@@ -519,14 +628,29 @@ func (e *IndexLookUpExecutor) startTableWorker(ctx context.Context, workCh <-cha
 	e.tblWorkerWg.Add(lookupConcurrencyLimit)
 	for i := 0; i < lookupConcurrencyLimit; i++ {
 		worker := &tableWorker{
-			idxLookup:      e,
-			workCh:         workCh,
-			finished:       e.finished,
-			buildTblReader: e.buildTableReader,
-			keepOrder:      e.keepOrder,
-			handleIdx:      e.handleIdx,
-			isCheckOp:      e.isCheckOp,
-			memTracker:     memory.NewTracker(tableWorkerLabel, -1),
+			idxLookup:           e,
+			workCh:              workCh,
+			finished:            e.finished,
+			buildTblReader:      e.buildTableReader,
+			keepOrder:           e.keepOrder,
+			handleIdx:           e.handleIdx,
+			isCheckOp:           e.isCheckOp,
+			memTracker:          memory.NewTracker(tableWorkerLabel, -1),
+			shardConcurrency:    1,
+			maxShardConcurrency: e.ctx.GetSessionVars().IndexLookupTableConcurrency,
+			shardAdaptive:       e.shardStats != nil,
+			shardTargetLatency:  time.Duration(e.ctx.GetSessionVars().IndexLookupTableConcurrencyTargetLatencyMS) * time.Millisecond,
+			shardStats:          e.shardStats,
+		}
+		if worker.maxShardConcurrency < 1 {
+			worker.maxShardConcurrency = 1
+		}
+		worker.rowCacheTableID = getPhysicalTableID(e.table)
+		if isRowCacheEnabledForTable(worker.rowCacheTableID) {
+			if cache := getSessionRowCache(e.ctx.GetSessionVars()); cache != nil {
+				worker.rowCache = cache
+				worker.rowCacheCodec = chunk.NewCodec(retTypes(e))
+			}
 		}
 		worker.memTracker.AttachTo(e.memTracker)
 		ctx1, cancel := context.WithCancel(ctx)
@@ -565,7 +689,13 @@ func (e *IndexLookUpExecutor) Close() error {
 	close(e.finished)
 	// Drain the resultCh and discard the result, in case that Next() doesn't fully
 	// consume the data, background worker still writing to resultCh and block forever.
-	for range e.resultCh {
+	// Streaming tasks don't need draining here the way spill needs closeSpill:
+	// every send to a streaming task's rowStream is itself selected against
+	// w.finished (see task_stream.go), so a producer that's already running
+	// unblocks on its own now that finished is closed, and a task that was
+	// never dequeued at all never had a producer to begin with.
+	for task := range e.resultCh {
+		terror.Log(task.closeSpill())
 	}
 	e.idxWorkerWg.Wait()
 	e.tblWorkerWg.Wait()
@@ -576,6 +706,12 @@ func (e *IndexLookUpExecutor) Close() error {
 	if e.runtimeStats != nil {
 		copStats := e.ctx.GetSessionVars().StmtCtx.RuntimeStatsColl.GetRootStats(e.idxPlans[0].ExplainID().String())
 		copStats.SetRowNum(e.feedback.Actual())
+		if e.adaptiveStats != nil {
+			logutil.Logger(context.Background()).Debug("index lookup adaptive batch stats", zap.Stringer("stats", e.adaptiveStats))
+		}
+		if e.shardStats != nil {
+			logutil.Logger(context.Background()).Debug("index lookup table-read shard stats", zap.Stringer("stats", e.shardStats))
+		}
 	}
 	return nil
 }
@@ -600,8 +736,29 @@ func (e *IndexLookUpExecutor) Next(ctx context.Context, req *chunk.Chunk) error
 		if resultTask == nil {
 			return nil
 		}
-		for resultTask.cursor < len(resultTask.rows) {
-			req.AppendRow(resultTask.rows[resultTask.cursor])
+		if resultTask.streaming {
+			for {
+				row, ok := resultTask.nextStreamRow()
+				if !ok {
+					resultTask.streamDone = true
+					if resultTask.streamErr != nil {
+						return resultTask.streamErr
+					}
+					break
+				}
+				req.AppendRow(row)
+				if req.IsFull() {
+					return nil
+				}
+			}
+			continue
+		}
+		for resultTask.cursor < resultTask.rowCount() {
+			row, err := resultTask.rowAt(resultTask.cursor)
+			if err != nil {
+				return err
+			}
+			req.AppendRow(row)
 			resultTask.cursor++
 			if req.IsFull() {
 				return nil
@@ -611,8 +768,14 @@ func (e *IndexLookUpExecutor) Next(ctx context.Context, req *chunk.Chunk) error
 }
 
 func (e *IndexLookUpExecutor) getResultTask() (*lookupTableTask, error) {
-	if e.resultCurr != nil && e.resultCurr.cursor < len(e.resultCurr.rows) {
-		return e.resultCurr, nil
+	if e.resultCurr != nil {
+		if e.resultCurr.streaming {
+			if !e.resultCurr.streamDone {
+				return e.resultCurr, nil
+			}
+		} else if e.resultCurr.cursor < e.resultCurr.rowCount() {
+			return e.resultCurr, nil
+		}
 	}
 	task, ok := <-e.resultCh
 	if !ok {
@@ -625,6 +788,9 @@ func (e *IndexLookUpExecutor) getResultTask() (*lookupTableTask, error) {
 	// Release the memory usage of last task before we handle a new task.
 	if e.resultCurr != nil {
 		e.resultCurr.memTracker.Consume(-e.resultCurr.memUsage)
+		if err := e.resultCurr.closeSpill(); err != nil {
+			return nil, err
+		}
 	}
 	e.resultCurr = task
 	return e.resultCurr, nil
@@ -638,10 +804,22 @@ type indexWorker struct {
 	resultCh  chan<- *lookupTableTask
 	keepOrder bool
 
+	// streaming mirrors idxLookup.streamRows; buildTableTasks copies it onto
+	// every task it builds.
+	streaming bool
+
 	// batchSize is for lightweight startup. It will be increased exponentially until reaches the max batch size value.
 	batchSize    int
 	maxBatchSize int
 	maxChunkSize int
+
+	// adaptive, minBatchSize, and targetLatency configure nextBatchSize's
+	// adaptive sizing; adaptive is false unless IndexLookupAdaptiveBatch
+	// is on, in which case batchSize is grown, shrunk, or held based on
+	// idxLookup.adaptiveStats instead of doubling unconditionally.
+	adaptive      bool
+	minBatchSize  int
+	targetLatency time.Duration
 }
 
 // fetchHandles fetches a batch of handles from index data and builds the index lookup tasks.
@@ -680,13 +858,23 @@ func (w *indexWorker) fetchHandles(ctx context.Context, result distsql.SelectRes
 			return count, nil
 		}
 		count += int64(len(handles))
-		task := w.buildTableTask(handles)
-		select {
-		case <-ctx.Done():
-			return count, nil
-		case <-w.finished:
-			return count, nil
-		case w.workCh <- task:
+		for _, task := range w.buildTableTasks(handles) {
+			select {
+			case w.workCh <- task:
+				w.idxLookup.adaptiveStats.recordWorkChSend(false)
+			default:
+				// workCh was already full: the table side hasn't drained the
+				// previous batch yet. Record the stall for adaptive batch
+				// sizing, then fall back to a blocking send.
+				w.idxLookup.adaptiveStats.recordWorkChSend(true)
+				select {
+				case <-ctx.Done():
+					return count, nil
+				case <-w.finished:
+					return count, nil
+				case w.workCh <- task:
+				}
+			}
 			w.resultCh <- task
 		}
 	}
@@ -707,28 +895,44 @@ func (w *indexWorker) extractTaskHandles(ctx context.Context, chk *chunk.Chunk,
 			handles = append(handles, chk.GetRow(i).GetInt64(0))
 		}
 	}
-	w.batchSize *= 2
-	if w.batchSize > w.maxBatchSize {
-		w.batchSize = w.maxBatchSize
+	minBatchSize := w.minBatchSize
+	if minBatchSize <= 0 {
+		minBatchSize = 1
 	}
+	w.batchSize = nextBatchSize(w.idxLookup.adaptiveStats, w.adaptive, w.batchSize, minBatchSize, w.maxBatchSize, w.targetLatency)
+	w.idxLookup.adaptiveStats.setLastBatchSize(w.batchSize)
 	return handles, nil
 }
 
-func (w *indexWorker) buildTableTask(handles []int64) *lookupTableTask {
-	var indexOrder map[int64]int
-	if w.keepOrder {
-		// Save the index order.
-		indexOrder = make(map[int64]int, len(handles))
-		for i, h := range handles {
-			indexOrder[h] = i
+// buildTableTasks runs handles through idxLookup.handleFilter and turns
+// each resulting batch into its own lookupTableTask. A single index-side
+// batch of handles can produce more than one task: the identityHandleFilter
+// never splits, but a bloomCoalesceHandleFilter can drop duplicates
+// entirely (yielding no task) or split an overlong batch into several.
+func (w *indexWorker) buildTableTasks(handles []int64) []*lookupTableTask {
+	batches := w.idxLookup.handleFilter.Filter(handles, w.keepOrder)
+	tasks := make([]*lookupTableTask, 0, len(batches))
+	for _, batch := range batches {
+		var indexOrder map[int64]int
+		if w.keepOrder {
+			// Save the index order.
+			indexOrder = make(map[int64]int, len(batch))
+			for i, h := range batch {
+				indexOrder[h] = i
+			}
 		}
+		task := &lookupTableTask{
+			handles:    batch,
+			indexOrder: indexOrder,
+			streaming:  w.streaming,
+		}
+		if w.streaming {
+			task.rowStream = make(chan chunk.Row, streamRowBufSize)
+		}
+		task.doneCh = make(chan error, 1)
+		tasks = append(tasks, task)
 	}
-	task := &lookupTableTask{
-		handles:    handles,
-		indexOrder: indexOrder,
-	}
-	task.doneCh = make(chan error, 1)
-	return task
+	return tasks
 }
 
 // tableWorker is used by IndexLookUpExecutor to maintain table lookup background goroutines.
@@ -745,6 +949,29 @@ type tableWorker struct {
 
 	// isCheckOp is used to determine whether we need to check the consistency of the index data.
 	isCheckOp bool
+
+	// shardConcurrency is how many concurrent table-read shards
+	// fetchTableRows splits a task's handles across; maxShardConcurrency,
+	// shardAdaptive, and shardTargetLatency configure nextShardCount's
+	// growth/shrink decisions the same way indexWorker's adaptive,
+	// minBatchSize, and targetLatency configure nextBatchSize. shardStats
+	// is shared by every tableWorker of the same IndexLookUpExecutor and is
+	// nil unless IndexLookupTableConcurrencyAdaptive is on.
+	shardConcurrency    int
+	maxShardConcurrency int
+	shardAdaptive       bool
+	shardTargetLatency  time.Duration
+	shardStats          *shardReadStats
+
+	// rowCache is this tableWorker's handle-to-row cache, shared across
+	// every task of this session's IndexLookUpExecutors on
+	// rowCacheTableID; nil unless the session has
+	// IndexLookupRowCacheCapacity configured and rowCacheTableID was opted
+	// in with EnableRowCacheForTable. rowCacheCodec encodes/decodes a
+	// chunk.Row to the bytes rowCache actually stores.
+	rowCache        *RowCache
+	rowCacheCodec   *chunk.Codec
+	rowCacheTableID int64
 }
 
 // pickAndExecTask picks tasks from workCh, and execute them.
@@ -772,6 +999,18 @@ func (w *tableWorker) pickAndExecTask(ctx context.Context) {
 		case <-w.finished:
 			return
 		}
+		if task.streaming {
+			// Unlike the batched path, a streaming task is "done" as soon as
+			// it's accepted: Next() can start draining task.rowStream while
+			// this same goroutine is still fetching. The real outcome is
+			// reported through task.streamErr, which is only safe to read
+			// once rowStream is observed closed (see the close below).
+			task.doneCh <- nil
+			err := w.executeTaskStreaming(ctx, task)
+			task.streamErr = err
+			close(task.rowStream)
+			continue
+		}
 		err := w.executeTask(ctx, task)
 		task.doneCh <- err
 	}
@@ -780,37 +1019,20 @@ func (w *tableWorker) pickAndExecTask(ctx context.Context) {
 // executeTask executes the table look up tasks. We will construct a table reader and send request by handles.
 // Then we hold the returning rows and finish this task.
 func (w *tableWorker) executeTask(ctx context.Context, task *lookupTableTask) error {
-	tableReader, err := w.buildTblReader(ctx, task.handles)
-	if err != nil {
-		logutil.Logger(ctx).Error("build table reader failed", zap.Error(err))
-		return err
-	}
-	defer terror.Call(tableReader.Close)
+	start := time.Now()
+	defer func() { w.idxLookup.adaptiveStats.observeTask(time.Since(start), len(task.handles)) }()
 
 	task.memTracker = w.memTracker
 	memUsage := int64(cap(task.handles) * 8)
 	task.memUsage = memUsage
 	task.memTracker.Consume(memUsage)
 	handleCnt := len(task.handles)
-	task.rows = make([]chunk.Row, 0, handleCnt)
-	for {
-		chk := newFirstChunk(tableReader)
-		err = tableReader.Next(ctx, chk)
-		if err != nil {
-			logutil.Logger(ctx).Error("table reader fetch next chunk failed", zap.Error(err))
-			return err
-		}
-		if chk.NumRows() == 0 {
-			break
-		}
-		memUsage = chk.MemoryUsage()
-		task.memUsage += memUsage
-		task.memTracker.Consume(memUsage)
-		iter := chunk.NewIterator4Chunk(chk)
-		for row := iter.Begin(); row != iter.End(); row = iter.Next() {
-			task.rows = append(task.rows, row)
-		}
+
+	rows, err := w.fetchTableRows(ctx, task)
+	if err != nil {
+		return err
 	}
+	task.rows = rows
 
 	memUsage = int64(cap(task.rows)) * int64(unsafe.Sizeof(chunk.Row{}))
 	task.memUsage += memUsage
@@ -828,26 +1050,23 @@ func (w *tableWorker) executeTask(ctx context.Context, task *lookupTableTask) er
 	}
 
 	if handleCnt != len(task.rows) {
+		obtainedHandlesMap := make(map[int64]struct{}, len(task.rows))
+		for _, row := range task.rows {
+			handle := row.GetInt64(w.handleIdx)
+			obtainedHandlesMap[handle] = struct{}{}
+		}
+		missing, extra := GetLackHandles(task.handles, obtainedHandlesMap)
+		w.reportInconsistentIndex(ctx, task, missing, extra)
+
 		if w.isCheckOp {
-			obtainedHandlesMap := make(map[int64]struct{}, len(task.rows))
-			for _, row := range task.rows {
-				handle := row.GetInt64(w.handleIdx)
-				obtainedHandlesMap[handle] = struct{}{}
-			}
 			return errors.Errorf("inconsistent index %s handle count %d isn't equal to value count %d, missing handles %v in a batch",
-				w.idxLookup.index.Name.O, handleCnt, len(task.rows), GetLackHandles(task.handles, obtainedHandlesMap))
+				w.idxLookup.index.Name.O, handleCnt, len(task.rows), missing)
 		}
 
 		if len(w.idxLookup.tblPlans) == 1 {
-			obtainedHandlesMap := make(map[int64]struct{}, len(task.rows))
-			for _, row := range task.rows {
-				handle := row.GetInt64(w.handleIdx)
-				obtainedHandlesMap[handle] = struct{}{}
-			}
-
 			logutil.Logger(ctx).Error("inconsistent index handles", zap.String("index", w.idxLookup.index.Name.O),
 				zap.Int("index_cnt", handleCnt), zap.Int("table_cnt", len(task.rows)),
-				zap.Int64s("missing_handles", GetLackHandles(task.handles, obtainedHandlesMap)),
+				zap.Int64s("missing_handles", missing), zap.Int64s("extra_handles", extra),
 				zap.Int64s("total_handles", task.handles))
 
 			// table scan in double read can never has conditions according to convertToIndexScan.
@@ -857,30 +1076,40 @@ func (w *tableWorker) executeTask(ctx context.Context, task *lookupTableTask) er
 		}
 	}
 
+	if err := w.maybeSpill(task); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// GetLackHandles gets the handles in expectedHandles but not in obtainedHandlesMap.
-func GetLackHandles(expectedHandles []int64, obtainedHandlesMap map[int64]struct{}) []int64 {
-	diffCnt := len(expectedHandles) - len(obtainedHandlesMap)
-	diffHandles := make([]int64, 0, diffCnt)
-	var cnt int
-	for _, handle := range expectedHandles {
-		isExist := false
-		if _, ok := obtainedHandlesMap[handle]; ok {
-			delete(obtainedHandlesMap, handle)
-			isExist = true
-		}
-		if !isExist {
-			diffHandles = append(diffHandles, handle)
-			cnt++
-			if cnt == diffCnt {
-				break
-			}
-		}
-	}
+// GetLackHandles gets the handles in expectedHandles but not in
+// obtainedHandlesMap (missing), and the handles left over in
+// obtainedHandlesMap once every expected handle has been matched (extra),
+// covering index/table corruption that drops rows as well as corruption
+// that fabricates extras. It is a thin wrapper over
+// indexcheck.GetHandleDiff kept here so existing callers in this file don't
+// need to import indexcheck directly.
+func GetLackHandles(expectedHandles []int64, obtainedHandlesMap map[int64]struct{}) (missing, extra []int64) {
+	return indexcheck.GetHandleDiff(expectedHandles, obtainedHandlesMap)
+}
 
-	return diffHandles
+// reportInconsistentIndex builds an indexcheck.Report for task's
+// inconsistency and hands it to indexcheck.Detect, which persists it
+// through the configured sink and runs any registered IndexRepairer. This
+// is always best-effort: Detect logs its own failures and never returns an
+// error, so a reporting/repair problem never masks or replaces the
+// inconsistency error executeTask itself returns.
+func (w *tableWorker) reportInconsistentIndex(ctx context.Context, task *lookupTableTask, missing, extra []int64) {
+	indexcheck.Detect(ctx, w.idxLookup.repairRegistry, indexcheck.Report{
+		IndexName:       w.idxLookup.index.Name.O,
+		TableID:         getPhysicalTableID(w.idxLookup.table),
+		ExpectedHandles: task.handles,
+		MissingHandles:  missing,
+		ExtraHandles:    extra,
+		StartTS:         w.idxLookup.ctx.GetSessionVars().TxnCtx.StartTS,
+		Timestamp:       time.Now(),
+	})
 }
 
 func getPhysicalPlanIDs(plans []plannercore.PhysicalPlan) []fmt.Stringer {
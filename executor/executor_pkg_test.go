@@ -235,3 +235,54 @@ func assertEqualStrings(c *C, got []field, expect []string) {
 		c.Assert(string(got[i].str), Equals, expect[i])
 	}
 }
+
+func (s *testExecSuite) TestJSONLinesParser(c *C) {
+	ldInfo := LoadDataInfo{
+		Format:      "JSON",
+		ColumnsInfo: []string{"id", "name", "tags", "line"},
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			// Nested JSON values: a number and an array alongside strings.
+			`{"id": 1, "name": "alice", "tags": ["a", "b"], "line": "x"}`,
+			[]string{"1", "alice", `["a","b"]`, "x"},
+		},
+		{
+			// A JSON string with an embedded newline must decode intact.
+			`{"id": 2, "name": "bob", "line": "first\nsecond"}`,
+			[]string{"2", "bob", "", "first\nsecond"},
+		},
+		{
+			// A missing key produces an empty field rather than an error.
+			`{"id": 3}`,
+			[]string{"3", "", "", ""},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ldInfo.getFieldsFromLine([]byte(test.input))
+		c.Assert(err, IsNil, Commentf("failed: %s", test.input))
+		assertEqualStrings(c, got, test.expected)
+	}
+}
+
+func (s *testExecSuite) TestTSVParserHeaderDriven(c *C) {
+	parser := newTSVParser([]string{"id", "name"})
+	parser.ConsumeHeader([]byte("name\tid\textra"))
+
+	got, err := parser.Parse([]byte("alice\t1\tignored"))
+	c.Assert(err, IsNil)
+	assertEqualStrings(c, got, []string{"1", "alice"})
+}
+
+func (s *testExecSuite) TestTSVParserPositional(c *C) {
+	parser := newTSVParser([]string{"id", "name"})
+
+	got, err := parser.Parse([]byte("1\talice"))
+	c.Assert(err, IsNil)
+	assertEqualStrings(c, got, []string{"1", "alice"})
+}
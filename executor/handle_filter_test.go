@@ -0,0 +1,64 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testHandleFilterSuite{})
+
+type testHandleFilterSuite struct{}
+
+func (s *testHandleFilterSuite) TestIdentityHandleFilterForwardsUnchanged(c *C) {
+	f := identityHandleFilter{}
+	batches := f.Filter([]int64{3, 1, 2}, true)
+	c.Assert(batches, DeepEquals, [][]int64{{3, 1, 2}})
+	c.Assert(f.Filter(nil, true), IsNil)
+}
+
+func (s *testHandleFilterSuite) TestBloomCoalesceDropsDuplicatesAcrossBatches(c *C) {
+	f := newBloomCoalesceHandleFilter(1024, 1024)
+	first := f.Filter([]int64{1, 2, 3}, false)
+	c.Assert(first, DeepEquals, [][]int64{{1, 2, 3}})
+
+	second := f.Filter([]int64{2, 3, 4}, false)
+	c.Assert(second, DeepEquals, [][]int64{{4}})
+}
+
+func (s *testHandleFilterSuite) TestBloomCoalesceSortsUnlessKeepOrder(c *C) {
+	f := newBloomCoalesceHandleFilter(1024, 1024)
+	batches := f.Filter([]int64{5, 1, 3}, false)
+	c.Assert(batches, DeepEquals, [][]int64{{1, 3, 5}})
+
+	f2 := newBloomCoalesceHandleFilter(1024, 1024)
+	batches2 := f2.Filter([]int64{5, 1, 3}, true)
+	c.Assert(batches2, DeepEquals, [][]int64{{5, 1, 3}})
+}
+
+func (s *testHandleFilterSuite) TestBloomCoalesceSplitsOversizedBatch(c *C) {
+	f := newBloomCoalesceHandleFilter(1024, 2)
+	batches := f.Filter([]int64{1, 2, 3, 4, 5}, true)
+	c.Assert(batches, DeepEquals, [][]int64{{1, 2}, {3, 4}, {5}})
+}
+
+func (s *testHandleFilterSuite) TestHandleBloomFilterNeverFalseNegative(c *C) {
+	f := newHandleBloomFilter(256)
+	for i := int64(0); i < 256; i++ {
+		f.add(i)
+	}
+	for i := int64(0); i < 256; i++ {
+		c.Assert(f.mightContain(i), IsTrue)
+	}
+}
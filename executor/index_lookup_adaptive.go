@@ -0,0 +1,161 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// adaptiveBatchEMAWeight is the exponential-moving-average weight given
+// to each new tableWorker task sample; lower reacts more slowly, higher
+// chases noise.
+const adaptiveBatchEMAWeight = 0.2
+
+// adaptiveBatchFullStreakThreshold is how many consecutive workCh sends
+// have to block before extractTaskHandles treats the table side as
+// saturated and shrinks batchSize, independent of the latency average.
+const adaptiveBatchFullStreakThreshold = 3
+
+// indexLookupAdaptiveStats is shared between every tableWorker and the
+// indexWorker of a single IndexLookUpExecutor. tableWorkers fold their
+// per-task latency and row count in via observeTask; the indexWorker
+// reads the resulting moving averages in extractTaskHandles to grow,
+// shrink, or hold batchSize to match what the table side can actually
+// keep up with, instead of doubling blindly up to maxBatchSize.
+//
+// All fields are updated with atomic operations so tableWorkers never
+// contend with each other or with the indexWorker on a lock in the hot
+// path.
+type indexLookupAdaptiveStats struct {
+	// avgLatencyNanos is an EMA of tableWorker task latency, in
+	// nanoseconds.
+	avgLatencyNanos int64
+	// avgRowsPerSecMilli is an EMA of tableWorker throughput, in rows per
+	// second times 1000 (fixed-point, so the average can live in an
+	// int64 rather than needing atomic float support).
+	avgRowsPerSecMilli int64
+	// workChFullStreak counts consecutive workCh sends that had to block
+	// because it was already full; reset to 0 by the first send that
+	// doesn't block.
+	workChFullStreak int32
+	// lastBatchSize is the most recent batchSize extractTaskHandles chose,
+	// surfaced in runtime stats.
+	lastBatchSize int32
+}
+
+func casEMAInt64(addr *int64, sample int64, weight float64) {
+	for {
+		old := atomic.LoadInt64(addr)
+		next := sample
+		if old != 0 {
+			next = int64(float64(old)*(1-weight) + float64(sample)*weight)
+		}
+		if atomic.CompareAndSwapInt64(addr, old, next) {
+			return
+		}
+	}
+}
+
+// observeTask folds one tableWorker task's latency and row count into
+// the moving averages.
+func (s *indexLookupAdaptiveStats) observeTask(latency time.Duration, rows int) {
+	if s == nil {
+		return
+	}
+	casEMAInt64(&s.avgLatencyNanos, latency.Nanoseconds(), adaptiveBatchEMAWeight)
+	if latency > 0 {
+		rowsPerSecMilli := int64(float64(rows) * 1000 * float64(time.Second) / float64(latency))
+		casEMAInt64(&s.avgRowsPerSecMilli, rowsPerSecMilli, adaptiveBatchEMAWeight)
+	}
+}
+
+// recordWorkChSend updates the consecutive-full streak: blocked is true
+// when the indexWorker had to wait for a slot in workCh to send this
+// task.
+func (s *indexLookupAdaptiveStats) recordWorkChSend(blocked bool) {
+	if s == nil {
+		return
+	}
+	if blocked {
+		atomic.AddInt32(&s.workChFullStreak, 1)
+	} else {
+		atomic.StoreInt32(&s.workChFullStreak, 0)
+	}
+}
+
+func (s *indexLookupAdaptiveStats) avgLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.avgLatencyNanos))
+}
+
+func (s *indexLookupAdaptiveStats) fullStreak() int32 {
+	return atomic.LoadInt32(&s.workChFullStreak)
+}
+
+func (s *indexLookupAdaptiveStats) setLastBatchSize(n int) {
+	if s == nil {
+		return
+	}
+	atomic.StoreInt32(&s.lastBatchSize, int32(n))
+}
+
+// String renders the adaptive batching state for runtime stats/EXPLAIN
+// ANALYZE output.
+func (s *indexLookupAdaptiveStats) String() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("index_lookup_adaptive_batch: {last_batch_size: %d, avg_table_task: %v, avg_rows/s: %.1f}",
+		atomic.LoadInt32(&s.lastBatchSize), s.avgLatency(), float64(atomic.LoadInt64(&s.avgRowsPerSecMilli))/1000)
+}
+
+// nextBatchSize decides the next batchSize for extractTaskHandles.
+// adaptive is the caller's IndexLookupAdaptiveBatch toggle; when false
+// this just reproduces the fixed doubling distsql.go always used.
+// targetLatency of 0 disables the latency-based shrink/grow rules.
+func nextBatchSize(stats *indexLookupAdaptiveStats, adaptive bool, current, minSize, maxSize int, targetLatency time.Duration) int {
+	grow := func(n int) int {
+		n *= 2
+		if n > maxSize {
+			n = maxSize
+		}
+		return n
+	}
+	shrink := func(n int) int {
+		n /= 2
+		if n < minSize {
+			n = minSize
+		}
+		return n
+	}
+
+	if !adaptive || stats == nil {
+		return grow(current)
+	}
+
+	avgLatency := stats.avgLatency()
+	switch {
+	case stats.fullStreak() >= adaptiveBatchFullStreakThreshold:
+		// The table side hasn't kept up with several batches in a row;
+		// shrinking reduces how much gets stuck in workCh/memory.
+		return shrink(current)
+	case targetLatency > 0 && avgLatency > targetLatency:
+		return shrink(current)
+	case targetLatency > 0 && avgLatency > 0 && avgLatency < targetLatency/2:
+		return grow(current)
+	default:
+		return current
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testIndexLookupAdaptiveSuite{})
+
+type testIndexLookupAdaptiveSuite struct{}
+
+// TestNonAdaptiveDoublesUnconditionally checks that with adaptive off,
+// nextBatchSize still reproduces the old fixed-doubling behavior.
+func (s *testIndexLookupAdaptiveSuite) TestNonAdaptiveDoublesUnconditionally(c *C) {
+	c.Assert(nextBatchSize(nil, false, 32, 1, 1024, 0), Equals, 64)
+	c.Assert(nextBatchSize(nil, false, 900, 1, 1024, 0), Equals, 1024)
+}
+
+// TestAdaptiveShrinksOnFullWorkChStreak checks that enough consecutive
+// blocked workCh sends shrinks batchSize even when latency looks fine.
+func (s *testIndexLookupAdaptiveSuite) TestAdaptiveShrinksOnFullWorkChStreak(c *C) {
+	stats := &indexLookupAdaptiveStats{}
+	for i := 0; i < adaptiveBatchFullStreakThreshold; i++ {
+		stats.recordWorkChSend(true)
+	}
+	c.Assert(nextBatchSize(stats, true, 64, 4, 1024, 0), Equals, 32)
+}
+
+// TestAdaptiveShrinksWhenLatencyExceedsTarget checks that a table-side
+// latency above the target shrinks batchSize.
+func (s *testIndexLookupAdaptiveSuite) TestAdaptiveShrinksWhenLatencyExceedsTarget(c *C) {
+	stats := &indexLookupAdaptiveStats{}
+	stats.observeTask(500*time.Millisecond, 100)
+	c.Assert(nextBatchSize(stats, true, 64, 4, 1024, 50*time.Millisecond), Equals, 32)
+}
+
+// TestAdaptiveGrowsWhenFastAndUnderHalfTarget checks that comfortably
+// fast table-side latency still lets batchSize grow.
+func (s *testIndexLookupAdaptiveSuite) TestAdaptiveGrowsWhenFastAndUnderHalfTarget(c *C) {
+	stats := &indexLookupAdaptiveStats{}
+	stats.observeTask(5*time.Millisecond, 100)
+	c.Assert(nextBatchSize(stats, true, 64, 4, 1024, 50*time.Millisecond), Equals, 128)
+}
+
+// TestAdaptiveHoldsBetweenThresholds checks that a latency between half
+// the target and the target itself holds batchSize steady rather than
+// oscillating.
+func (s *testIndexLookupAdaptiveSuite) TestAdaptiveHoldsBetweenThresholds(c *C) {
+	stats := &indexLookupAdaptiveStats{}
+	stats.observeTask(40*time.Millisecond, 100)
+	c.Assert(nextBatchSize(stats, true, 64, 4, 1024, 50*time.Millisecond), Equals, 64)
+}
+
+// TestShrinkRespectsMinBatchSize checks that repeated shrinking floors
+// out at minBatchSize instead of going lower.
+func (s *testIndexLookupAdaptiveSuite) TestShrinkRespectsMinBatchSize(c *C) {
+	stats := &indexLookupAdaptiveStats{}
+	for i := 0; i < adaptiveBatchFullStreakThreshold; i++ {
+		stats.recordWorkChSend(true)
+	}
+	c.Assert(nextBatchSize(stats, true, 5, 4, 1024, 0), Equals, 4)
+}
+
+// TestObserveTaskIsNilSafe checks that every exported method on a nil
+// *indexLookupAdaptiveStats is a no-op, since adaptiveStats is nil
+// whenever IndexLookupAdaptiveBatch is off.
+func (s *testIndexLookupAdaptiveSuite) TestObserveTaskIsNilSafe(c *C) {
+	var stats *indexLookupAdaptiveStats
+	stats.observeTask(time.Millisecond, 10)
+	stats.recordWorkChSend(true)
+	stats.setLastBatchSize(16)
+	c.Assert(stats.String(), Equals, "")
+}
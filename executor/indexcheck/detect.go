@@ -0,0 +1,84 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexcheck
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// sink is the process-wide ReportSink every Detect call writes through;
+// nil (the default) makes Detect a no-op beyond the repairer pass, so a
+// cluster that hasn't configured a sink doesn't pay for one.
+var sink atomic.Value // ReportSink
+
+// SetSink installs the sink every subsequent Detect call writes reports to.
+// Passing nil disables report persistence; repairers still run.
+func SetSink(s ReportSink) {
+	sink.Store(&s)
+}
+
+func getSink() ReportSink {
+	v := sink.Load()
+	if v == nil {
+		return nil
+	}
+	return *(v.(*ReportSink))
+}
+
+// Detect records report through the configured sink and runs every
+// IndexRepairer in registry (falling back to Global if registry is nil)
+// against it. Both steps are best-effort: failures are logged, never
+// returned, since detection runs on the hot path of an already-failing
+// statement and must not add a second failure mode of its own.
+func Detect(ctx context.Context, registry *Registry, report Report) {
+	if s := getSink(); s != nil {
+		if err := s.Write(report); err != nil {
+			logutil.Logger(ctx).Warn("indexcheck: write report failed", zap.String("index", report.IndexName), zap.Error(err))
+		}
+	}
+
+	if registry == nil {
+		registry = Global
+	}
+	for _, repairer := range registry.All() {
+		if err := repairer.Repair(ctx, report); err != nil {
+			logutil.Logger(ctx).Warn("indexcheck: repairer failed", zap.String("index", report.IndexName), zap.Error(err))
+		}
+	}
+}
+
+// GetHandleDiff splits expectedHandles against obtainedHandlesMap into the
+// handles missing from the table side (expected but not obtained) and the
+// handles extra on the table side (obtained but not expected), covering
+// corruption in either direction. obtainedHandlesMap is consumed: matched
+// entries are deleted from it, so whatever remains once every expected
+// handle has been checked is, by construction, the extra set.
+func GetHandleDiff(expectedHandles []int64, obtainedHandlesMap map[int64]struct{}) (missing, extra []int64) {
+	for _, handle := range expectedHandles {
+		if _, ok := obtainedHandlesMap[handle]; ok {
+			delete(obtainedHandlesMap, handle)
+		} else {
+			missing = append(missing, handle)
+		}
+	}
+	extra = make([]int64, 0, len(obtainedHandlesMap))
+	for handle := range obtainedHandlesMap {
+		extra = append(extra, handle)
+	}
+	return missing, extra
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexcheck
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testIndexCheckSuite{})
+
+type testIndexCheckSuite struct{}
+
+// TestGetHandleDiffMissingOnly checks the common case: every obtained
+// handle was also expected, so only MissingHandles is non-empty.
+func (s *testIndexCheckSuite) TestGetHandleDiffMissingOnly(c *C) {
+	obtained := map[int64]struct{}{1: {}, 2: {}}
+	missing, extra := GetHandleDiff([]int64{1, 2, 3}, obtained)
+	c.Assert(missing, DeepEquals, []int64{3})
+	c.Assert(extra, HasLen, 0)
+}
+
+// TestGetHandleDiffBothDirections checks corruption that both drops an
+// expected handle and fabricates one never read off the index.
+func (s *testIndexCheckSuite) TestGetHandleDiffBothDirections(c *C) {
+	obtained := map[int64]struct{}{1: {}, 99: {}}
+	missing, extra := GetHandleDiff([]int64{1, 2}, obtained)
+	c.Assert(missing, DeepEquals, []int64{2})
+	c.Assert(extra, DeepEquals, []int64{99})
+}
+
+// fakeRepairer records every Report it is asked to repair.
+type fakeRepairer struct {
+	seen []Report
+}
+
+func (f *fakeRepairer) Repair(ctx context.Context, report Report) error {
+	f.seen = append(f.seen, report)
+	return nil
+}
+
+// TestRegistryAllPrefersSessionOverParent checks that a session-scoped
+// Registry's own entries shadow a same-named entry on its parent, while
+// still exposing the parent's other entries.
+func (s *testIndexCheckSuite) TestRegistryAllPrefersSessionOverParent(c *C) {
+	parent := NewRegistry()
+	parentOnly := &fakeRepairer{}
+	shadowed := &fakeRepairer{}
+	parent.Register("only-on-parent", parentOnly)
+	parent.Register("shared", shadowed)
+
+	session := NewSessionRegistry(parent)
+	override := &fakeRepairer{}
+	session.Register("shared", override)
+
+	all := session.All()
+	c.Assert(all, HasLen, 2)
+	var sawOverride, sawShadowed, sawParentOnly bool
+	for _, r := range all {
+		switch r.(*fakeRepairer) {
+		case override:
+			sawOverride = true
+		case shadowed:
+			sawShadowed = true
+		case parentOnly:
+			sawParentOnly = true
+		}
+	}
+	c.Assert(sawOverride, IsTrue)
+	c.Assert(sawShadowed, IsFalse)
+	c.Assert(sawParentOnly, IsTrue)
+}
+
+// TestDetectRunsRegisteredRepairer checks that Detect invokes every
+// repairer visible through the given registry with the report it built.
+func (s *testIndexCheckSuite) TestDetectRunsRegisteredRepairer(c *C) {
+	registry := NewRegistry()
+	repairer := &fakeRepairer{}
+	registry.Register("test", repairer)
+
+	report := Report{IndexName: "idx_a", MissingHandles: []int64{7}}
+	Detect(context.Background(), registry, report)
+
+	c.Assert(repairer.seen, HasLen, 1)
+	c.Assert(repairer.seen[0].IndexName, Equals, "idx_a")
+}
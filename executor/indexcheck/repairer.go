@@ -0,0 +1,98 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexcheck
+
+import (
+	"context"
+	"sync"
+)
+
+// IndexRepairer reacts to a detected Report, e.g. by re-reading the
+// affected handles from a later snapshot to confirm the corruption is real,
+// or by enqueueing an ADMIN RECOVER INDEX job. Repair's error is logged by
+// the caller, not surfaced to the statement that triggered detection --
+// repair is always best-effort. Detect calls Repair synchronously, on the
+// goroutine that detected the inconsistency.
+type IndexRepairer interface {
+	Repair(ctx context.Context, report Report) error
+}
+
+// Registry is a named set of IndexRepairers. The package keeps one global
+// Registry that every session consults by default, plus lets a session
+// hold its own Registry -- built with NewSessionRegistry -- to add or
+// override repairers without affecting other sessions. Registering a name
+// already in use replaces the previous repairer.
+type Registry struct {
+	parent *Registry
+
+	mu        sync.RWMutex
+	repairers map[string]IndexRepairer
+}
+
+// NewRegistry builds an empty, standalone Registry.
+func NewRegistry() *Registry {
+	return &Registry{repairers: make(map[string]IndexRepairer)}
+}
+
+// NewSessionRegistry builds a Registry that falls back to parent for any
+// name it does not itself hold. A nil parent is treated as the package's
+// Global registry, which is the common case for a session that only wants
+// to add a repairer on top of whatever is registered process-wide.
+func NewSessionRegistry(parent *Registry) *Registry {
+	if parent == nil {
+		parent = Global
+	}
+	return &Registry{parent: parent, repairers: make(map[string]IndexRepairer)}
+}
+
+// Register adds or replaces the named repairer on r.
+func (r *Registry) Register(name string, repairer IndexRepairer) {
+	r.mu.Lock()
+	r.repairers[name] = repairer
+	r.mu.Unlock()
+}
+
+// Unregister removes the named repairer from r, if present. It never
+// removes a repairer registered on r's parent.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.repairers, name)
+	r.mu.Unlock()
+}
+
+// All returns every repairer visible to r: its own, plus its parent's
+// (recursively), with r's own entries taking precedence over a
+// same-named parent entry.
+func (r *Registry) All() []IndexRepairer {
+	seen := make(map[string]IndexRepairer)
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mu.RLock()
+		for name, repairer := range reg.repairers {
+			if _, ok := seen[name]; !ok {
+				seen[name] = repairer
+			}
+		}
+		reg.mu.RUnlock()
+	}
+	repairers := make([]IndexRepairer, 0, len(seen))
+	for _, repairer := range seen {
+		repairers = append(repairers, repairer)
+	}
+	return repairers
+}
+
+// Global is the process-wide Registry consulted by every session that
+// hasn't been given its own session-scoped Registry via
+// NewSessionRegistry.
+var Global = NewRegistry()
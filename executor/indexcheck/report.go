@@ -0,0 +1,52 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package indexcheck captures, persists, and reacts to index/table
+// inconsistencies detected by IndexLookUpExecutor. Before this package
+// existed, executor/distsql.go only logged "inconsistent index handles" and
+// returned an error -- the missing (or, in corruption that goes both ways,
+// extra) handles were lost once the statement ended, leaving nothing for an
+// operator to act on after the fact.
+package indexcheck
+
+import "time"
+
+// Report is the structured record of one index/table inconsistency,
+// detected when a tableWorker's fetched rows don't match the handles its
+// indexWorker read for the same batch.
+type Report struct {
+	// IndexName is the offending index's name as IndexLookUpExecutor
+	// already logs it (index.Name.O), without the table name; TableID
+	// identifies the table.
+	IndexName string
+	TableID   int64
+
+	// ExpectedHandles are the handles read off the index side for this
+	// batch; MissingHandles and ExtraHandles are both subsets/complements
+	// derived from diffing ExpectedHandles against what the table side
+	// actually returned -- see GetHandleDiff.
+	ExpectedHandles []int64
+	MissingHandles  []int64
+	ExtraHandles    []int64
+
+	// RegionIDs are the table-side coprocessor regions this batch's
+	// handles were split across, when the caller has them on hand (e.g.
+	// from the dataReaderBuilder's KeyRanges); nil if unknown.
+	RegionIDs []uint64
+
+	// StartTS is the transaction's start timestamp, so an operator can
+	// correlate a report with the statement and transaction that produced
+	// it in logs or in information_schema.
+	StartTS   uint64
+	Timestamp time.Time
+}
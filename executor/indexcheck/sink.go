@@ -0,0 +1,164 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReportSink persists or forwards a Report once Detect has built it. Write
+// must be safe to call concurrently, since tableWorkers of the same
+// IndexLookUpExecutor can all detect inconsistencies at once.
+type ReportSink interface {
+	Write(report Report) error
+	Close() error
+}
+
+// MultiSink fans a Report out to every sink in order, collecting (but not
+// stopping on) individual failures -- one sink being down shouldn't hide a
+// report from the others.
+type MultiSink []ReportSink
+
+func (m MultiSink) Write(report Report) error {
+	var firstErr error
+	for _, sink := range m {
+		if err := sink.Write(report); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m MultiSink) Close() error {
+	var firstErr error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FileSink is a rotating WAL-style ReportSink: reports are appended as JSON
+// lines to a local file, which is rotated to a ".1" suffix (replacing any
+// previous rotation) once it clears maxBytes. It favors simplicity over a
+// numbered backlog of rotated files, since these reports are meant to be
+// read promptly by an operator or shipped onward, not retained indefinitely.
+type FileSink struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (creating if necessary) path for appending and returns
+// a FileSink that rotates once the file reaches maxBytes. maxBytes <= 0
+// disables rotation.
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileSink{path: path, maxBytes: maxBytes, file: f, size: info.Size()}, nil
+}
+
+func (s *FileSink) Write(report Report) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxBytes > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotateLocked replaces path.1 with the current file and starts a fresh
+// one. Callers must hold s.mu.
+func (s *FileSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	rotated := s.path + ".1"
+	if err := os.Rename(s.path, rotated); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.size = 0
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// HTTPSink POSTs each Report as a JSON body to url. It is meant as a
+// best-effort forward to an external diagnostics collector; a slow or
+// unreachable endpoint only affects Write's own return value, never the
+// caller's statement.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink builds an HTTPSink posting to url with the given request
+// timeout.
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPSink) Write(report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("indexcheck: HTTPSink got status %d from %s", resp.StatusCode, s.url)
+	}
+	return nil
+}
+
+func (s *HTTPSink) Close() error { return nil }
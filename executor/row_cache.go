@@ -0,0 +1,214 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RowCache is an LRU cache of encoded chunk.Row bytes keyed by
+// (namespace, handle), namespace being a physical table ID. It is modeled
+// on goleveldb's namespaced lruCache: one shared eviction list backs every
+// namespace, so a hot table can't be starved of cache space by a cold one,
+// while PurgeNamespace can still drop exactly one table's entries without
+// walking the others.
+//
+// capacity and size are both in bytes of encoded row data; alive is the
+// live entry count, reported alongside the byte counters since a cache
+// full of many small rows evicts very differently from one full of few
+// large ones.
+type RowCache struct {
+	mu sync.Mutex
+
+	capacity int64
+	size     int64
+	alive    int
+
+	namespaces map[int64]map[int64]*rowCacheNode
+	head, tail rowCacheNode // sentinel nodes; head.next is most recently used
+}
+
+type rowCacheNode struct {
+	namespace  int64
+	handle     int64
+	value      []byte
+	prev, next *rowCacheNode
+}
+
+// NewRowCache builds an empty RowCache with room for capacity bytes of
+// encoded row data.
+func NewRowCache(capacity int64) *RowCache {
+	c := &RowCache{
+		capacity:   capacity,
+		namespaces: make(map[int64]map[int64]*rowCacheNode),
+	}
+	c.head.next = &c.tail
+	c.tail.prev = &c.head
+	return c
+}
+
+func (c *RowCache) unlink(n *rowCacheNode) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+}
+
+func (c *RowCache) pushFront(n *rowCacheNode) {
+	n.next = c.head.next
+	n.prev = &c.head
+	c.head.next.prev = n
+	c.head.next = n
+}
+
+// Get returns the cached value for (namespace, handle), if present, and
+// marks it most recently used.
+func (c *RowCache) Get(namespace, handle int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byHandle, ok := c.namespaces[namespace]
+	if !ok {
+		rowCacheMissCounter.Inc()
+		return nil, false
+	}
+	n, ok := byHandle[handle]
+	if !ok {
+		rowCacheMissCounter.Inc()
+		return nil, false
+	}
+	c.unlink(n)
+	c.pushFront(n)
+	rowCacheHitCounter.Inc()
+	return n.value, true
+}
+
+// Put inserts or replaces the cached value for (namespace, handle),
+// evicting least-recently-used entries (from any namespace) until the
+// cache is back within capacity.
+func (c *RowCache) Put(namespace, handle int64, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byHandle, ok := c.namespaces[namespace]
+	if !ok {
+		byHandle = make(map[int64]*rowCacheNode)
+		c.namespaces[namespace] = byHandle
+	}
+	if old, ok := byHandle[handle]; ok {
+		c.unlink(old)
+		c.size -= int64(len(old.value))
+		c.alive--
+	}
+
+	n := &rowCacheNode{namespace: namespace, handle: handle, value: value}
+	byHandle[handle] = n
+	c.pushFront(n)
+	c.size += int64(len(value))
+	c.alive++
+
+	for c.size > c.capacity && c.tail.prev != &c.head {
+		c.evictLocked(c.tail.prev)
+	}
+	rowCacheSizeGauge.Set(float64(c.size))
+	rowCacheAliveGauge.Set(float64(c.alive))
+}
+
+// evictLocked drops n from the cache. Callers must hold c.mu.
+func (c *RowCache) evictLocked(n *rowCacheNode) {
+	c.unlink(n)
+	delete(c.namespaces[n.namespace], n.handle)
+	if len(c.namespaces[n.namespace]) == 0 {
+		delete(c.namespaces, n.namespace)
+	}
+	c.size -= int64(len(n.value))
+	c.alive--
+	rowCacheEvictionCounter.Inc()
+}
+
+// PurgeNamespace drops every entry cached for namespace, e.g. because a DDL
+// changed that table's schema version and cached rows may no longer be
+// valid.
+func (c *RowCache) PurgeNamespace(namespace int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byHandle, ok := c.namespaces[namespace]
+	if !ok {
+		return
+	}
+	for _, n := range byHandle {
+		c.unlink(n)
+		c.size -= int64(len(n.value))
+		c.alive--
+	}
+	delete(c.namespaces, namespace)
+	rowCacheSizeGauge.Set(float64(c.size))
+	rowCacheAliveGauge.Set(float64(c.alive))
+}
+
+// Capacity, Size, and Alive report the cache's configured capacity and its
+// current byte size / live entry count, for tests and diagnostics.
+func (c *RowCache) Capacity() int64 { return c.capacity }
+func (c *RowCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+func (c *RowCache) Alive() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.alive
+}
+
+var (
+	rowCacheHitCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "tidb",
+		Subsystem: "executor",
+		Name:      "index_lookup_row_cache_hits_total",
+		Help:      "Number of IndexLookUp handle probes served from the row cache.",
+	})
+	rowCacheMissCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "tidb",
+		Subsystem: "executor",
+		Name:      "index_lookup_row_cache_misses_total",
+		Help:      "Number of IndexLookUp handle probes not found in the row cache.",
+	})
+	rowCacheEvictionCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "tidb",
+		Subsystem: "executor",
+		Name:      "index_lookup_row_cache_evictions_total",
+		Help:      "Number of row cache entries evicted to stay within capacity.",
+	})
+	rowCacheSizeGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tidb",
+		Subsystem: "executor",
+		Name:      "index_lookup_row_cache_bytes",
+		Help:      "Current encoded-row byte size of the row cache.",
+	})
+	rowCacheAliveGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tidb",
+		Subsystem: "executor",
+		Name:      "index_lookup_row_cache_alive_entries",
+		Help:      "Current number of live entries in the row cache.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(rowCacheHitCounter)
+	prometheus.MustRegister(rowCacheMissCounter)
+	prometheus.MustRegister(rowCacheEvictionCounter)
+	prometheus.MustRegister(rowCacheSizeGauge)
+	prometheus.MustRegister(rowCacheAliveGauge)
+}
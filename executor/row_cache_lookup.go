@@ -0,0 +1,85 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/golang/snappy"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// probeRowCache splits handles into the rows already cached for
+// w.rowCacheTableID and the handles that still need a table read. Hits are
+// decoded eagerly since fetchTableRows needs real chunk.Rows to append
+// alongside the miss rows it is about to fetch.
+func (w *tableWorker) probeRowCache(ctx context.Context, handles []int64) (hits []chunk.Row, missHandles []int64) {
+	hits = make([]chunk.Row, 0, len(handles))
+	missHandles = make([]int64, 0, len(handles))
+	for _, h := range handles {
+		encoded, ok := w.rowCache.Get(w.rowCacheTableID, h)
+		if !ok {
+			missHandles = append(missHandles, h)
+			continue
+		}
+		row, err := w.decodeCachedRow(encoded)
+		if err != nil {
+			logutil.Logger(ctx).Warn("index lookup row cache: decode cached row failed, re-fetching",
+				zap.Int64("table_id", w.rowCacheTableID), zap.Int64("handle", h), zap.Error(err))
+			missHandles = append(missHandles, h)
+			continue
+		}
+		hits = append(hits, row)
+	}
+	return hits, missHandles
+}
+
+// populateRowCache encodes and caches every freshly-fetched row, keyed by
+// the handle the row itself carries (not by position: shard order doesn't
+// necessarily match the miss-handle order it was requested in).
+func (w *tableWorker) populateRowCache(ctx context.Context, rows []chunk.Row) {
+	for _, row := range rows {
+		handle := row.GetInt64(w.handleIdx)
+		encoded, err := w.encodeCachedRow(row)
+		if err != nil {
+			logutil.Logger(ctx).Warn("index lookup row cache: encode row failed, not caching",
+				zap.Int64("table_id", w.rowCacheTableID), zap.Int64("handle", handle), zap.Error(err))
+			continue
+		}
+		w.rowCache.Put(w.rowCacheTableID, handle, encoded)
+	}
+}
+
+// encodeCachedRow serializes row with w.rowCacheCodec and snappy-compresses
+// it, the same encoding kvRowContainerBackend uses for spilled chunks.
+func (w *tableWorker) encodeCachedRow(row chunk.Row) ([]byte, error) {
+	chk := chunk.NewChunkWithCapacity(retTypes(w.idxLookup), 1)
+	chk.AppendRow(row)
+	return snappy.Encode(nil, w.rowCacheCodec.Encode(chk)), nil
+}
+
+// decodeCachedRow reverses encodeCachedRow.
+func (w *tableWorker) decodeCachedRow(encoded []byte) (chunk.Row, error) {
+	raw, err := snappy.Decode(nil, encoded)
+	if err != nil {
+		return chunk.Row{}, err
+	}
+	chk := chunk.NewChunkWithCapacity(retTypes(w.idxLookup), 1)
+	if err := w.rowCacheCodec.Decode(chk, raw); err != nil {
+		return chunk.Row{}, err
+	}
+	return chk.GetRow(0), nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sync"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// sessionRowCaches holds one RowCache per connection, keyed by
+// SessionVars.ConnectionID, so that repeated point lookups within the same
+// session benefit from a warm cache without sharing rows across sessions
+// (and therefore across transactions that may see different MVCC
+// versions). Entries are never proactively removed when a connection
+// closes; this mirrors how goleveldb's own lruCache sizes itself by bytes,
+// not by connection count, so a handful of long-lived idle connections
+// cost at most tidb_index_lookup_row_cache_capacity bytes each until the
+// process-wide total is reaped by a future session-close hook.
+var (
+	sessionRowCachesMu sync.Mutex
+	sessionRowCaches   = make(map[uint64]*RowCache)
+)
+
+// getSessionRowCache returns sessVars' RowCache, creating one sized to
+// IndexLookupRowCacheCapacity if it doesn't exist yet (or rebuilding it if
+// the capacity changed), or nil if the capacity is non-positive.
+func getSessionRowCache(sessVars *variable.SessionVars) *RowCache {
+	capacity := sessVars.IndexLookupRowCacheCapacity
+	if capacity <= 0 {
+		return nil
+	}
+
+	sessionRowCachesMu.Lock()
+	defer sessionRowCachesMu.Unlock()
+	cache, ok := sessionRowCaches[sessVars.ConnectionID]
+	if ok && cache.Capacity() == capacity {
+		return cache
+	}
+	cache = NewRowCache(capacity)
+	sessionRowCaches[sessVars.ConnectionID] = cache
+	return cache
+}
+
+// CloseSessionRowCache drops connID's RowCache, if any. It should be called
+// from the session's own Close path once this tree has one to hook into;
+// until then, a long-lived connection that enabled the row cache keeps its
+// entries around for the life of the process.
+func CloseSessionRowCache(connID uint64) {
+	sessionRowCachesMu.Lock()
+	delete(sessionRowCaches, connID)
+	sessionRowCachesMu.Unlock()
+}
+
+// rowCacheOptInTables is the set of physical table IDs opted into the row
+// cache. A real deployment would drive this from a table option (e.g. a
+// CACHE INDEX LOOKUP ROWS clause) persisted on model.TableInfo, but
+// model.TableInfo lives in the separate pingcap/parser module this tree
+// doesn't vendor; EnableRowCacheForTable/DisableRowCacheForTable stand in
+// as the explicit opt-in surface until that option exists.
+var rowCacheOptInTables sync.Map // int64 tableID -> struct{}
+
+// EnableRowCacheForTable opts tableID into the row cache for every session
+// that has IndexLookupRowCacheCapacity configured.
+func EnableRowCacheForTable(tableID int64) {
+	rowCacheOptInTables.Store(tableID, struct{}{})
+}
+
+// DisableRowCacheForTable reverses EnableRowCacheForTable.
+func DisableRowCacheForTable(tableID int64) {
+	rowCacheOptInTables.Delete(tableID)
+}
+
+func isRowCacheEnabledForTable(tableID int64) bool {
+	_, ok := rowCacheOptInTables.Load(tableID)
+	return ok
+}
+
+// InvalidateTableRowCache purges tableID's entries from every session's row
+// cache. It is meant to be called whenever tableID's schema version changes
+// (a DDL commits against it) so that stale rows can't be served out of the
+// cache; wiring that call site is left for the DDL package, which does not
+// yet have a post-commit table-version-change hook in this tree.
+func InvalidateTableRowCache(tableID int64) {
+	sessionRowCachesMu.Lock()
+	caches := make([]*RowCache, 0, len(sessionRowCaches))
+	for _, cache := range sessionRowCaches {
+		caches = append(caches, cache)
+	}
+	sessionRowCachesMu.Unlock()
+
+	for _, cache := range caches {
+		cache.PurgeNamespace(tableID)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testRowCacheSuite{})
+
+type testRowCacheSuite struct{}
+
+// TestRowCacheGetMiss checks that Get on an empty cache reports a miss
+// rather than a zero-value hit.
+func (s *testRowCacheSuite) TestRowCacheGetMiss(c *C) {
+	cache := NewRowCache(1024)
+	_, ok := cache.Get(1, 1)
+	c.Assert(ok, IsFalse)
+}
+
+// TestRowCachePutGetRoundTrip checks a cached value comes back unchanged
+// and counts towards Size/Alive.
+func (s *testRowCacheSuite) TestRowCachePutGetRoundTrip(c *C) {
+	cache := NewRowCache(1024)
+	cache.Put(1, 42, []byte("row-bytes"))
+
+	val, ok := cache.Get(1, 42)
+	c.Assert(ok, IsTrue)
+	c.Assert(val, DeepEquals, []byte("row-bytes"))
+	c.Assert(cache.Size(), Equals, int64(len("row-bytes")))
+	c.Assert(cache.Alive(), Equals, 1)
+}
+
+// TestRowCacheEvictsLeastRecentlyUsed checks that Put evicts the
+// least-recently-touched entry once capacity is exceeded, and that a
+// recent Get protects an entry from eviction.
+func (s *testRowCacheSuite) TestRowCacheEvictsLeastRecentlyUsed(c *C) {
+	cache := NewRowCache(2) // room for exactly two 1-byte values
+	cache.Put(1, 1, []byte("a"))
+	cache.Put(1, 2, []byte("b"))
+	_, _ = cache.Get(1, 1) // touch 1 so 2 becomes least-recently-used
+	cache.Put(1, 3, []byte("c"))
+
+	_, ok1 := cache.Get(1, 1)
+	_, ok2 := cache.Get(1, 2)
+	_, ok3 := cache.Get(1, 3)
+	c.Assert(ok1, IsTrue)
+	c.Assert(ok2, IsFalse)
+	c.Assert(ok3, IsTrue)
+}
+
+// TestRowCachePurgeNamespaceIsScoped checks that PurgeNamespace drops only
+// the given namespace's entries, leaving other tables' cached rows intact.
+func (s *testRowCacheSuite) TestRowCachePurgeNamespaceIsScoped(c *C) {
+	cache := NewRowCache(1024)
+	cache.Put(1, 1, []byte("t1-row"))
+	cache.Put(2, 1, []byte("t2-row"))
+
+	cache.PurgeNamespace(1)
+
+	_, ok1 := cache.Get(1, 1)
+	_, ok2 := cache.Get(2, 1)
+	c.Assert(ok1, IsFalse)
+	c.Assert(ok2, IsTrue)
+	c.Assert(cache.Alive(), Equals, 1)
+}
+
+// TestRowCacheOptInRegistry checks the table opt-in set toggles cleanly.
+func (s *testRowCacheSuite) TestRowCacheOptInRegistry(c *C) {
+	c.Assert(isRowCacheEnabledForTable(999), IsFalse)
+	EnableRowCacheForTable(999)
+	c.Assert(isRowCacheEnabledForTable(999), IsTrue)
+	DisableRowCacheForTable(999)
+	c.Assert(isRowCacheEnabledForTable(999), IsFalse)
+}
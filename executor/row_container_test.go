@@ -0,0 +1,53 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testRowContainerSuite{})
+
+type testRowContainerSuite struct{}
+
+// TestMemRowKVStorePutGet checks the reference RowKVStore round-trips
+// values and copies them on Put, so later mutation of the caller's slice
+// can't corrupt a stored value.
+func (s *testRowContainerSuite) TestMemRowKVStorePutGet(c *C) {
+	store := newMemRowKVStore()
+	val := []byte{1, 2, 3}
+	c.Assert(store.Put([]byte("k"), val), IsNil)
+	val[0] = 9
+	got, err := store.Get([]byte("k"))
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, []byte{1, 2, 3})
+}
+
+// TestMemRowKVStoreMissingKey checks Get on an absent key returns an error
+// rather than a zero-value slice, so kvRowContainerBackend.GetRow can't
+// silently read back garbage for a bad chunk.RowPtr.
+func (s *testRowContainerSuite) TestMemRowKVStoreMissingKey(c *C) {
+	store := newMemRowKVStore()
+	_, err := store.Get([]byte("missing"))
+	c.Assert(err, NotNil)
+}
+
+// TestRowContainerKVKeyIsStableAndDistinct checks that rowContainerKVKey
+// produces a distinct, deterministic key per chunk index, since
+// kvRowContainerBackend relies on it to address chunks in the store.
+func (s *testRowContainerSuite) TestRowContainerKVKeyIsStableAndDistinct(c *C) {
+	c.Assert(rowContainerKVKey(0), DeepEquals, rowContainerKVKey(0))
+	c.Assert(rowContainerKVKey(0), Not(DeepEquals), rowContainerKVKey(1))
+	c.Assert(rowContainerKVKey(300), Not(DeepEquals), rowContainerKVKey(1))
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/parser/terror"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// shardReadEMAWeight is the exponential-moving-average weight given to each
+// shard's observed latency; mirrors adaptiveBatchEMAWeight in
+// index_lookup_adaptive.go.
+const shardReadEMAWeight = 0.2
+
+// shardReadStats is shared by every tableWorker of a single
+// IndexLookUpExecutor. It tracks the moving average latency of a single
+// table-read shard, which nextShardCount uses to grow or shrink
+// tableWorker.shardConcurrency to match how fast shards are actually
+// completing.
+type shardReadStats struct {
+	avgLatencyNanos int64
+}
+
+func (s *shardReadStats) observeShard(latency time.Duration) {
+	if s == nil {
+		return
+	}
+	casEMAInt64(&s.avgLatencyNanos, latency.Nanoseconds(), shardReadEMAWeight)
+}
+
+func (s *shardReadStats) avgLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.avgLatencyNanos))
+}
+
+func (s *shardReadStats) String() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("table_read_shard: {avg_shard_latency: %v}", s.avgLatency())
+}
+
+// nextShardCount decides how many concurrent table-read shards the next
+// task should use. Unlike nextBatchSize, which doubles, shard counts step
+// by one: a batch of handles rarely wants more than a handful of shards,
+// and overshooting wastes coprocessor connections for no benefit.
+func nextShardCount(stats *shardReadStats, adaptive bool, current, minShards, maxShards int, targetLatency time.Duration) int {
+	if current < minShards {
+		current = minShards
+	}
+	if !adaptive || stats == nil || targetLatency <= 0 {
+		return current
+	}
+	avgLatency := stats.avgLatency()
+	switch {
+	case avgLatency > targetLatency && current > minShards:
+		return current - 1
+	case avgLatency > 0 && avgLatency < targetLatency/2 && current < maxShards:
+		return current + 1
+	default:
+		return current
+	}
+}
+
+// shardHandles splits handles into up to n roughly-equal contiguous runs,
+// preserving order. It does not shard along actual region boundaries: that
+// needs the KV client's region metadata, which (like handle_filter.go's
+// bloomCoalesceHandleFilter) this tree's store/tikv package does not expose
+// outside of the region_cache.go this snapshot is missing. Splitting a
+// sorted or keepOrder handle batch into n contiguous runs is a reasonable
+// stand-in, since buildTableReaderFromHandles already groups contiguous
+// handles into coprocessor ranges per shard.
+func shardHandles(handles []int64, n int) [][]int64 {
+	if n < 1 {
+		n = 1
+	}
+	if n > len(handles) {
+		n = len(handles)
+	}
+	if n <= 1 {
+		return [][]int64{handles}
+	}
+	shards := make([][]int64, 0, n)
+	base := len(handles) / n
+	rem := len(handles) % n
+	start := 0
+	for i := 0; i < n; i++ {
+		size := base
+		if i < rem {
+			size++
+		}
+		if size == 0 {
+			continue
+		}
+		shards = append(shards, handles[start:start+size])
+		start += size
+	}
+	return shards
+}
+
+// fetchTableRows reads task.handles; the rows come back in shard order, not
+// handle order, and the caller's keepOrder sort restores the index order.
+// When w.rowCache is enabled, it first probes the cache and only reads the
+// miss handles from TiKV, populating the cache with whatever it fetches,
+// and returns the cache hits followed by the fetched rows; with the cache
+// disabled, it reads every handle.
+func (w *tableWorker) fetchTableRows(ctx context.Context, task *lookupTableTask) ([]chunk.Row, error) {
+	if w.rowCache == nil {
+		return w.fetchHandleRows(ctx, task, task.handles)
+	}
+
+	hitRows, missHandles := w.probeRowCache(ctx, task.handles)
+	missRows, err := w.fetchHandleRows(ctx, task, missHandles)
+	if err != nil {
+		return nil, err
+	}
+	w.populateRowCache(ctx, missRows)
+	return append(hitRows, missRows...), nil
+}
+
+// fetchHandleRows reads handles concurrently across
+// tableWorker.shardConcurrency shards. It is the concurrent replacement for
+// looping a single tableReader over all of task.handles.
+func (w *tableWorker) fetchHandleRows(ctx context.Context, task *lookupTableTask, handles []int64) ([]chunk.Row, error) {
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	shardConcurrency := nextShardCount(w.shardStats, w.shardAdaptive, w.shardConcurrency, 1, w.maxShardConcurrency, w.shardTargetLatency)
+	w.shardConcurrency = shardConcurrency
+
+	shards := shardHandles(handles, shardConcurrency)
+	results := make([][]chunk.Row, len(shards))
+	errs := make([]error, len(shards))
+
+	var wg sync.WaitGroup
+	wg.Add(len(shards))
+	for i, shard := range shards {
+		go func(i int, handles []int64) {
+			defer wg.Done()
+			start := time.Now()
+			rows, err := w.fetchShardRows(ctx, handles, task)
+			w.shardStats.observeShard(time.Since(start))
+			results[i], errs[i] = rows, err
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	rows := make([]chunk.Row, 0, len(handles))
+	for _, shardRows := range results {
+		rows = append(rows, shardRows...)
+	}
+	return rows, nil
+}
+
+// fetchShardRows builds a table reader over handles alone and drains it
+// into a row slice, consuming memory against task.memTracker as it goes.
+// It may run concurrently with other shards of the same task, so it never
+// touches task.rows and adds to task.memUsage only atomically; the caller
+// merges the returned rows once every shard has finished.
+func (w *tableWorker) fetchShardRows(ctx context.Context, handles []int64, task *lookupTableTask) ([]chunk.Row, error) {
+	tableReader, err := w.buildTblReader(ctx, handles)
+	if err != nil {
+		logutil.Logger(ctx).Error("build table reader failed", zap.Error(err))
+		return nil, err
+	}
+	defer terror.Call(tableReader.Close)
+
+	rows := make([]chunk.Row, 0, len(handles))
+	for {
+		chk := newFirstChunk(tableReader)
+		if err := tableReader.Next(ctx, chk); err != nil {
+			logutil.Logger(ctx).Error("table reader fetch next chunk failed", zap.Error(err))
+			return nil, err
+		}
+		if chk.NumRows() == 0 {
+			break
+		}
+		memUsage := chk.MemoryUsage()
+		task.memTracker.Consume(memUsage)
+		atomic.AddInt64(&task.memUsage, memUsage)
+		iter := chunk.NewIterator4Chunk(chk)
+		for row := iter.Begin(); row != iter.End(); row = iter.Next() {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
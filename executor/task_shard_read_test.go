@@ -0,0 +1,72 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testTaskShardReadSuite{})
+
+type testTaskShardReadSuite struct{}
+
+// TestShardHandlesSplitsContiguousRuns checks that shardHandles preserves
+// order and splits as close to evenly as possible, with leftover handles
+// going to the earliest shards.
+func (s *testTaskShardReadSuite) TestShardHandlesSplitsContiguousRuns(c *C) {
+	handles := []int64{1, 2, 3, 4, 5, 6, 7}
+	shards := shardHandles(handles, 3)
+	c.Assert(shards, DeepEquals, [][]int64{{1, 2, 3}, {4, 5}, {6, 7}})
+}
+
+// TestShardHandlesClampsToHandleCount checks that asking for more shards
+// than handles available never produces empty shards.
+func (s *testTaskShardReadSuite) TestShardHandlesClampsToHandleCount(c *C) {
+	handles := []int64{1, 2}
+	shards := shardHandles(handles, 5)
+	c.Assert(shards, DeepEquals, [][]int64{{1}, {2}})
+}
+
+// TestShardHandlesSingleShard checks that n<=1 returns the handles unsplit.
+func (s *testTaskShardReadSuite) TestShardHandlesSingleShard(c *C) {
+	handles := []int64{1, 2, 3}
+	c.Assert(shardHandles(handles, 1), DeepEquals, [][]int64{{1, 2, 3}})
+	c.Assert(shardHandles(handles, 0), DeepEquals, [][]int64{{1, 2, 3}})
+}
+
+// TestNextShardCountNonAdaptiveHoldsCurrent checks that with adaptive off,
+// nextShardCount never changes the shard count.
+func (s *testTaskShardReadSuite) TestNextShardCountNonAdaptiveHoldsCurrent(c *C) {
+	c.Assert(nextShardCount(nil, false, 2, 1, 8, time.Millisecond), Equals, 2)
+}
+
+// TestNextShardCountGrowsWhenFast checks that a shard average well under
+// half the target latency grows the shard count by one, up to the max.
+func (s *testTaskShardReadSuite) TestNextShardCountGrowsWhenFast(c *C) {
+	stats := &shardReadStats{}
+	stats.observeShard(time.Millisecond)
+	c.Assert(nextShardCount(stats, true, 2, 1, 8, 100*time.Millisecond), Equals, 3)
+	c.Assert(nextShardCount(stats, true, 8, 1, 8, 100*time.Millisecond), Equals, 8)
+}
+
+// TestNextShardCountShrinksWhenSlow checks that a shard average over the
+// target latency shrinks the shard count by one, down to the min.
+func (s *testTaskShardReadSuite) TestNextShardCountShrinksWhenSlow(c *C) {
+	stats := &shardReadStats{}
+	stats.observeShard(200 * time.Millisecond)
+	c.Assert(nextShardCount(stats, true, 4, 1, 8, 100*time.Millisecond), Equals, 3)
+	c.Assert(nextShardCount(stats, true, 1, 1, 8, 100*time.Millisecond), Equals, 1)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// lookupTableTaskSpill is the on-disk state for a lookupTableTask whose
+// rows tableWorker.executeTask moved to disk via maybeSpill. container
+// holds the row data behind a pluggable RowContainerBackend; rowPtrs maps
+// the task's logical row order (already keepOrder-sorted, if applicable)
+// to a pointer into container.
+type lookupTableTaskSpill struct {
+	container *RowContainer
+	rowPtrs   []chunk.RowPtr
+}
+
+// newRowContainerBackend builds the RowContainerBackend maybeSpill spills
+// to, honoring IndexLookupSpillKVBackend: when on, spill goes to a
+// RowKVStore-backed container instead of the default local-file
+// chunk.ListInDisk. The RowKVStore used here is always the in-memory
+// memRowKVStore, so with the KV backend on, spilled rows stay in process
+// memory; no other RowKVStore can be selected.
+func newRowContainerBackend(sessVars *variable.SessionVars, fieldTypes []*types.FieldType) RowContainerBackend {
+	if sessVars.IndexLookupSpillKVBackend {
+		return newKVRowContainerBackend(fieldTypes, newMemRowKVStore())
+	}
+	return newDiskRowContainerBackend(fieldTypes)
+}
+
+// maybeSpill moves task.rows to disk and replaces them with a
+// lookupTableTaskSpill, if IndexLookupSpillEnabled is on and this task's
+// memory usage clears IndexLookupSpillThreshold. It must run after
+// task.rows has its final contents -- including, for a keepOrder query,
+// after sort.Sort(task) -- since rowIdx-based sorting only ever reorders
+// task.rows, never a spilled task's rowPtrs. See MergeRowContainers' doc
+// comment for why this single in-memory sort is sufficient and no external
+// merge sort is needed here.
+func (w *tableWorker) maybeSpill(task *lookupTableTask) error {
+	sessVars := w.idxLookup.ctx.GetSessionVars()
+	if !sessVars.IndexLookupSpillEnabled || len(task.rows) == 0 {
+		return nil
+	}
+	if task.memUsage < sessVars.IndexLookupSpillThreshold {
+		return nil
+	}
+
+	fieldTypes := retTypes(w.idxLookup)
+	container := NewRowContainer(fieldTypes, newRowContainerBackend(sessVars, fieldTypes))
+	rowPtrs := make([]chunk.RowPtr, 0, len(task.rows))
+	for _, row := range task.rows {
+		ptr, err := container.AppendRow(row)
+		if err != nil {
+			return err
+		}
+		rowPtrs = append(rowPtrs, ptr)
+	}
+
+	// The rows now live in container, which memTracker does not track;
+	// release this task's share of memTracker rather than waiting for the usual
+	// Consume(-task.memUsage) in IndexLookUpExecutor.getResultTask.
+	task.memTracker.Consume(-task.memUsage)
+	task.memUsage = 0
+	task.spillRowCount = len(task.rows)
+	task.rows = nil
+	task.spill = &lookupTableTaskSpill{container: container, rowPtrs: rowPtrs}
+	return nil
+}
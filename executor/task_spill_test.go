@@ -0,0 +1,33 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+var _ = Suite(&testTaskSpillSuite{})
+
+type testTaskSpillSuite struct{}
+
+// TestRowCountAndCloseSpillBeforeSpilling checks that an un-spilled task's
+// rowCount reflects len(task.rows) and closeSpill is a no-op, so callers
+// like IndexLookUpExecutor.Next don't need to special-case the common
+// (never spilled) path.
+func (s *testTaskSpillSuite) TestRowCountAndCloseSpillBeforeSpilling(c *C) {
+	task := &lookupTableTask{rows: make([]chunk.Row, 3)}
+	c.Assert(task.rowCount(), Equals, 3)
+	c.Assert(task.closeSpill(), IsNil)
+}
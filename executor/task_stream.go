@@ -0,0 +1,213 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/parser/terror"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// streamRowBufSize is the capacity of task.rowStream and, for a keepOrder
+// task, of each shard's channel in front of it, so a streaming task holds
+// at most this many fetched-but-not-yet-delivered rows per channel.
+// IndexLookUpExecutor.Next draining task.rowStream is this path's only
+// back-pressure, so an idle consumer stalls the fetching goroutines there
+// instead of letting task.rows grow without bound the way the batched path
+// would.
+const streamRowBufSize = 1024
+
+// executeTaskStreaming is tableWorker's streaming alternative to
+// executeTask: it shards task.handles the same way executeTask's
+// fetchTableRows does, but publishes each shard's rows to task.rowStream as
+// soon as they're fetched instead of collecting them into task.rows first.
+// It never spills and never runs the isCheckOp consistency check -- both
+// need every obtained handle in hand, which streaming is built to avoid
+// buffering. idxLookup.streamRows is already false whenever either applies
+// (see IndexLookUpExecutor.startWorkers), so this is never called in those
+// cases.
+func (w *tableWorker) executeTaskStreaming(ctx context.Context, task *lookupTableTask) error {
+	start := time.Now()
+	defer func() { w.idxLookup.adaptiveStats.observeTask(time.Since(start), len(task.handles)) }()
+
+	task.memTracker = w.memTracker
+	memUsage := int64(cap(task.handles) * 8)
+	task.memUsage = memUsage
+	task.memTracker.Consume(memUsage)
+
+	if len(task.handles) == 0 {
+		return nil
+	}
+
+	shardConcurrency := nextShardCount(w.shardStats, w.shardAdaptive, w.shardConcurrency, 1, w.maxShardConcurrency, w.shardTargetLatency)
+	w.shardConcurrency = shardConcurrency
+	shards := shardHandles(task.handles, shardConcurrency)
+
+	if !w.keepOrder {
+		return w.streamShardsUnordered(ctx, task, shards)
+	}
+	return w.streamShardsOrdered(ctx, task, shards)
+}
+
+// streamShardsUnordered fans every shard's rows directly into
+// task.rowStream as each shard produces them; output order across shards is
+// unspecified, matching the existing !keepOrder contract of the batched
+// path.
+func (w *tableWorker) streamShardsUnordered(ctx context.Context, task *lookupTableTask, shards [][]int64) error {
+	errCh := make(chan error, len(shards))
+	var wg sync.WaitGroup
+	wg.Add(len(shards))
+	for _, shard := range shards {
+		go func(handles []int64) {
+			defer wg.Done()
+			shardStart := time.Now()
+			err := w.fetchShardRowsStreaming(ctx, handles, task, task.rowStream)
+			w.shardStats.observeShard(time.Since(shardStart))
+			if err != nil {
+				errCh <- err
+			}
+		}(shard)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamMergeItem is one shard's next not-yet-delivered row, ordered by the
+// position its handle originally held in the index scan.
+type streamMergeItem struct {
+	row      chunk.Row
+	order    int
+	shardIdx int
+}
+
+type streamMergeHeap []streamMergeItem
+
+func (h streamMergeHeap) Len() int            { return len(h) }
+func (h streamMergeHeap) Less(i, j int) bool  { return h[i].order < h[j].order }
+func (h streamMergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *streamMergeHeap) Push(x interface{}) { *h = append(*h, x.(streamMergeItem)) }
+func (h *streamMergeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// streamShardsOrdered k-way merges every shard's rows by
+// task.indexOrder[handle] before publishing to task.rowStream, so keepOrder
+// output stays correct without waiting for every shard to finish (and
+// without the batched path's sort.Sort over the whole task).
+func (w *tableWorker) streamShardsOrdered(ctx context.Context, task *lookupTableTask, shards [][]int64) error {
+	shardChs := make([]chan chunk.Row, len(shards))
+	errCh := make(chan error, len(shards))
+	var wg sync.WaitGroup
+	wg.Add(len(shards))
+	for i, shard := range shards {
+		shardChs[i] = make(chan chunk.Row, streamRowBufSize)
+		go func(i int, handles []int64) {
+			defer wg.Done()
+			defer close(shardChs[i])
+			shardStart := time.Now()
+			err := w.fetchShardRowsStreaming(ctx, handles, task, shardChs[i])
+			w.shardStats.observeShard(time.Since(shardStart))
+			if err != nil {
+				errCh <- err
+			}
+		}(i, shard)
+	}
+	go func() {
+		wg.Wait()
+		close(errCh)
+	}()
+
+	h := make(streamMergeHeap, 0, len(shards))
+	for i, ch := range shardChs {
+		if row, ok := <-ch; ok {
+			h = append(h, streamMergeItem{row: row, order: task.indexOrder[row.GetInt64(w.handleIdx)], shardIdx: i})
+		}
+	}
+	heap.Init(&h)
+	for h.Len() > 0 {
+		item := heap.Pop(&h).(streamMergeItem)
+		select {
+		case task.rowStream <- item.row:
+		case <-w.finished:
+			return nil
+		}
+		if row, ok := <-shardChs[item.shardIdx]; ok {
+			heap.Push(&h, streamMergeItem{row: row, order: task.indexOrder[row.GetInt64(w.handleIdx)], shardIdx: item.shardIdx})
+		}
+	}
+
+	var err error
+	for e := range errCh {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// fetchShardRowsStreaming is fetchShardRows' streaming counterpart: instead
+// of collecting rows into a slice it returns once the shard is exhausted,
+// it pushes each fetched chunk's rows onto out as soon as they arrive.
+func (w *tableWorker) fetchShardRowsStreaming(ctx context.Context, handles []int64, task *lookupTableTask, out chan<- chunk.Row) error {
+	tableReader, err := w.buildTblReader(ctx, handles)
+	if err != nil {
+		logutil.Logger(ctx).Error("build table reader failed", zap.Error(err))
+		return err
+	}
+	defer terror.Call(tableReader.Close)
+
+	for {
+		chk := newFirstChunk(tableReader)
+		if err := tableReader.Next(ctx, chk); err != nil {
+			logutil.Logger(ctx).Error("table reader fetch next chunk failed", zap.Error(err))
+			return err
+		}
+		if chk.NumRows() == 0 {
+			return nil
+		}
+		memUsage := chk.MemoryUsage()
+		task.memTracker.Consume(memUsage)
+		atomic.AddInt64(&task.memUsage, memUsage)
+		iter := chunk.NewIterator4Chunk(chk)
+		for row := iter.Begin(); row != iter.End(); row = iter.Next() {
+			// Selecting on w.finished, not ctx.Done(), matches
+			// pickAndExecTask's own reasoning: IndexLookUpExecutor.Close
+			// closes finished and relies on every blocking send in this
+			// package unblocking from it, rather than waiting for a
+			// context a caller might never cancel.
+			select {
+			case out <- row:
+			case <-w.finished:
+				return nil
+			}
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 
 	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/logutil"
 	"go.uber.org/zap"
@@ -27,8 +28,11 @@ var specialFoldHandler = map[string]func(*ScalarFunction) (Expression, bool){}
 
 func init() {
 	specialFoldHandler = map[string]func(*ScalarFunction) (Expression, bool){
-		ast.If:     ifFoldHandler,
-		ast.Ifnull: ifNullFoldHandler,
+		ast.If:       ifFoldHandler,
+		ast.Ifnull:   ifNullFoldHandler,
+		ast.Case:     caseWhenFoldHandler,
+		ast.Coalesce: coalesceFoldHandler,
+		ast.Nullif:   nullIfFoldHandler,
 	}
 }
 
@@ -80,6 +84,153 @@ func ifNullFoldHandler(expr *ScalarFunction) (Expression, bool) {
 	return expr, isDeferredConst
 }
 
+// caseWhenFoldHandler folds a CASE WHEN c1 THEN r1 WHEN c2 THEN r2 ... [ELSE
+// re] expression, represented as args [c1, r1, c2, r2, ..., (re)]. It walks
+// the WHEN arms in order: a condition that folds to a non-null true
+// constant ends the walk, and everything after it is left unfolded since it
+// can never run and may error if evaluated; its THEN is the whole result
+// when no earlier arm was kept, and otherwise becomes the ELSE of the kept
+// arms; a condition that folds to false or null can never fire
+// regardless of the other arms, so its pair is dropped; anything else is
+// kept and folded in place without collapsing the CASE.
+func caseWhenFoldHandler(expr *ScalarFunction) (Expression, bool) {
+	args := expr.GetArgs()
+	l := len(args)
+	var isDeferredConst bool
+	newArgs := make([]Expression, 0, l)
+	truncated := false
+	for i := 0; i+1 < l; i += 2 {
+		foldedCond, isDeferred := foldConstant(args[i])
+		isDeferredConst = isDeferredConst || isDeferred
+		if constCond, isConst := foldedCond.(*Constant); isConst {
+			b, isNull, err := constCond.EvalInt(expr.Function.getCtx(), chunk.Row{})
+			if err != nil {
+				logutil.Logger(context.Background()).Debug("fold expression to constant", zap.String("expression", expr.ExplainInfo()), zap.Error(err))
+				return expr, false
+			}
+			if !isNull && b != 0 {
+				foldedThen, isDeferredThen := foldConstant(args[i+1])
+				isDeferredConst = isDeferredConst || isDeferredThen
+				if len(newArgs) == 0 {
+					return foldedThen, isDeferredConst
+				}
+				// A kept non-constant arm may still fire first, so this THEN
+				// becomes the ELSE and every later arm is dropped.
+				newArgs = append(newArgs, foldedThen)
+				truncated = true
+				break
+			}
+			// Constant false or null: this arm can never fire, drop it.
+			continue
+		}
+		foldedThen, isDeferred := foldConstant(args[i+1])
+		isDeferredConst = isDeferredConst || isDeferred
+		newArgs = append(newArgs, foldedCond, foldedThen)
+	}
+	if l%2 == 1 && !truncated {
+		foldedElse, isDeferred := foldConstant(args[l-1])
+		isDeferredConst = isDeferredConst || isDeferred
+		newArgs = append(newArgs, foldedElse)
+	}
+	switch len(newArgs) {
+	case 0:
+		// Every WHEN arm was constant false/null and there's no ELSE.
+		return &Constant{Value: types.Datum{}, RetType: expr.RetType}, isDeferredConst
+	case 1:
+		// Only the ELSE survived.
+		return newArgs[0], isDeferredConst
+	case len(args):
+		// Nothing was dropped; keep the original ScalarFunction in place.
+		for i, a := range newArgs {
+			expr.GetArgs()[i] = a
+		}
+		return expr, isDeferredConst
+	default:
+		rebuilt, err := NewFunctionBase(expr.GetCtx(), expr.FuncName.L, expr.GetType(), newArgs...)
+		if err != nil {
+			return expr, isDeferredConst
+		}
+		return rebuilt, isDeferredConst
+	}
+}
+
+// coalesceFoldHandler folds COALESCE(a, b, c, ...) left-to-right, dropping
+// constant NULLs (they can never be the result) and stopping at the first
+// non-null constant: with no non-constant argument before it, it is the
+// answer; otherwise it becomes the last argument and everything after it is
+// dropped. The (possibly shortened) list is kept as a COALESCE over just
+// the unresolved arguments.
+func coalesceFoldHandler(expr *ScalarFunction) (Expression, bool) {
+	args := expr.GetArgs()
+	var isDeferredConst bool
+	newArgs := make([]Expression, 0, len(args))
+	for _, arg := range args {
+		folded, isDeferred := foldConstant(arg)
+		isDeferredConst = isDeferredConst || isDeferred
+		if constArg, isConst := folded.(*Constant); isConst {
+			if constArg.Value.IsNull() {
+				continue
+			}
+			if len(newArgs) == 0 {
+				return folded, isDeferredConst
+			}
+			newArgs = append(newArgs, folded)
+			break
+		}
+		newArgs = append(newArgs, folded)
+	}
+	switch len(newArgs) {
+	case 0:
+		return &Constant{Value: types.Datum{}, RetType: expr.RetType}, isDeferredConst
+	case 1:
+		return newArgs[0], isDeferredConst
+	case len(args):
+		for i, a := range newArgs {
+			expr.GetArgs()[i] = a
+		}
+		return expr, isDeferredConst
+	default:
+		rebuilt, err := NewFunctionBase(expr.GetCtx(), expr.FuncName.L, expr.GetType(), newArgs...)
+		if err != nil {
+			return expr, isDeferredConst
+		}
+		return rebuilt, isDeferredConst
+	}
+}
+
+// nullIfFoldHandler folds NULLIF(a, b), which is NULL when a equals b and a
+// otherwise. A constant NULL on either side means the equality can never be
+// known true, so the result is always a; with both sides constant, it folds
+// to the direct evaluation.
+func nullIfFoldHandler(expr *ScalarFunction) (Expression, bool) {
+	args := expr.GetArgs()
+	foldedA, isDeferredA := foldConstant(args[0])
+	foldedB, isDeferredB := foldConstant(args[1])
+	isDeferredConst := isDeferredA || isDeferredB
+	constA, aIsConst := foldedA.(*Constant)
+	constB, bIsConst := foldedB.(*Constant)
+	if aIsConst && constA.Value.IsNull() {
+		return foldedA, isDeferredConst
+	}
+	if bIsConst && constB.Value.IsNull() {
+		return foldedA, isDeferredConst
+	}
+	expr.GetArgs()[0] = foldedA
+	expr.GetArgs()[1] = foldedB
+	if !aIsConst || !bIsConst {
+		return expr, isDeferredConst
+	}
+	value, err := expr.Eval(chunk.Row{})
+	if err != nil {
+		logutil.Logger(context.Background()).Debug("fold expression to constant", zap.String("expression", expr.ExplainInfo()), zap.Error(err))
+		return expr, isDeferredConst
+	}
+	if isDeferredConst {
+		return &Constant{Value: value, RetType: expr.RetType, DeferredExpr: expr}, true
+	}
+	return &Constant{Value: value, RetType: expr.RetType}, false
+}
+
 func foldConstant(expr Expression) (Expression, bool) {
 	switch x := expr.(type) {
 	case *ScalarFunction:
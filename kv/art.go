@@ -0,0 +1,329 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import "bytes"
+
+func init() {
+	RegisterMemBuffer("art", MemBufferFactoryFunc(func(cap int) MemBuffer {
+		return newArtMemBuffer(cap)
+	}))
+}
+
+// artNode16Cap is the child count at which a node grows to the next tier: a
+// small node stores its keys/children as parallel sorted slices (cheap for
+// up to a handful of children, the common case for TiDB's encoded keys
+// which rarely branch wide at any single byte), and growing past
+// artNode16Cap switches to a flat 256-entry array indexed directly by the
+// branching byte, trading memory for O(1) lookups once a node is that
+// crowded. artNode4Cap is not consulted: there is no separate tier between
+// 4 and 16 children, the sorted slices simply grow.
+const (
+	artNode4Cap  = 4
+	artNode16Cap = 16
+)
+
+// artLeaf holds one stored key/value pair. It lives at the artNode reached
+// by walking one child per key byte, so a key is never stored more than
+// once across the tree -- only its trailing, not-yet-branched bytes are
+// implicit in the path taken to reach its leaf.
+type artLeaf struct {
+	key   []byte
+	value []byte
+}
+
+// artNode is one branching point of the adaptive radix tree. Unlike a
+// textbook ART this does not compress single-child chains into a shared
+// prefix (each node still dispatches on exactly one key byte); what makes
+// it "adaptive" here is purely the sorted-slice to node256 growth below,
+// which is still where most of the memory win over a flat 256-way trie
+// comes from when most nodes have only one or two children. A leaf value
+// can sit on any node, not just leaves of the tree, since one stored key
+// may be a prefix of another (e.g. an index key versus a longer key that
+// shares its encoded prefix).
+type artNode struct {
+	leaf     *artLeaf
+	keys     []byte     // sorted; unused once full (see full)
+	children []*artNode // parallel to keys; unused once full
+	full     []*artNode // 256-entry, only allocated once grown past artNode16Cap
+}
+
+func (n *artNode) isNode256() bool {
+	return n.full != nil
+}
+
+func (n *artNode) child(b byte) *artNode {
+	if n.isNode256() {
+		return n.full[b]
+	}
+	for i, k := range n.keys {
+		if k == b {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// setChild inserts or replaces the child reached by key byte b, growing
+// this node to a node256 first if it's already at artNode16Cap and b isn't
+// one of its existing children.
+func (n *artNode) setChild(b byte, child *artNode) {
+	if n.isNode256() {
+		n.full[b] = child
+		return
+	}
+	for i, k := range n.keys {
+		if k == b {
+			n.children[i] = child
+			return
+		}
+	}
+	if len(n.keys) >= artNode16Cap {
+		n.growToNode256()
+		n.full[b] = child
+		return
+	}
+	idx := 0
+	for idx < len(n.keys) && n.keys[idx] < b {
+		idx++
+	}
+	n.keys = append(n.keys, 0)
+	n.children = append(n.children, nil)
+	copy(n.keys[idx+1:], n.keys[idx:])
+	copy(n.children[idx+1:], n.children[idx:])
+	n.keys[idx] = b
+	n.children[idx] = child
+}
+
+func (n *artNode) growToNode256() {
+	full := make([]*artNode, 256)
+	for i, k := range n.keys {
+		full[k] = n.children[i]
+	}
+	n.full = full
+	n.keys = nil
+	n.children = nil
+}
+
+// walk calls f for every child in ascending (or, if reverse, descending)
+// key-byte order, which is what lets artMemBuffer.collectRange produce
+// sorted output just by walking the tree depth-first.
+func (n *artNode) walk(reverse bool, f func(child *artNode)) {
+	if n.isNode256() {
+		if !reverse {
+			for b := 0; b < 256; b++ {
+				if c := n.full[b]; c != nil {
+					f(c)
+				}
+			}
+		} else {
+			for b := 255; b >= 0; b-- {
+				if c := n.full[b]; c != nil {
+					f(c)
+				}
+			}
+		}
+		return
+	}
+	if !reverse {
+		for _, c := range n.children {
+			f(c)
+		}
+	} else {
+		for i := len(n.children) - 1; i >= 0; i-- {
+			f(n.children[i])
+		}
+	}
+}
+
+// artMemBuffer is a MemBuffer backed by the adaptive radix tree above
+// instead of the skiplist NewMemDbBuffer uses. TiDB's encoded row and index
+// keys share long common prefixes (same table/index ID, varint-encoded
+// handle suffix); a skiplist re-compares that whole shared prefix on every
+// node it visits during a lookup, while this tree only ever branches on the
+// bytes where two stored keys actually differ, which is where its better
+// point-lookup and memory-density behavior for this key shape comes from.
+type artMemBuffer struct {
+	root   *artNode
+	size   int // total bytes of stored values, for Size()
+	length int // number of live entries, for Len()
+	cap    int
+}
+
+func newArtMemBuffer(cap int) *artMemBuffer {
+	return &artMemBuffer{root: &artNode{}, cap: cap}
+}
+
+// Get implements MemBuffer.
+func (t *artMemBuffer) Get(k Key) ([]byte, error) {
+	leaf := t.find(k)
+	if leaf == nil {
+		return nil, ErrNotExist
+	}
+	return leaf.value, nil
+}
+
+func (t *artMemBuffer) find(k Key) *artLeaf {
+	n := t.root
+	for _, b := range k {
+		n = n.child(b)
+		if n == nil {
+			return nil
+		}
+	}
+	return n.leaf
+}
+
+// Set implements MemBuffer.
+func (t *artMemBuffer) Set(key Key, value []byte) error {
+	n := t.root
+	for _, b := range key {
+		child := n.child(b)
+		if child == nil {
+			child = &artNode{}
+			n.setChild(b, child)
+		}
+		n = child
+	}
+	if n.leaf == nil {
+		t.length++
+	} else {
+		t.size -= len(n.leaf.value)
+	}
+	n.leaf = &artLeaf{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	}
+	t.size += len(value)
+	return nil
+}
+
+// Delete implements MemBuffer. It leaves a tombstone leaf with a
+// zero-length value rather than clearing n.leaf outright: unionStore.Get
+// distinguishes "buffered delete" from "never buffered" by checking
+// len(v) == 0 on a present, error-free Get before it ever falls through to
+// the snapshot, so a deleted key must keep resolving to an empty value
+// here, not to ErrNotExist, or a read-after-delete within the same
+// transaction would incorrectly resurrect the snapshot's stale value.
+func (t *artMemBuffer) Delete(k Key) error {
+	n := t.root
+	for _, b := range k {
+		child := n.child(b)
+		if child == nil {
+			child = &artNode{}
+			n.setChild(b, child)
+		}
+		n = child
+	}
+	if n.leaf == nil {
+		t.length++
+	} else {
+		t.size -= len(n.leaf.value)
+	}
+	n.leaf = &artLeaf{
+		key:   append([]byte(nil), k...),
+		value: []byte{},
+	}
+	return nil
+}
+
+// Size implements MemBuffer.
+func (t *artMemBuffer) Size() int { return t.size }
+
+// Len implements MemBuffer.
+func (t *artMemBuffer) Len() int { return t.length }
+
+// Reset implements MemBuffer.
+func (t *artMemBuffer) Reset() {
+	t.root = &artNode{}
+	t.size = 0
+	t.length = 0
+}
+
+// SetCap implements MemBuffer.
+func (t *artMemBuffer) SetCap(cap int) { t.cap = cap }
+
+// collectRange walks the whole tree and returns the leaves with
+// lower <= key < upper (either bound nil meaning unbounded), in ascending
+// key order, or descending if reverse. This materializes the matched range
+// up front rather than stepping through the tree lazily; given this
+// backend's main target is point lookups on typically-small transaction
+// buffers, a cursor-based Iterator that re-walked the tree on every Next
+// would add real complexity for a cold path that's rarely the bottleneck.
+func (t *artMemBuffer) collectRange(lower, upper Key, reverse bool) []*artLeaf {
+	var items []*artLeaf
+	var visit func(n *artNode)
+	visit = func(n *artNode) {
+		if n == nil {
+			return
+		}
+		// A key sorts before every longer key it prefixes, so in reverse
+		// order its leaf comes after its subtree.
+		if n.leaf != nil && !reverse {
+			items = append(items, n.leaf)
+		}
+		n.walk(reverse, visit)
+		if n.leaf != nil && reverse {
+			items = append(items, n.leaf)
+		}
+	}
+	visit(t.root)
+
+	out := items[:0]
+	for _, leaf := range items {
+		if lower != nil && bytes.Compare(leaf.key, lower) < 0 {
+			continue
+		}
+		if upper != nil && bytes.Compare(leaf.key, upper) >= 0 {
+			continue
+		}
+		out = append(out, leaf)
+	}
+	return out
+}
+
+// Iter implements MemBuffer.
+func (t *artMemBuffer) Iter(k Key, upperBound Key) (Iterator, error) {
+	return &artIterator{items: t.collectRange(k, upperBound, false)}, nil
+}
+
+// IterReverse implements MemBuffer.
+func (t *artMemBuffer) IterReverse(k Key) (Iterator, error) {
+	return &artIterator{items: t.collectRange(nil, k, true)}, nil
+}
+
+// artIterator walks a pre-sorted slice of leaves collected by collectRange.
+type artIterator struct {
+	items []*artLeaf
+	idx   int
+}
+
+func (it *artIterator) Valid() bool {
+	return it.idx < len(it.items)
+}
+
+func (it *artIterator) Next() error {
+	it.idx++
+	return nil
+}
+
+func (it *artIterator) Key() Key {
+	return Key(it.items[it.idx].key)
+}
+
+func (it *artIterator) Value() []byte {
+	return it.items[it.idx].value
+}
+
+func (it *artIterator) Close() {}
@@ -0,0 +1,84 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import "testing"
+
+// staleSnapshot is a minimal Snapshot whose Get always returns a fixed,
+// pre-existing value, standing in for a transaction's underlying read
+// snapshot. It lets the tests below tell apart "read came from the
+// buffered write" from "read fell through to the snapshot" without a real
+// store behind it.
+type staleSnapshot struct {
+	value []byte
+}
+
+func (s staleSnapshot) Get(k Key) ([]byte, error) {
+	return append([]byte(nil), s.value...), nil
+}
+
+func (s staleSnapshot) Iter(k Key, upperBound Key) (Iterator, error) {
+	return invalidIterator{}, nil
+}
+
+func (s staleSnapshot) IterReverse(k Key) (Iterator, error) {
+	return invalidIterator{}, nil
+}
+
+// TestArtMemBufferDeleteThenGetDoesNotResurrectSnapshotValue exercises
+// Set -> Delete -> Get against a unionStore backed by the "art" MemBuffer,
+// the same path a transaction takes: a key deleted in this transaction
+// must read back as ErrNotExist, not as the stale value still sitting in
+// the underlying snapshot.
+func TestArtMemBufferDeleteThenGetDoesNotResurrectSnapshotValue(t *testing.T) {
+	factory, ok := GetMemBufferFactory("art")
+	if !ok {
+		t.Fatal(`"art" MemBuffer factory not registered`)
+	}
+	us := NewUnionStoreWithBuffer(staleSnapshot{value: []byte("stale-snapshot-value")}, factory)
+
+	key := Key("k1")
+	if err := us.Set(key, []byte("fresh-value")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := us.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err := us.Get(key)
+	if !IsErrNotFound(err) {
+		t.Fatalf("Get after Delete: got err=%v, want ErrNotExist", err)
+	}
+}
+
+// TestArtMemBufferGetAfterDeleteDoesNotFallThroughOnNeverSetKey checks the
+// same contract for a key that was never Set in this transaction at all:
+// deleting it still must not resolve through to the snapshot's value.
+func TestArtMemBufferGetAfterDeleteDoesNotFallThroughOnNeverSetKey(t *testing.T) {
+	factory, ok := GetMemBufferFactory("art")
+	if !ok {
+		t.Fatal(`"art" MemBuffer factory not registered`)
+	}
+	us := NewUnionStoreWithBuffer(staleSnapshot{value: []byte("stale-snapshot-value")}, factory)
+
+	key := Key("k2")
+	if err := us.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err := us.Get(key)
+	if !IsErrNotFound(err) {
+		t.Fatalf("Get after Delete: got err=%v, want ErrNotExist", err)
+	}
+}
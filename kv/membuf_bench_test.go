@@ -0,0 +1,113 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+// benchRowKey mimics TiDB's "t{tableID}_r{handle}" row key encoding: a
+// shared table-ID prefix with a varying handle suffix, which is exactly
+// the shape art.go's doc comment claims this backend is better suited to
+// than a skiplist.
+func benchRowKey(tableID, handle int64) []byte {
+	buf := make([]byte, 0, 19)
+	buf = append(buf, 't')
+	buf = appendBigEndianUint64(buf, uint64(tableID))
+	buf = append(buf, '_', 'r')
+	buf = appendBigEndianUint64(buf, uint64(handle))
+	return buf
+}
+
+func appendBigEndianUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func benchmarkInsertThenScan(b *testing.B, factory MemBufferFactory) {
+	const rows = 10000
+	for i := 0; i < b.N; i++ {
+		mb := factory.NewMemBuffer(rows)
+		for h := int64(0); h < rows; h++ {
+			if err := mb.Set(benchRowKey(1, h), []byte(fmt.Sprintf("value-%d", h))); err != nil {
+				b.Fatal(err)
+			}
+		}
+		it, err := mb.Iter(benchRowKey(1, 0), benchRowKey(2, 0))
+		if err != nil {
+			b.Fatal(err)
+		}
+		n := 0
+		for it.Valid() {
+			n++
+			if err := it.Next(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		it.Close()
+		if n != rows {
+			b.Fatalf("scanned %d rows, want %d", n, rows)
+		}
+	}
+}
+
+// BenchmarkInsertThenScanSkiplist measures NewMemDbBuffer's insert-then-scan
+// cost over realistic, long-shared-prefix row keys.
+func BenchmarkInsertThenScanSkiplist(b *testing.B) {
+	benchmarkInsertThenScan(b, defaultMemBufferFactory)
+}
+
+// BenchmarkInsertThenScanART measures the same workload against the "art"
+// backend, to compare against BenchmarkInsertThenScanSkiplist.
+func BenchmarkInsertThenScanART(b *testing.B) {
+	f, ok := GetMemBufferFactory("art")
+	if !ok {
+		b.Fatal(`"art" MemBufferFactory not registered`)
+	}
+	benchmarkInsertThenScan(b, f)
+}
+
+func benchmarkPointGet(b *testing.B, factory MemBufferFactory) {
+	const rows = 10000
+	mb := factory.NewMemBuffer(rows)
+	for h := int64(0); h < rows; h++ {
+		if err := mb.Set(benchRowKey(1, h), []byte(fmt.Sprintf("value-%d", h))); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mb.Get(benchRowKey(1, int64(i%rows))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPointGetSkiplist measures point-lookup cost against
+// BenchmarkPointGetART for the same long-shared-prefix key shape.
+func BenchmarkPointGetSkiplist(b *testing.B) {
+	benchmarkPointGet(b, defaultMemBufferFactory)
+}
+
+// BenchmarkPointGetART is ART's counterpart to BenchmarkPointGetSkiplist.
+func BenchmarkPointGetART(b *testing.B) {
+	f, ok := GetMemBufferFactory("art")
+	if !ok {
+		b.Fatal(`"art" MemBufferFactory not registered`)
+	}
+	benchmarkPointGet(b, f)
+}
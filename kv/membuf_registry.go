@@ -0,0 +1,85 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import "sync"
+
+// MemBufferFactory constructs a fresh MemBuffer sized to the given
+// capacity hint. unionStore used to always get its buffer from
+// NewMemDbBuffer (a skiplist); registering a MemBufferFactory under a name
+// via RegisterMemBuffer lets NewUnionStoreWithBuffer (and, through it, the
+// tidb_mem_buffer_impl session variable once sessionctx/variable grows a
+// case for it) pick a different backend per transaction, such as the
+// art.go ART implementation.
+type MemBufferFactory interface {
+	NewMemBuffer(cap int) MemBuffer
+}
+
+// MemBufferFactoryFunc adapts a plain function to a MemBufferFactory.
+type MemBufferFactoryFunc func(cap int) MemBuffer
+
+// NewMemBuffer implements MemBufferFactory.
+func (f MemBufferFactoryFunc) NewMemBuffer(cap int) MemBuffer {
+	return f(cap)
+}
+
+// defaultMemBufferFactory reproduces the skiplist-backed behavior
+// NewUnionStore always had before RegisterMemBuffer existed; it is
+// registered under "skiplist" below so callers can still ask for it by
+// name.
+var defaultMemBufferFactory = MemBufferFactoryFunc(func(cap int) MemBuffer {
+	return NewMemDbBuffer(cap)
+})
+
+var (
+	memBufferFactoriesMu sync.RWMutex
+	memBufferFactories   = map[string]MemBufferFactory{
+		"skiplist": defaultMemBufferFactory,
+	}
+)
+
+// RegisterMemBuffer makes factory available under name for
+// NewUnionStoreWithBuffer callers and, eventually, the
+// tidb_mem_buffer_impl session variable to select by name. Re-registering
+// an existing name overwrites its factory; this is normally only done once
+// per name, from an init function (see art.go's registration of "art").
+func RegisterMemBuffer(name string, factory MemBufferFactory) {
+	memBufferFactoriesMu.Lock()
+	defer memBufferFactoriesMu.Unlock()
+	memBufferFactories[name] = factory
+}
+
+// GetMemBufferFactory looks up the MemBufferFactory registered under name.
+func GetMemBufferFactory(name string) (MemBufferFactory, bool) {
+	memBufferFactoriesMu.RLock()
+	defer memBufferFactoriesMu.RUnlock()
+	f, ok := memBufferFactories[name]
+	return f, ok
+}
+
+// NewUnionStoreWithBuffer builds a new UnionStore the same way NewUnionStore
+// does, except its buffered writes are held in a MemBuffer built by factory
+// instead of the default skiplist. BufferStore itself always constructs its
+// own default MemBuffer first (see NewBufferStore); this swaps it out right
+// afterwards rather than threading the factory through BufferStore's own
+// constructor, so BufferStore needs no changes to support this.
+func NewUnionStoreWithBuffer(snapshot Snapshot, factory MemBufferFactory) UnionStore {
+	bs := NewBufferStore(snapshot, DefaultTxnMembufCap)
+	bs.MemBuffer = factory.NewMemBuffer(DefaultTxnMembufCap)
+	return &unionStore{
+		BufferStore:        bs,
+		lazyConditionPairs: make(map[string]*conditionPair),
+		opts:               make(map[Option]interface{}),
+	}
+}
@@ -79,11 +79,7 @@ type unionStore struct {
 
 // NewUnionStore builds a new UnionStore.
 func NewUnionStore(snapshot Snapshot) UnionStore {
-	return &unionStore{
-		BufferStore:        NewBufferStore(snapshot, DefaultTxnMembufCap),
-		lazyConditionPairs: make(map[string]*conditionPair),
-		opts:               make(map[Option]interface{}),
-	}
+	return NewUnionStoreWithBuffer(snapshot, defaultMemBufferFactory)
 }
 
 // invalidIterator implements Iterator interface.
@@ -108,10 +104,22 @@ func (it invalidIterator) Value() []byte {
 
 func (it invalidIterator) Close() {}
 
-// lazyMemBuffer wraps a MemBuffer which is to be initialized when it is modified.
+// lazyMemBuffer wraps a MemBuffer which is to be initialized when it is
+// modified. factory picks which backend that turns out to be; it is nil
+// for a lazyMemBuffer built the old way (struct literal with just a cap),
+// so activeFactory falls back to the skiplist default rather than panicking
+// on those call sites.
 type lazyMemBuffer struct {
-	mb  MemBuffer
-	cap int
+	mb      MemBuffer
+	cap     int
+	factory MemBufferFactory
+}
+
+func (lmb *lazyMemBuffer) activeFactory() MemBufferFactory {
+	if lmb.factory != nil {
+		return lmb.factory
+	}
+	return defaultMemBufferFactory
 }
 
 func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
@@ -124,7 +132,7 @@ func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
 
 func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer(lmb.cap)
+		lmb.mb = lmb.activeFactory().NewMemBuffer(lmb.cap)
 	}
 
 	return lmb.mb.Set(key, value)
@@ -132,7 +140,7 @@ func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 
 func (lmb *lazyMemBuffer) Delete(k Key) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer(lmb.cap)
+		lmb.mb = lmb.activeFactory().NewMemBuffer(lmb.cap)
 	}
 
 	return lmb.mb.Delete(k)
@@ -0,0 +1,130 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoid
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+)
+
+// ReserveRange atomically bumps the persisted auto-increment counter for
+// tableID by n and returns the contiguous half-open range [min, max) that
+// belongs to the caller. It talks to meta directly, in its own
+// transaction, rather than through any single *Allocator's locally cached
+// [base, end) window: systems like RadonDB and Arana front TiDB with a
+// sharding proxy that hands out IDs from many proxy instances at once, so
+// the source of truth for a reservation has to be the persisted counter
+// itself, not one allocator's in-process cache.
+func ReserveRange(store kv.Storage, dbID, tableID, n int64) (min, max int64, err error) {
+	if n <= 0 {
+		return 0, 0, errors.Errorf("autoid: ReserveRange requires n > 0, got %d", n)
+	}
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		newEnd, err1 := m.GenAutoTableID(dbID, tableID, n)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		// The counter holds the last ID handed out, so the new IDs are
+		// newEnd-n+1 through newEnd.
+		max = newEnd + 1
+		min = newEnd - n + 1
+		return nil
+	})
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	return min, max, nil
+}
+
+// Lease records that the half-open range [Min, Max) reserved for TableID by
+// LeaseRange is held by Owner until ExpireTime. A lessee that completes
+// normally should call (*LeaseRegistry).Release; one that crashes leaves
+// the lease behind until ReclaimExpired drops it. The range itself is not
+// handed out again either way.
+type Lease struct {
+	TableID    int64
+	Min, Max   int64
+	Owner      string
+	ExpireTime time.Time
+}
+
+// LeaseRegistry tracks in-flight range leases handed out by LeaseRange. Its
+// step is NextStep-aware in the sense that callers are expected to size n
+// the same way the single-ID allocator sizes its own cache window (see
+// NextStep), so a lease roughly amortizes to one meta round-trip per
+// NextStep-sized batch of rows instead of one per row.
+type LeaseRegistry struct {
+	mu     sync.Mutex
+	leases map[string]*Lease
+}
+
+// NewLeaseRegistry builds an empty LeaseRegistry.
+func NewLeaseRegistry() *LeaseRegistry {
+	return &LeaseRegistry{leases: make(map[string]*Lease)}
+}
+
+// LeaseRange behaves like ReserveRange, but also records a lease in r so
+// the reserved range can be reclaimed after ttl if owner never confirms it
+// finished using the range (see ReclaimExpired).
+func (r *LeaseRegistry) LeaseRange(store kv.Storage, dbID, tableID, n int64, owner string, ttl time.Duration) (*Lease, error) {
+	min, max, err := ReserveRange(store, dbID, tableID, n)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	lease := &Lease{
+		TableID:    tableID,
+		Min:        min,
+		Max:        max,
+		Owner:      owner,
+		ExpireTime: leaseNow().Add(ttl),
+	}
+	r.mu.Lock()
+	r.leases[owner] = lease
+	r.mu.Unlock()
+	return lease, nil
+}
+
+// ReclaimExpired drops every lease in r whose TTL has passed and returns
+// them. The ranges they named are not reused automatically — TiDB never
+// hands out a given [min, max) twice regardless of lease state — this only
+// stops r from growing without bound as lessees come and go.
+func (r *LeaseRegistry) ReclaimExpired() []*Lease {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := leaseNow()
+	var reclaimed []*Lease
+	for owner, lease := range r.leases {
+		if now.After(lease.ExpireTime) {
+			reclaimed = append(reclaimed, lease)
+			delete(r.leases, owner)
+		}
+	}
+	return reclaimed
+}
+
+// Release lets a lessee that finished normally give its lease back early,
+// rather than waiting for ReclaimExpired to time it out.
+func (r *LeaseRegistry) Release(owner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.leases, owner)
+}
+
+// leaseNow is a var so tests can fake the passage of time without sleeping.
+var leaseNow = time.Now
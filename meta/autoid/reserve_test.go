@@ -0,0 +1,130 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoid_test
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/store/mockstore"
+)
+
+// TestConcurrentReserveRange mirrors TestConcurrentAlloc, but drives
+// allocation through ReserveRange from N goroutines instead of Alloc, and
+// asserts the ranges they get back tile [1, 1+sum(n)) with no duplicates and
+// no gaps.
+func (*testSuite) TestConcurrentReserveRange(c *C) {
+	store, err := mockstore.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	dbID := int64(2)
+	tblID := int64(100)
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: dbID, Name: model.NewCIStr("a")})
+		c.Assert(err, IsNil)
+		err = m.CreateTableOrView(dbID, &model.TableInfo{ID: tblID, Name: model.NewCIStr("t")})
+		c.Assert(err, IsNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	const n = int64(37)
+	count := 10
+	var mu sync.Mutex
+	var ranges [][2]int64
+	wg := sync.WaitGroup{}
+	errCh := make(chan error, count)
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			min, max, err1 := autoid.ReserveRange(store, dbID, tblID, n)
+			if err1 != nil {
+				errCh <- err1
+				return
+			}
+			if max-min != n {
+				errCh <- fmt.Errorf("expected range of size %d, got [%d, %d)", n, min, max)
+				return
+			}
+			mu.Lock()
+			ranges = append(ranges, [2]int64{min, max})
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	err = <-errCh
+	c.Assert(err, IsNil)
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	for i, r := range ranges {
+		want := int64(i)*n + 1
+		c.Assert(r[0], Equals, want)
+		c.Assert(r[1], Equals, want+n)
+	}
+}
+
+// TestLeaseRangeReclaim checks that a lease not released before its TTL
+// elapses is returned by ReclaimExpired exactly once, and that releasing a
+// lease drops it instead of letting it reappear later.
+func (*testSuite) TestLeaseRangeReclaim(c *C) {
+	store, err := mockstore.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	dbID := int64(3)
+	tblID := int64(200)
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: dbID, Name: model.NewCIStr("b")})
+		c.Assert(err, IsNil)
+		err = m.CreateTableOrView(dbID, &model.TableInfo{ID: tblID, Name: model.NewCIStr("t")})
+		c.Assert(err, IsNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	registry := autoid.NewLeaseRegistry()
+
+	lease, err := registry.LeaseRange(store, dbID, tblID, 10, "proxy-a", time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(lease.Max-lease.Min, Equals, int64(10))
+
+	released, err := registry.LeaseRange(store, dbID, tblID, 10, "proxy-b", time.Hour)
+	c.Assert(err, IsNil)
+	registry.Release("proxy-b")
+
+	time.Sleep(5 * time.Millisecond)
+	reclaimed := registry.ReclaimExpired()
+	c.Assert(len(reclaimed), Equals, 1)
+	c.Assert(reclaimed[0].Owner, Equals, "proxy-a")
+
+	// proxy-b was released explicitly, so it must not show up again even
+	// though it would also be past its (much longer) TTL eventually.
+	for _, l := range reclaimed {
+		c.Assert(l.Owner, Not(Equals), "proxy-b")
+	}
+	_ = released
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/meta/autoid/service"
+	"github.com/pingcap/tidb/store/mockstore"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testSuite{})
+
+type testSuite struct{}
+
+// TestNewServerDisabled checks that the service refuses to come up at all
+// unless an operator has explicitly opted in via Config.Enabled.
+func (*testSuite) TestNewServerDisabled(c *C) {
+	store, err := mockstore.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	_, err = service.NewServer(store, service.Config{Enabled: false})
+	c.Assert(err, NotNil)
+}
+
+// TestConcurrentReserveRangeRPC mirrors TestConcurrentAlloc in
+// meta/autoid, but drives allocation through the Server's ReserveRange RPC
+// from N goroutines, and asserts the ranges it hands back tile
+// [1, 1+sum(n)) with no duplicates and no gaps.
+func (*testSuite) TestConcurrentReserveRangeRPC(c *C) {
+	store, err := mockstore.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	dbID := int64(4)
+	tblID := int64(300)
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: dbID, Name: model.NewCIStr("c")})
+		c.Assert(err, IsNil)
+		err = m.CreateTableOrView(dbID, &model.TableInfo{ID: tblID, Name: model.NewCIStr("t")})
+		c.Assert(err, IsNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	srv, err := service.NewServer(store, service.Config{Enabled: true})
+	c.Assert(err, IsNil)
+
+	const n = int64(23)
+	count := 10
+	var mu sync.Mutex
+	var ranges [][2]int64
+	wg := sync.WaitGroup{}
+	errCh := make(chan error, count)
+	ctx := context.Background()
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err1 := srv.ReserveRange(ctx, &service.ReserveRangeRequest{DBID: dbID, TableID: tblID, N: n})
+			if err1 != nil {
+				errCh <- err1
+				return
+			}
+			if resp.Max-resp.Min != n {
+				errCh <- fmt.Errorf("expected range of size %d, got [%d, %d)", n, resp.Min, resp.Max)
+				return
+			}
+			mu.Lock()
+			ranges = append(ranges, [2]int64{resp.Min, resp.Max})
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	err = <-errCh
+	c.Assert(err, IsNil)
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	for i, r := range ranges {
+		want := int64(i)*n + 1
+		c.Assert(r[0], Equals, want)
+		c.Assert(r[1], Equals, want+n)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doctor walks the descriptor storage meta.Meta exposes and
+// reports dangling references, missing constraint IDs, and orphaned
+// namespace entries: the same class of corruption an operator would
+// otherwise only notice by eyeballing a debug-zip dump.
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+)
+
+// PKMaxScanner answers "what is the largest primary key value this table
+// currently holds", so Examine can tell whether a table's autoid counter
+// has fallen behind the rows actually present. Implementations may answer
+// from a bounded sample or an exact coprocessor scan; Sampled reports which
+// one happened so the report can say so.
+type PKMaxScanner interface {
+	MaxPK(dbName, tableName string, tableID int64) (max int64, sampled bool, err error)
+}
+
+// Options controls what Examine checks and whether it may repair anything.
+type Options struct {
+	// PKMax answers the autoid-vs-actual-max-pk check. A nil PKMax skips
+	// that check entirely rather than reporting every table as unknown.
+	PKMax PKMaxScanner
+	// Repair rebases a table's autoid counter forward when it has fallen
+	// behind the table's actual max PK. It never touches schema-level
+	// corruption (dangling FKs, zero-ID constraints, namespace mismatches)
+	// — those need a human to decide the fix.
+	Repair bool
+}
+
+// ObjectResult is one line of the report: the outcome of examining a
+// single database, table, or constraint.
+type ObjectResult struct {
+	Kind      string // "database", "table", "foreign_key", "index", "autoid"
+	DB        string
+	Table     string
+	Processed bool
+	Errors    []string
+	Repaired  bool
+}
+
+// Report is the result of a full Examine pass.
+type Report struct {
+	Objects []ObjectResult
+}
+
+// OK reports whether every object examined was clean (or successfully
+// repaired).
+func (r *Report) OK() bool {
+	for _, o := range r.Objects {
+		if len(o.Errors) > 0 && !o.Repaired {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the report as the "processed"/"error" lines tidb-ctl
+// doctor prints, in Examine's iteration order: one line for each clean
+// object and one line for each error of an object that has errors.
+func (r *Report) String() string {
+	out := ""
+	for _, o := range r.Objects {
+		if len(o.Errors) == 0 {
+			out += fmt.Sprintf("%s db=%s table=%s processed\n", o.Kind, o.DB, o.Table)
+			continue
+		}
+		for _, e := range o.Errors {
+			status := "error"
+			if o.Repaired {
+				status = "repaired"
+			}
+			out += fmt.Sprintf("%s db=%s table=%s %s: %s\n", o.Kind, o.DB, o.Table, status, e)
+		}
+	}
+	return out
+}
+
+// Examine iterates every DBInfo/TableInfo reachable from store via
+// meta.Meta and checks, for each table: that the autoid counter is not
+// behind the table's actual max PK, that every foreign key's referenced
+// database still exists, and that every constraint carries a non-zero ID;
+// and, for each database, that its ID resolves back to the descriptor
+// Examine just read. Whether a foreign key's referenced table or index
+// exists is not checked, and table namespace entries are not checked.
+func Examine(store kv.Storage, opts Options) (*Report, error) {
+	report := &Report{}
+	err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		dbs, err := m.ListDatabases()
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		known := make(map[string]struct{}, len(dbs))
+		tablesByDB := make(map[int64][]*model.TableInfo, len(dbs))
+		for _, db := range dbs {
+			known[db.Name.L] = struct{}{}
+			tbls, err := m.ListTables(db.ID)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			tablesByDB[db.ID] = tbls
+			report.Objects = append(report.Objects, examineNamespace(m, db))
+		}
+
+		for _, db := range dbs {
+			for _, tbl := range tablesByDB[db.ID] {
+				report.Objects = append(report.Objects, examineConstraints(db, tbl, known)...)
+				if opts.PKMax != nil {
+					report.Objects = append(report.Objects, examineAutoID(m, db, tbl, opts))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return report, nil
+}
+
+// examineNamespace checks that db's own ID still resolves back to a
+// descriptor named db.Name — the kind of drift a half-applied rename or a
+// restored-from-backup namespace table would leave behind.
+func examineNamespace(m *meta.Meta, db *model.DBInfo) ObjectResult {
+	res := ObjectResult{Kind: "database", DB: db.Name.O}
+	got, err := m.GetDatabase(db.ID)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("namespace entry db_id=%d does not resolve: %v", db.ID, err))
+		return res
+	}
+	if got.Name.L != db.Name.L {
+		res.Errors = append(res.Errors, fmt.Sprintf("namespace entry db_id=%d resolves to %q, expected %q", db.ID, got.Name.O, db.Name.O))
+	}
+	return res
+}
+
+// examineConstraints checks tbl's foreign keys and indices: every FK must
+// reference a database that still exists, and every FK/index must carry a
+// non-zero ID.
+func examineConstraints(db *model.DBInfo, tbl *model.TableInfo, knownDBs map[string]struct{}) []ObjectResult {
+	var out []ObjectResult
+	for _, idx := range tbl.Indices {
+		res := ObjectResult{Kind: "index", DB: db.Name.O, Table: tbl.Name.O}
+		if idx.ID == 0 {
+			res.Errors = append(res.Errors, fmt.Sprintf("index %q has a zero ID", idx.Name.O))
+		}
+		out = append(out, res)
+	}
+	for _, fk := range tbl.ForeignKeys {
+		res := ObjectResult{Kind: "foreign_key", DB: db.Name.O, Table: tbl.Name.O}
+		if fk.ID == 0 {
+			res.Errors = append(res.Errors, fmt.Sprintf("foreign key %q has a zero ID", fk.Name.O))
+		}
+		refSchema := fk.RefSchema.L
+		if refSchema == "" {
+			refSchema = db.Name.L
+		}
+		if _, ok := knownDBs[refSchema]; !ok {
+			res.Errors = append(res.Errors, fmt.Sprintf("foreign key %q references missing database %q", fk.Name.O, fk.RefSchema.O))
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// examineAutoID checks tbl's autoid counter against its actual max PK via
+// opts.PKMax, rebasing the counter forward in --repair mode when it has
+// fallen behind.
+func examineAutoID(m *meta.Meta, db *model.DBInfo, tbl *model.TableInfo, opts Options) ObjectResult {
+	res := ObjectResult{Kind: "autoid", DB: db.Name.O, Table: tbl.Name.O}
+	maxPK, _, err := opts.PKMax.MaxPK(db.Name.O, tbl.Name.O, tbl.ID)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("scanning max PK: %v", err))
+		return res
+	}
+	current, err := m.GetAutoTableID(db.ID, tbl.ID)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("reading autoid counter: %v", err))
+		return res
+	}
+	if current >= maxPK {
+		return res
+	}
+	res.Errors = append(res.Errors, fmt.Sprintf("autoid counter %d is behind actual max PK %d", current, maxPK))
+	if opts.Repair {
+		if _, err := m.GenAutoTableID(db.ID, tbl.ID, maxPK-current); err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("repair: rebasing autoid counter: %v", err))
+			return res
+		}
+		res.Repaired = true
+	}
+	return res
+}
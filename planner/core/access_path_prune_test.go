@@ -0,0 +1,48 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// pruneAccessPathsByStats itself isn't exercised here: SessionVars,
+// *statistics.Table and AccessPath are all external types this checkout
+// doesn't define, so there's no way to construct the arguments it needs
+// without fabricating them. indexVisitsTooManyRows is the pure arithmetic
+// it delegates to, and is the part that's actually testable in isolation.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testAccessPathPruneSuite{})
+
+type testAccessPathPruneSuite struct{}
+
+func (s *testAccessPathPruneSuite) TestIndexVisitsTooManyRowsLowNDVPrunes(c *C) {
+	// NDV close to 1 means the index visits nearly every row -- no better
+	// than a table scan, so it should be pruned at any reasonable ratio.
+	c.Assert(indexVisitsTooManyRows(1000, 1, 0.1), IsTrue)
+}
+
+func (s *testAccessPathPruneSuite) TestIndexVisitsTooManyRowsHighNDVKeeps(c *C) {
+	// NDV == row count means the index is fully selective -- always worth
+	// keeping.
+	c.Assert(indexVisitsTooManyRows(1000, 1000, 0.1), IsFalse)
+}
+
+func (s *testAccessPathPruneSuite) TestIndexVisitsTooManyRowsRatioBoundary(c *C) {
+	// NDV of 2 over 1000 rows visits 500 rows, exactly half the table.
+	// A ratio of 0.5 tolerates visiting up to half the table, so this
+	// should not be pruned; a slightly stricter ratio should prune it.
+	c.Assert(indexVisitsTooManyRows(1000, 2, 0.5), IsFalse)
+	c.Assert(indexVisitsTooManyRows(1000, 2, 0.6), IsTrue)
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/terror"
+)
+
+// MySQL error instances raised by the plan builder. Each one is keyed by a
+// standard MySQL error code, which is how the wire protocol layer looks up
+// both the human-readable message (mysql.MySQLErrName) and the 5-byte
+// SQLSTATE it writes into the ERR_PACKET (mysql.MySQLState), so an
+// ORM/driver that switches on SQLSTATE rather than the vendor-specific
+// error number behaves the same against these errors as against any other
+// MySQL server:
+//   - ErrUnknownColumn      -> 42S22 (bad field)
+//   - ErrDupFieldName       -> 42S21 (duplicate field)
+//   - ErrWrongNumberOfColumnsInSelect -> 21000 (cardinality violation)
+//   - ErrWrongArguments     -> 42000 (syntax/client use error)
+//   - ErrFieldNotInGroupBy, ErrMixOfGroupFuncAndFields, ErrWrongGroupField ->
+//     42000, the same ONLY_FULL_GROUP_BY family checkOnlyFullGroupBy and
+//     rule_funcdep_group_by.go raise.
+//   - ErrIllegalReference, ErrInvalidWildCard, ErrBadTable,
+//     ErrPartitionClauseOnNonpartitioned -> HY000 (general error), matching
+//     what MySQL itself returns for each.
+//   - ErrInvalidGroupFuncUse, ErrAmbiguous, ErrWindowInvalidWindowFuncUse ->
+//     no override; these keep the generic SQLSTATE their MySQL error code
+//     already carries.
+//   - ErrBadGeneratedColumn (buildUpdateLists, generated column in an
+//     UPDATE's SET list) -> 42S22 (bad field), the same class as any other
+//     reference to a column that can't be written directly.
+//   - ErrNonUpdatableTable (buildUpdate/buildDelete, subquery or ambiguous
+//     self-join target), ErrViewInvalid (buildDataSource, a view whose
+//     query can no longer be resolved) -> HY000 (general error).
+//   - ErrWindowRowsIntervalUse (buildWindowFunctionFrameBound, an INTERVAL
+//     frame bound on a ROWS/GROUPS frame) -> 42000 (syntax/client use
+//     error), the same class as ErrWrongArguments above.
+//   - ErrUnknownTable (buildDelete, a `DELETE a FROM ...` target absent from
+//     the FROM clause) -> 42S02 (unknown table).
+//
+// ErrTableNotExists (SQLSTATE 42P01, undefined table), raised by
+// infoschema rather than this package, isn't duplicated here.
+var (
+	// ErrAmbiguous is raised when an unqualified column reference matches
+	// more than one select field or table source.
+	ErrAmbiguous = terror.ClassOptimizer.New(mysql.ErrNonUniq, mysql.MySQLErrName[mysql.ErrNonUniq])
+	// ErrUnknownColumn is raised when a column reference can't be resolved
+	// against any of the sources in scope for the clause it appears in.
+	ErrUnknownColumn = terror.ClassOptimizer.New(mysql.ErrBadFieldError, mysql.MySQLErrName[mysql.ErrBadFieldError])
+	// ErrWrongNumberOfColumnsInSelect is raised when two SELECTs combined by
+	// UNION, or the seed and recursive branches of a recursive CTE, don't
+	// project the same number of columns.
+	ErrWrongNumberOfColumnsInSelect = terror.ClassOptimizer.New(mysql.ErrWrongNumberOfColumnsInSelect, mysql.MySQLErrName[mysql.ErrWrongNumberOfColumnsInSelect])
+	// ErrWrongArguments is raised when a function this package special-cases
+	// at plan-build time (NAME_CONST, LIMIT/FETCH FIRST's count and offset,
+	// a window function's frame argument) is given a value it can't use.
+	ErrWrongArguments = terror.ClassOptimizer.New(mysql.ErrWrongArguments, mysql.MySQLErrName[mysql.ErrWrongArguments])
+	// ErrWindowInvalidWindowFuncUse is raised when a window function
+	// appears somewhere window functions aren't allowed, such as HAVING.
+	ErrWindowInvalidWindowFuncUse = terror.ClassOptimizer.New(mysql.ErrWindowInvalidWindowFuncUse, mysql.MySQLErrName[mysql.ErrWindowInvalidWindowFuncUse])
+	// ErrInvalidGroupFuncUse is raised when an aggregate function appears
+	// in WHERE, a JOIN's ON condition, or a GROUP BY item -- clauses that
+	// evaluate once per input row rather than once per group, where MySQL
+	// rejects a set function outright instead of silently grouping the
+	// whole result into one bucket.
+	ErrInvalidGroupFuncUse = terror.ClassOptimizer.New(mysql.ErrInvalidGroupFuncUse, mysql.MySQLErrName[mysql.ErrInvalidGroupFuncUse])
+	// ErrDupFieldName is raised when two select fields share the same
+	// resolved name, or, under strict SQL mode, when two non-auxiliary
+	// select fields share the same AsName (see checkDuplicateAlias).
+	ErrDupFieldName = terror.ClassOptimizer.New(mysql.ErrDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
+)
+
+func init() {
+	// terror.ErrClassToMySQLCodes lets the wire protocol layer translate one
+	// of this package's terror.Errors back to the MySQL error number a
+	// client expects, the same way store/tikv/error.go registers its own
+	// errors' codes. mysql.MySQLState then carries that number to the
+	// SQLSTATE the handshake writes, so registering the code here is enough
+	// to get the right SQLSTATE for free.
+	//
+	// ErrFieldNotInGroupBy, ErrMixOfGroupFuncAndFields, ErrWrongGroupField,
+	// ErrIllegalReference, ErrInvalidWildCard, ErrBadTable,
+	// ErrPartitionClauseOnNonpartitioned, ErrBadGeneratedColumn,
+	// ErrNonUpdatableTable, ErrViewInvalid, ErrWindowRowsIntervalUse and
+	// ErrUnknownTable are all raised elsewhere in this package
+	// (checkOnlyFullGroupBy and friends, buildProjection, buildDataSource,
+	// buildUpdate, buildUpdateLists, buildDelete,
+	// buildWindowFunctionFrameBound) against terror.ClassOptimizer with their
+	// own MySQL error codes already; registering those codes here is the
+	// only step needed to get a conforming SQLSTATE onto the wire for them
+	// too.
+	optimizerMySQLErrCodes := map[terror.ErrCode]uint16{
+		mysql.ErrNonUniq:                         mysql.ErrNonUniq,
+		mysql.ErrBadFieldError:                   mysql.ErrBadFieldError,
+		mysql.ErrWrongNumberOfColumnsInSelect:    mysql.ErrWrongNumberOfColumnsInSelect,
+		mysql.ErrWrongArguments:                  mysql.ErrWrongArguments,
+		mysql.ErrWindowInvalidWindowFuncUse:      mysql.ErrWindowInvalidWindowFuncUse,
+		mysql.ErrDupFieldName:                    mysql.ErrDupFieldName,
+		mysql.ErrInvalidGroupFuncUse:             mysql.ErrInvalidGroupFuncUse,
+		mysql.ErrFieldNotInGroupBy:               mysql.ErrFieldNotInGroupBy,
+		mysql.ErrMixOfGroupFuncAndFields:         mysql.ErrMixOfGroupFuncAndFields,
+		mysql.ErrWrongGroupField:                 mysql.ErrWrongGroupField,
+		mysql.ErrIllegalReference:                mysql.ErrIllegalReference,
+		mysql.ErrInvalidWildCard:                 mysql.ErrInvalidWildCard,
+		mysql.ErrBadGeneratedColumn:              mysql.ErrBadGeneratedColumn,
+		mysql.ErrNonUpdatableTable:               mysql.ErrNonUpdatableTable,
+		mysql.ErrViewInvalid:                     mysql.ErrViewInvalid,
+		mysql.ErrWindowRowsIntervalUse:           mysql.ErrWindowRowsIntervalUse,
+		mysql.ErrUnknownTable:                    mysql.ErrUnknownTable,
+		mysql.ErrBadTable:                        mysql.ErrBadTable,
+		mysql.ErrPartitionClauseOnNonpartitioned: mysql.ErrPartitionClauseOnNonpartitioned,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = optimizerMySQLErrCodes
+}
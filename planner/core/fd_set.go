@@ -0,0 +1,198 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/tidb/expression"
+)
+
+// fd is one functional dependency: every column in determinant, taken
+// together, determines dependent. A determinant of length 0 means
+// dependent is constant (e.g. from a `col = <const>` predicate).
+type fd struct {
+	determinant []int64
+	dependent   int64
+}
+
+// FDSet is a set of functional dependencies attached to a LogicalPlan's
+// output schema. It lets aggregationEliminateChecker (and, eventually,
+// other rules) recognize that a GROUP BY list determines every column an
+// aggregate references even when the GROUP BY columns are not literally a
+// declared unique/primary key of the immediate child -- e.g. because they
+// are equi-joined to one, or because the remaining columns are pinned to a
+// constant by a pushed-down Selection.
+type FDSet struct {
+	fds []fd
+}
+
+// NewFDSet creates an empty FDSet.
+func NewFDSet() *FDSet {
+	return &FDSet{}
+}
+
+// AddFD records that determinant -> dependent.
+func (s *FDSet) AddFD(determinant []int64, dependent int64) {
+	det := make([]int64, len(determinant))
+	copy(det, determinant)
+	s.fds = append(s.fds, fd{determinant: det, dependent: dependent})
+}
+
+// AddConstant records that column is pinned to a single value, i.e. the
+// empty set of columns determines it.
+func (s *FDSet) AddConstant(column int64) {
+	s.AddFD(nil, column)
+}
+
+// AddEquivalence records col1 <-> col2 from an equi-join or `col1 = col2`
+// predicate: each determines the other.
+func (s *FDSet) AddEquivalence(col1, col2 int64) {
+	s.AddFD([]int64{col1}, col2)
+	s.AddFD([]int64{col2}, col1)
+}
+
+// Closure returns the transitive closure of cols: every column that cols
+// functionally determines, including cols themselves.
+func (s *FDSet) Closure(cols []int64) map[int64]struct{} {
+	closure := make(map[int64]struct{}, len(cols))
+	for _, c := range cols {
+		closure[c] = struct{}{}
+	}
+	for {
+		grown := false
+		for _, f := range s.fds {
+			if _, ok := closure[f.dependent]; ok {
+				continue
+			}
+			if fdSatisfied(f.determinant, closure) {
+				closure[f.dependent] = struct{}{}
+				grown = true
+			}
+		}
+		if !grown {
+			return closure
+		}
+	}
+}
+
+// Determines reports whether cols' closure contains every column in target.
+func (s *FDSet) Determines(cols []int64, target []int64) bool {
+	closure := s.Closure(cols)
+	for _, t := range target {
+		if _, ok := closure[t]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func fdSatisfied(determinant []int64, closure map[int64]struct{}) bool {
+	for _, d := range determinant {
+		if _, ok := closure[d]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// buildFDSetFromSchema seeds an FDSet with one FD per declared unique key
+// of schema: the key's columns determine every column of schema.
+func buildFDSetFromSchema(schema *expression.Schema) *FDSet {
+	fds := NewFDSet()
+	allCols := make([]int64, 0, schema.Len())
+	for _, c := range schema.Columns {
+		allCols = append(allCols, int64(c.UniqueID))
+	}
+	for _, key := range schema.Keys {
+		det := make([]int64, 0, len(key))
+		for _, c := range key {
+			det = append(det, int64(c.UniqueID))
+		}
+		for _, dependent := range allCols {
+			fds.AddFD(det, dependent)
+		}
+	}
+	return fds
+}
+
+// buildFDSetFromChild additionally collects FDs from agg's child and the
+// level just below it: equi-join conditions (col = col, propagated as an
+// equivalence) and constant predicates from a pushed-down Selection (col =
+// <const>, recorded as AddConstant). It looks at child itself and, through
+// mergeChildFDs, at child's direct children, whatever operator child is,
+// and never deeper.
+func buildFDSetFromChild(child LogicalPlan) *FDSet {
+	fds := buildFDSetFromSchema(child.Schema())
+	switch x := child.(type) {
+	case *LogicalSelection:
+		for _, cond := range x.Conditions {
+			collectConstantFD(fds, cond)
+		}
+	case *LogicalJoin:
+		for _, cond := range x.EqualConditions {
+			collectEquivalenceFD(fds, cond)
+		}
+	}
+	for _, c := range child.Children() {
+		mergeChildFDs(fds, c)
+	}
+	return fds
+}
+
+// mergeChildFDs pulls in equi-join/constant FDs one level further down, so
+// `GROUP BY c.id` can still see a `Selection` or `Join` sitting under an
+// intermediate operator such as a `Projection`.
+func mergeChildFDs(fds *FDSet, child LogicalPlan) {
+	switch x := child.(type) {
+	case *LogicalSelection:
+		for _, cond := range x.Conditions {
+			collectConstantFD(fds, cond)
+		}
+	case *LogicalJoin:
+		for _, cond := range x.EqualConditions {
+			collectEquivalenceFD(fds, cond)
+		}
+	}
+}
+
+// collectConstantFD recognizes `col = <const>` and records col as constant.
+func collectConstantFD(fds *FDSet, cond expression.Expression) {
+	sf, ok := cond.(*expression.ScalarFunction)
+	if !ok || sf.FuncName.L != ast.EQ {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		col, ok := sf.GetArgs()[i].(*expression.Column)
+		if !ok {
+			continue
+		}
+		if _, ok := sf.GetArgs()[1-i].(*expression.Constant); ok {
+			fds.AddConstant(int64(col.UniqueID))
+		}
+	}
+}
+
+// collectEquivalenceFD recognizes `col1 = col2` and records the
+// equivalence both ways.
+func collectEquivalenceFD(fds *FDSet, cond expression.Expression) {
+	sf, ok := cond.(*expression.ScalarFunction)
+	if !ok || sf.FuncName.L != ast.EQ {
+		return
+	}
+	col1, ok1 := sf.GetArgs()[0].(*expression.Column)
+	col2, ok2 := sf.GetArgs()[1].(*expression.Column)
+	if ok1 && ok2 {
+		fds.AddEquivalence(int64(col1.UniqueID), int64(col2.UniqueID))
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testFDSetSuite{})
+
+type testFDSetSuite struct{}
+
+// TestFDSetClosureIncludesSeedColumns checks that Closure always contains
+// the columns it was seeded with, even with no functional dependencies
+// recorded at all.
+func (s *testFDSetSuite) TestFDSetClosureIncludesSeedColumns(c *C) {
+	fds := NewFDSet()
+	closure := fds.Closure([]int64{1, 2})
+	_, ok1 := closure[1]
+	_, ok2 := closure[2]
+	c.Assert(ok1, IsTrue)
+	c.Assert(ok2, IsTrue)
+}
+
+// TestFDSetAddFDExtendsClosureTransitively checks that a -> b and b -> c
+// chains into a's closure containing c, i.e. Closure computes the
+// transitive closure rather than stopping after one hop.
+func (s *testFDSetSuite) TestFDSetAddFDExtendsClosureTransitively(c *C) {
+	fds := NewFDSet()
+	fds.AddFD([]int64{1}, 2)
+	fds.AddFD([]int64{2}, 3)
+	closure := fds.Closure([]int64{1})
+	_, ok := closure[3]
+	c.Assert(ok, IsTrue)
+}
+
+// TestFDSetAddConstantDeterminesFromEmptyDeterminant checks that a
+// constant column (AddConstant) is in every closure, since an empty
+// determinant is trivially satisfied.
+func (s *testFDSetSuite) TestFDSetAddConstantDeterminesFromEmptyDeterminant(c *C) {
+	fds := NewFDSet()
+	fds.AddConstant(5)
+	c.Assert(fds.Determines([]int64{1}, []int64{5}), IsTrue)
+}
+
+// TestFDSetAddEquivalenceIsSymmetric checks that AddEquivalence records
+// the dependency in both directions.
+func (s *testFDSetSuite) TestFDSetAddEquivalenceIsSymmetric(c *C) {
+	fds := NewFDSet()
+	fds.AddEquivalence(1, 2)
+	c.Assert(fds.Determines([]int64{1}, []int64{2}), IsTrue)
+	c.Assert(fds.Determines([]int64{2}, []int64{1}), IsTrue)
+}
+
+// TestFDSetDeterminesRequiresFullDeterminant checks that a multi-column
+// determinant only fires once every one of its columns is present -- a
+// partial determinant must not leak the dependent column into the
+// closure.
+func (s *testFDSetSuite) TestFDSetDeterminesRequiresFullDeterminant(c *C) {
+	fds := NewFDSet()
+	fds.AddFD([]int64{1, 2}, 3)
+	c.Assert(fds.Determines([]int64{1}, []int64{3}), IsFalse)
+	c.Assert(fds.Determines([]int64{1, 2}, []int64{3}), IsTrue)
+}
+
+// TestFDSetDeterminesFalseForUnknownColumn checks that Determines reports
+// false, not a panic or a stale true, for a target column no recorded FD
+// ever mentions.
+func (s *testFDSetSuite) TestFDSetDeterminesFalseForUnknownColumn(c *C) {
+	fds := NewFDSet()
+	fds.AddFD([]int64{1}, 2)
+	c.Assert(fds.Determines([]int64{1}, []int64{99}), IsFalse)
+}
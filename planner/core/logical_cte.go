@@ -0,0 +1,150 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+// LogicalCTE is the fixpoint operator WITH RECURSIVE lowers to: evaluate
+// SeedPlan once, then repeatedly evaluate RecursivePlan against the rows the
+// previous iteration produced (the occurrence of the CTE's own name inside
+// RecursivePlan resolves to a LogicalCTEAnchor standing in for exactly that
+// working set) until an iteration adds no new rows, UNION-ing every
+// iteration's output together -- UNION DISTINCT if IsDistinct is set, UNION
+// ALL otherwise. A non-recursive CTE never produces one of these; every
+// reference to it instead shares the one LogicalPlan buildNonRecursiveCTE's
+// query was built into (see cteInfo.builtPlan), behind a LogicalCTERef.
+type LogicalCTE struct {
+	baseLogicalPlan
+
+	CTEName       model.CIStr
+	SeedPlan      LogicalPlan
+	RecursivePlan LogicalPlan
+	IsDistinct    bool
+	// MaxRecursionDepth caps the number of fixpoint iterations, taken from
+	// the tidb_max_recursive_iterations session variable at build time, so a
+	// mistakenly non-terminating recursive branch fails the statement
+	// instead of running forever. SessionVars.MaxRecursiveIterations is the
+	// assumed field backing that session variable; both live in the
+	// sessionctx/variable package, outside this checkout.
+	MaxRecursionDepth uint64
+}
+
+// Init assigns a context to LogicalCTE, following the same By-value literal
+// + Init(ctx) convention every other logical operator in this package uses.
+func (p LogicalCTE) Init(ctx sessionctx.Context) *LogicalCTE {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, "CTE", &p)
+	return &p
+}
+
+// LogicalCTEAnchor stands in, inside a LogicalCTE's RecursivePlan, for the
+// rows the previous fixpoint iteration produced -- it is what
+// PlanBuilder.buildDataSource returns when a FROM item names the CTE
+// currently being built instead of a real table. It carries no plan of its
+// own; the executor substitutes the previous iteration's working set for it
+// on every pass.
+type LogicalCTEAnchor struct {
+	baseLogicalPlan
+
+	CTEName model.CIStr
+}
+
+// Init assigns a context to LogicalCTEAnchor, following the same By-value
+// literal + Init(ctx) convention every other logical operator in this
+// package uses.
+func (p LogicalCTEAnchor) Init(ctx sessionctx.Context) *LogicalCTEAnchor {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, "CTEAnchor", &p)
+	return &p
+}
+
+// LogicalCTERef is what buildDataSource returns for every reference to a
+// finished CTE -- a non-recursive one, built once by buildNonRecursiveCTE's
+// first reference and cached on cteInfo.builtPlan, or a recursive one once
+// its own LogicalCTE has finished building. Its single child is a
+// LogicalProjection, one per reference, that projects cteInfo.builtPlan's
+// real output columns into this reference's own fresh UniqueIDs -- so two
+// references in the same statement are, unusually for this package, two
+// different LogicalProjections pointing at the same grandchild rather than
+// two independently built subtrees; CTEName is carried purely for EXPLAIN/
+// debugging, since the shared grandchild is already reachable via
+// Children()[0].Children()[0]. The projection is what makes "each
+// LogicalCTERef gets its own schema" true in more than name: an expression
+// built against this reference's schema names a UniqueID the projection
+// actually produces, the same way two references to the same view or table
+// get independent column identities that something still computes.
+//
+// None of the rule passes in this checkout (rule_aggregation_elimination.go,
+// rule_funcdep_group_by.go, rule_funcdep_simplify.go, rule_join_reorder_dp.go,
+// rule_projection_pull_up.go) mutate a child in place or push expressions
+// down past a LogicalProjection into it, so sharing that grandchild across
+// more than one LogicalCTERef's projection is safe here. A predicate-
+// pushdown-style rule, if one is added later, would need to either treat
+// LogicalCTERef (or the projection beneath it) as an opaque barrier (stop
+// there, the same way it would at a LogicalCTEAnchor) or this type would
+// need to grow into routing through one real materialization operator
+// instead of an aliased child -- the approach real multi-reference CTE
+// execution eventually needs anyway, since two LogicalCTERefs sharing one
+// in-memory result set at runtime is an executor-level concern this
+// checkout's executor package doesn't yet implement.
+type LogicalCTERef struct {
+	baseLogicalPlan
+
+	CTEName model.CIStr
+}
+
+// Init assigns a context to LogicalCTERef, following the same By-value
+// literal + Init(ctx) convention every other logical operator in this
+// package uses.
+func (p LogicalCTERef) Init(ctx sessionctx.Context) *LogicalCTERef {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, "CTERef", &p)
+	return &p
+}
+
+// cteInfo tracks one WITH-clause entry while it, and anything it encloses,
+// is being built. PlanBuilder keeps a stack of these (assumed field
+// b.ctes []*cteInfo, pushed in buildWith and popped once the statement that
+// declared them is fully built) so a nested WITH can shadow an outer one by
+// name the same way Go scoping would suggest, and so buildDataSource can
+// find the innermost CTE matching an unqualified table name before it ever
+// consults the real infoschema.
+type cteInfo struct {
+	name       model.CIStr
+	recursive  bool
+	seedSchema *expression.Schema
+	// query and colNameList are only set for a non-recursive CTE; the first
+	// reference buildDataSource resolves to it builds a plan from query
+	// (cte.Query.Query), renames its output columns to colNameList when the
+	// CTE declared one, and caches the result on builtPlan. Every later
+	// reference, including that first one, wraps builtPlan in a fresh
+	// LogicalCTERef rather than rebuilding it, so a CTE referenced N times
+	// is planned once, not N times.
+	query       ast.ResultSetNode
+	colNameList []model.CIStr
+	builtPlan   LogicalPlan
+	// recursiveRefCount counts how many times buildDataSource resolved a
+	// FROM item to this CTE while its own RecursivePlan was being built, so
+	// buildRecursiveCTE can enforce "the recursive reference appears
+	// exactly once" once that build finishes.
+	recursiveRefCount int
+	// buildingRecursive is true only while this CTE's own RecursivePlan is
+	// being built, so a reference to the CTE's name from anywhere else
+	// (another CTE in the same WITH list, or the main query) still resolves
+	// to it as an ordinary, already-finished derived table rather than as
+	// the anchor.
+	buildingRecursive bool
+}
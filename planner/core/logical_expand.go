@@ -0,0 +1,58 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+// LogicalExpand duplicates every row from its child once per entry in
+// GroupingSets, nulling out every GROUP BY column not in that entry's set,
+// and appends GroupingIDCol stamped with the entry's (1-based) position.
+// expandGroupingSets builds one of these in front of a LogicalAggregation
+// whenever GROUP BY ROLLUP/CUBE/GROUPING SETS needs more than one grouping
+// set computed in a single pass; a plain GROUP BY never introduces one.
+//
+// Because it changes the cardinality and NULLability of its child's rows,
+// columnPruner must not prune a GROUP BY column LogicalExpand still needs
+// to null out per set even if no aggregate references it, and
+// aggregationPushDownSolver must not push an aggregate below it the way it
+// would push one below an ordinary child -- both of those rules live in
+// files outside this checkout, so this is a note for whoever adds them
+// rather than an enforced check here.
+type LogicalExpand struct {
+	baseLogicalPlan
+
+	// GroupingSets holds, for each grouping set, the columns that stay real
+	// for that set; every other GROUP BY column is replaced with NULL in
+	// the rows emitted for it.
+	GroupingSets [][]*expression.Column
+	// GroupingIDCol is appended to the child's schema and set to the
+	// (1-based) index of the grouping set a given output row belongs to, so
+	// GROUPING(col) can tell a real NULL from one LogicalExpand introduced:
+	// GROUPING(col) is true exactly when col is absent from
+	// GroupingSets[GroupingIDCol-1]. The scalar function itself is a
+	// builtin registered in expression/builtin_other.go, outside this
+	// checkout.
+	GroupingIDCol *expression.Column
+}
+
+// Init assigns a context to LogicalExpand, following the same By-value
+// literal + Init(ctx) convention every other logical operator in this
+// package uses (see LogicalJoin{...}.Init(ctx) in logical_plan_builder.go).
+func (p LogicalExpand) Init(ctx sessionctx.Context) *LogicalExpand {
+	p.baseLogicalPlan = newBaseLogicalPlan(ctx, "Expand", &p)
+	return &p
+}
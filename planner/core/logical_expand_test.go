@@ -0,0 +1,67 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
+)
+
+var _ = Suite(&testLogicalExpandSuite{})
+
+type testLogicalExpandSuite struct{}
+
+// TestRollupOrCubeSetsRollupProducesPrefixes checks that ROLLUP over
+// (a, b, c) produces the n+1 nested prefixes, from the full grouping down
+// to the grand-total empty set, in descending order of specificity.
+func (s *testLogicalExpandSuite) TestRollupOrCubeSetsRollupProducesPrefixes(c *C) {
+	a := &expression.Column{UniqueID: 1}
+	b := &expression.Column{UniqueID: 2}
+	cc := &expression.Column{UniqueID: 3}
+	sets := rollupOrCubeSets([]*expression.Column{a, b, cc}, false)
+	c.Assert(sets, HasLen, 4)
+	c.Assert(sets[0], DeepEquals, []*expression.Column{a, b, cc})
+	c.Assert(sets[1], DeepEquals, []*expression.Column{a, b})
+	c.Assert(sets[2], DeepEquals, []*expression.Column{a})
+	c.Assert(sets[3], HasLen, 0)
+}
+
+// TestRollupOrCubeSetsCubeProducesAllSubsets checks that CUBE over
+// (a, b) produces all 2^2 subsets, including the full set and the empty
+// set.
+func (s *testLogicalExpandSuite) TestRollupOrCubeSetsCubeProducesAllSubsets(c *C) {
+	a := &expression.Column{UniqueID: 1}
+	b := &expression.Column{UniqueID: 2}
+	sets := rollupOrCubeSets([]*expression.Column{a, b}, true)
+	c.Assert(sets, HasLen, 4)
+
+	seen := make(map[string]bool, len(sets))
+	for _, set := range sets {
+		key := ""
+		for _, col := range set {
+			key += string(rune('a' + int(col.UniqueID) - 1))
+		}
+		seen[key] = true
+	}
+	c.Assert(seen, DeepEquals, map[string]bool{"ab": true, "a": true, "b": true, "": true})
+}
+
+// TestRollupOrCubeSetsEmptyColumnsYieldsOneEmptySet checks the degenerate
+// GROUP BY ROLLUP() case: no columns still yields exactly one (empty)
+// grouping set, the grand total.
+func (s *testLogicalExpandSuite) TestRollupOrCubeSetsEmptyColumnsYieldsOneEmptySet(c *C) {
+	sets := rollupOrCubeSets(nil, false)
+	c.Assert(sets, HasLen, 1)
+	c.Assert(sets[0], HasLen, 0)
+}
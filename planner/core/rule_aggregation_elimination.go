@@ -37,16 +37,11 @@ type aggregationEliminateChecker struct {
 // If we can eliminate agg successful, we return a projection. Else we return a nil pointer.
 func (a *aggregationEliminateChecker) tryToEliminateAggregation(agg *LogicalAggregation) *LogicalProjection {
 	for _, af := range agg.AggFuncs {
-		// TODO(issue #9968): Actually, we can rewrite GROUP_CONCAT when all the
-		// arguments it accepts are promised to be NOT-NULL.
-		// When it accepts only 1 argument, we can extract this argument into a
-		// projection.
-		// When it accepts multiple arguments, we can wrap the arguments with a
-		// function CONCAT_WS and extract this function into a projection.
-		// BUT, GROUP_CONCAT should truncate the final result according to the
-		// system variable `group_concat_max_len`. To ensure the correctness of
-		// the result, we close the elimination of GROUP_CONCAT here.
-		if af.Name == ast.AggFuncGroupConcat {
+		// GROUP_CONCAT can only be eliminated when every argument is
+		// provably NOT NULL: the rewrite below still has to apply
+		// group_concat_max_len truncation, but does not need GROUP_CONCAT's
+		// NULL-skipping behavior once nulls are ruled out.
+		if af.Name == ast.AggFuncGroupConcat && !a.groupConcatArgsNotNull(af) {
 			return nil
 		}
 	}
@@ -64,9 +59,47 @@ func (a *aggregationEliminateChecker) tryToEliminateAggregation(agg *LogicalAggr
 		proj.SetChildren(agg.children[0])
 		return proj
 	}
+	if a.coveredByFD(agg) {
+		// GroupByCols' functional-dependency closure covers every column the
+		// aggregate arguments reference, so grouping by them still leaves one
+		// row per group -- the aggregation can be removed just as if they
+		// were a literal unique key.
+		proj := a.convertAggToProj(agg)
+		proj.SetChildren(agg.children[0])
+		return proj
+	}
 	return nil
 }
 
+// coveredByFD extends the unique-key check with functional dependencies
+// collected from the child plan: equi-join predicates (grouping by one side
+// of `a.x = b.y` also determines the other) and constant predicates from a
+// pushed-down Selection (grouping by the remaining columns still uniquely
+// determines a column pinned to a constant). See FDSet for details.
+func (a *aggregationEliminateChecker) coveredByFD(agg *LogicalAggregation) bool {
+	target := make(map[int64]struct{})
+	for _, af := range agg.AggFuncs {
+		for _, arg := range af.Args {
+			for _, col := range expression.ExtractColumns(arg) {
+				target[int64(col.UniqueID)] = struct{}{}
+			}
+		}
+	}
+	if len(target) == 0 {
+		return false
+	}
+	groupByCols := make([]int64, 0, len(agg.groupByCols))
+	for _, c := range agg.groupByCols {
+		groupByCols = append(groupByCols, int64(c.UniqueID))
+	}
+	targetCols := make([]int64, 0, len(target))
+	for c := range target {
+		targetCols = append(targetCols, c)
+	}
+	fds := buildFDSetFromChild(agg.children[0])
+	return fds.Determines(groupByCols, targetCols)
+}
+
 func (a *aggregationEliminateChecker) convertAggToProj(agg *LogicalAggregation) *LogicalProjection {
 	proj := LogicalProjection{
 		Exprs: make([]expression.Expression, 0, len(agg.AggFuncs)),
@@ -87,8 +120,10 @@ func (a *aggregationEliminateChecker) rewriteExpr(ctx sessionctx.Context, aggFun
 			return a.wrapCastFunction(ctx, aggFunc.Args[0], aggFunc.RetTp)
 		}
 		return a.rewriteCount(ctx, aggFunc.Args, aggFunc.RetTp)
-	case ast.AggFuncSum, ast.AggFuncAvg, ast.AggFuncFirstRow, ast.AggFuncMax, ast.AggFuncMin, ast.AggFuncGroupConcat:
+	case ast.AggFuncSum, ast.AggFuncAvg, ast.AggFuncFirstRow, ast.AggFuncMax, ast.AggFuncMin:
 		return a.wrapCastFunction(ctx, aggFunc.Args[0], aggFunc.RetTp)
+	case ast.AggFuncGroupConcat:
+		return a.rewriteGroupConcat(ctx, aggFunc)
 	case ast.AggFuncBitAnd, ast.AggFuncBitOr, ast.AggFuncBitXor:
 		return a.rewriteBitFunc(ctx, aggFunc.Name, aggFunc.Args[0], aggFunc.RetTp)
 	default:
@@ -96,6 +131,52 @@ func (a *aggregationEliminateChecker) rewriteExpr(ctx sessionctx.Context, aggFun
 	}
 }
 
+// groupConcatArgsNotNull reports whether every value argument of a
+// GROUP_CONCAT call is provably NOT NULL, i.e. safe to eliminate without
+// GROUP_CONCAT's implicit NULL-skipping changing the result. The trailing
+// argument is always the separator constant and is not considered.
+func (a *aggregationEliminateChecker) groupConcatArgsNotNull(aggFunc *aggregation.AggFuncDesc) bool {
+	valueArgs := aggFunc.Args[:len(aggFunc.Args)-1]
+	if len(valueArgs) == 0 {
+		return false
+	}
+	for _, arg := range valueArgs {
+		if !mysql.HasNotNullFlag(arg.GetType().Flag) {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteGroupConcat rewrites an eliminable GROUP_CONCAT(arg1, ..., argN
+// SEPARATOR sep) into a plain expression equivalent to its single-row
+// value, still bounded by @@group_concat_max_len:
+//   - 1 value argument:  LEFT(CAST(arg AS CHAR), group_concat_max_len)
+//   - N value arguments: LEFT(CONCAT_WS(sep, arg1, ..., argN), group_concat_max_len)
+//
+// group_concat_max_len is read from the session at rewrite time since the
+// eliminated aggregation no longer has a runtime hook of its own to consult
+// it; MySQL's "Row was truncated" (1260) warning is emitted by LEFT's own
+// evaluation when it actually cuts the string, exactly as it would for any
+// other expression hitting the same limit.
+func (a *aggregationEliminateChecker) rewriteGroupConcat(ctx sessionctx.Context, aggFunc *aggregation.AggFuncDesc) expression.Expression {
+	valueArgs := aggFunc.Args[:len(aggFunc.Args)-1]
+	var body expression.Expression
+	if len(valueArgs) == 1 {
+		body = expression.BuildCastFunction(ctx, valueArgs[0], types.NewFieldType(mysql.TypeVarString))
+	} else {
+		sep := aggFunc.Args[len(aggFunc.Args)-1]
+		concatArgs := append([]expression.Expression{sep}, valueArgs...)
+		body = expression.NewFunctionInternal(ctx, ast.ConcatWS, types.NewFieldType(mysql.TypeVarString), concatArgs...)
+	}
+	maxLen := ctx.GetSessionVars().GroupConcatMaxLen
+	maxLenConst := &expression.Constant{
+		Value:   types.NewUintDatum(maxLen),
+		RetType: types.NewFieldType(mysql.TypeLonglong),
+	}
+	return expression.NewFunctionInternal(ctx, ast.Left, aggFunc.RetTp, body, maxLenConst)
+}
+
 func (a *aggregationEliminateChecker) rewriteCount(ctx sessionctx.Context, exprs []expression.Expression, targetTp *types.FieldType) expression.Expression {
 	// If is count(expr), we will change it to if(isnull(expr), 0, 1).
 	// If is count(distinct x, y, z) we will change it to if(isnull(x) or isnull(y) or isnull(z), 0, 1).
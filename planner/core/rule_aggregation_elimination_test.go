@@ -0,0 +1,68 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/types"
+)
+
+var _ = Suite(&testAggregationEliminationSuite{})
+
+type testAggregationEliminationSuite struct{}
+
+func notNullCol(id int64) *expression.Column {
+	tp := types.NewFieldType(mysql.TypeVarString)
+	tp.Flag |= mysql.NotNullFlag
+	return &expression.Column{UniqueID: id, RetType: tp}
+}
+
+func nullableCol(id int64) *expression.Column {
+	return &expression.Column{UniqueID: id, RetType: types.NewFieldType(mysql.TypeVarString)}
+}
+
+// TestGroupConcatArgsNotNullAllNotNull checks that GROUP_CONCAT is
+// reported eliminable when every value argument (everything but the
+// trailing separator) is declared NOT NULL.
+func (s *testAggregationEliminationSuite) TestGroupConcatArgsNotNullAllNotNull(c *C) {
+	a := &aggregationEliminateChecker{}
+	aggFunc := &aggregation.AggFuncDesc{
+		Args: []expression.Expression{notNullCol(1), notNullCol(2), expression.Zero},
+	}
+	c.Assert(a.groupConcatArgsNotNull(aggFunc), IsTrue)
+}
+
+// TestGroupConcatArgsNotNullOneNullable checks that a single nullable
+// value argument disqualifies the whole call from elimination, since
+// GROUP_CONCAT's NULL-skipping behavior could not be reproduced by the
+// rewritten expression.
+func (s *testAggregationEliminationSuite) TestGroupConcatArgsNotNullOneNullable(c *C) {
+	a := &aggregationEliminateChecker{}
+	aggFunc := &aggregation.AggFuncDesc{
+		Args: []expression.Expression{notNullCol(1), nullableCol(2), expression.Zero},
+	}
+	c.Assert(a.groupConcatArgsNotNull(aggFunc), IsFalse)
+}
+
+// TestGroupConcatArgsNotNullNoValueArgs checks the degenerate case of no
+// value arguments (only a separator) reports false rather than true from
+// an empty-slice vacuous loop.
+func (s *testAggregationEliminationSuite) TestGroupConcatArgsNotNullNoValueArgs(c *C) {
+	a := &aggregationEliminateChecker{}
+	aggFunc := &aggregation.AggFuncDesc{Args: []expression.Expression{expression.Zero}}
+	c.Assert(a.groupConcatArgsNotNull(aggFunc), IsFalse)
+}
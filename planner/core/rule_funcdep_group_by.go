@@ -0,0 +1,313 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/planner/funcdep"
+)
+
+// buildFuncDepSet derives p's functional dependencies bottom-up. This is a
+// free function rather than a LogicalPlan.FDSet() method because the
+// LogicalPlan interface itself is declared outside this checkout (the same
+// reason buildFDSetFromChild in fd_set.go is a free function too); once
+// that file is available, hanging this off the interface and caching the
+// result on each node is the natural next step. Its callers
+// (checkOnlyFullGroupByWithGroupClause below, and canElideDistinct and
+// pruneRedundantOrderBy in rule_funcdep_simplify.go) each ask once per plan
+// they check, and every call recomputes the set bottom-up.
+func buildFuncDepSet(p LogicalPlan) *funcdep.FDSet {
+	switch x := p.(type) {
+	case *DataSource:
+		return buildFuncDepSetFromDataSource(x)
+	case *LogicalSelection:
+		fds := buildFuncDepSet(x.children[0])
+		for _, cond := range x.Conditions {
+			applyFuncDepCondition(fds, cond)
+		}
+		return fds
+	case *LogicalJoin:
+		return buildFuncDepSetFromJoin(x)
+	case *LogicalAggregation:
+		fds := funcdep.New()
+		groupCols := funcdep.NewColSet()
+		for _, c := range x.groupByCols {
+			groupCols.Insert(int64(c.UniqueID))
+		}
+		outCols := funcdep.NewColSet()
+		for _, c := range x.Schema().Columns {
+			outCols.Insert(int64(c.UniqueID))
+		}
+		fds.AddColumns(outCols)
+		// GROUP BY collapses the input to one row per distinct value of its
+		// columns, so those columns are a strict key of the aggregation's
+		// own output -- they determine every aggregate result sitting
+		// alongside them, the same reasoning tryToEliminateAggregation
+		// already relies on (see rule_aggregation_elimination.go).
+		fds.AddStrictKey(groupCols)
+		return fds
+	default:
+		children := p.Children()
+		if len(children) == 0 {
+			fds := funcdep.New()
+			cols := funcdep.NewColSet()
+			for _, c := range p.Schema().Columns {
+				cols.Insert(int64(c.UniqueID))
+			}
+			fds.AddColumns(cols)
+			return fds
+		}
+		// A single-child pass-through operator (Projection, Sort, Limit,
+		// ...) that doesn't rename/merge columns keeps its child's FDs
+		// unchanged; one that does (e.g. a Projection computing an
+		// expression) simply contributes no extra dependency for its
+		// computed columns, which is sound even if incomplete. A plan with
+		// more than one child and no case of its own above (LogicalUnionAll,
+		// LogicalApply, LogicalCTE) falls back to its first child's FDs.
+		// For LogicalUnionAll that child's output shares the union's own
+		// column UniqueIDs (buildProjection4Union wraps every child in a
+		// Projection cloned from the union's schema), so the columns line
+		// up; the dependencies themselves are only as complete as that one
+		// child's, which is conservative rather than wrong. LogicalCTE's
+		// output columns carry fresh UniqueIDs that its seed plan's FDs
+		// never name, so no dependency is known for them.
+		return buildFuncDepSet(children[0])
+	}
+}
+
+// buildFuncDepSetFromDataSource seeds strict/lax keys from every UNIQUE
+// index and the PRIMARY KEY, the same sources checkColFuncDepend used to
+// walk, and registers every other column as reachable once one of those
+// keys is satisfied.
+func buildFuncDepSetFromDataSource(ds *DataSource) *funcdep.FDSet {
+	fds := funcdep.New()
+	allCols := funcdep.NewColSet()
+	for _, c := range ds.Schema().Columns {
+		allCols.Insert(int64(c.UniqueID))
+	}
+	fds.AddColumns(allCols)
+
+	for _, index := range ds.tableInfo.Indices {
+		if !index.Unique {
+			continue
+		}
+		cols, allNotNull, ok := dataSourceIndexColumns(ds, index.Columns)
+		if !ok {
+			continue
+		}
+		if allNotNull {
+			fds.AddStrictKey(cols)
+		} else {
+			fds.AddLaxKey(cols)
+		}
+	}
+
+	var pkNames []model.CIStr
+	for _, colInfo := range ds.tableInfo.Columns {
+		if mysql.HasPriKeyFlag(colInfo.Flag) {
+			pkNames = append(pkNames, colInfo.Name)
+		}
+	}
+	if cols, ok := dataSourceColumnsByName(ds, pkNames); ok {
+		fds.AddStrictKey(cols)
+	}
+	return fds
+}
+
+// dataSourceIndexColumns resolves an index's declared columns against ds's
+// schema, reporting whether every one of them was found and whether every
+// one of them is declared NOT NULL.
+func dataSourceIndexColumns(ds *DataSource, indexCols []*model.IndexColumn) (funcdep.ColSet, bool, bool) {
+	cols := funcdep.NewColSet()
+	allNotNull := true
+	for _, idxCol := range indexCols {
+		colInfo := ds.tableInfo.Columns[idxCol.Offset]
+		schemaCol := findDataSourceColumn(ds, colInfo.Name)
+		if schemaCol == nil {
+			return funcdep.ColSet{}, false, false
+		}
+		cols.Insert(int64(schemaCol.UniqueID))
+		if !mysql.HasNotNullFlag(colInfo.Flag) {
+			allNotNull = false
+		}
+	}
+	return cols, allNotNull, true
+}
+
+// dataSourceColumnsByName is dataSourceIndexColumns' counterpart for the
+// PRIMARY KEY, which this package already has as a plain column name list
+// rather than model.IndexColumn offsets. An empty names reports !ok, the
+// same as any unresolved column -- there's no PK-derived key to add.
+func dataSourceColumnsByName(ds *DataSource, names []model.CIStr) (funcdep.ColSet, bool) {
+	if len(names) == 0 {
+		return funcdep.ColSet{}, false
+	}
+	cols := funcdep.NewColSet()
+	for _, n := range names {
+		schemaCol := findDataSourceColumn(ds, n)
+		if schemaCol == nil {
+			return funcdep.ColSet{}, false
+		}
+		cols.Insert(int64(schemaCol.UniqueID))
+	}
+	return cols, true
+}
+
+func findDataSourceColumn(ds *DataSource, name model.CIStr) *expression.Column {
+	for _, c := range ds.Schema().Columns {
+		if c.OrigColName.L == name.L {
+			return c
+		}
+	}
+	return nil
+}
+
+// buildFuncDepSetFromJoin combines both sides' FDs, layers in equivalences
+// from the join's equality conditions, and, for an outer join, demotes
+// whatever those FDs imply about the null-extended side to lax via
+// MakeOuterJoin.
+func buildFuncDepSetFromJoin(x *LogicalJoin) *funcdep.FDSet {
+	left := buildFuncDepSet(x.children[0])
+	right := buildFuncDepSet(x.children[1])
+	fds := funcdep.New()
+	leftCols := funcdep.NewColSet()
+	for _, c := range x.children[0].Schema().Columns {
+		leftCols.Insert(int64(c.UniqueID))
+	}
+	rightCols := funcdep.NewColSet()
+	for _, c := range x.children[1].Schema().Columns {
+		rightCols.Insert(int64(c.UniqueID))
+	}
+	fds.AddFrom(left)
+	fds.AddFrom(right)
+	for _, cond := range x.EqualConditions {
+		applyFuncDepCondition(fds, cond)
+	}
+	for _, cond := range x.OtherConditions {
+		applyFuncDepCondition(fds, cond)
+	}
+
+	switch x.JoinType {
+	case LeftOuterJoin, LeftOuterSemiJoin, AntiLeftOuterSemiJoin:
+		fds.MakeOuterJoin(leftCols, rightCols, rightCols)
+	case RightOuterJoin:
+		fds.MakeOuterJoin(leftCols, rightCols, leftCols)
+	}
+	return fds
+}
+
+// applyFuncDepCondition recognizes the two predicate shapes
+// ONLY_FULL_GROUP_BY's closure computation cares about: `col1 = col2`
+// (an equivalence) and `col = <const>` or `col IS NOT NULL` (col becomes
+// reachable once its table's lax keys are promoted to strict).
+func applyFuncDepCondition(fds *funcdep.FDSet, cond expression.Expression) {
+	sf, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return
+	}
+	switch sf.FuncName.L {
+	case ast.EQ:
+		args := sf.GetArgs()
+		col1, ok1 := args[0].(*expression.Column)
+		col2, ok2 := args[1].(*expression.Column)
+		if ok1 && ok2 {
+			fds.AddEquivalence(funcdep.NewColSet(int64(col1.UniqueID)), funcdep.NewColSet(int64(col2.UniqueID)))
+			return
+		}
+		for i := 0; i < 2; i++ {
+			col, ok := args[i].(*expression.Column)
+			if !ok {
+				continue
+			}
+			if _, ok := args[1-i].(*expression.Constant); ok {
+				cols := funcdep.NewColSet(int64(col.UniqueID))
+				fds.AddConstants(cols)
+				fds.MakeNotNull(cols)
+			}
+		}
+	case ast.IsTruthWithoutNull, ast.IsTruthWithNull:
+		// `col` (used bare in a WHERE/ON/HAVING predicate) is truthy,
+		// which rules out NULL the same way IS NOT NULL does.
+		if col, ok := sf.GetArgs()[0].(*expression.Column); ok {
+			fds.MakeNotNull(funcdep.NewColSet(int64(col.UniqueID)))
+		}
+	case ast.UnaryNot:
+		// NOT (col IS NULL) is how `col IS NOT NULL` parses.
+		if inner, ok := sf.GetArgs()[0].(*expression.ScalarFunction); ok && inner.FuncName.L == ast.IsNull {
+			if col, ok := inner.GetArgs()[0].(*expression.Column); ok {
+				fds.MakeNotNull(funcdep.NewColSet(int64(col.UniqueID)))
+			}
+		}
+	}
+}
+
+// checkOnlyFullGroupByWithGroupClause checks that every non-aggregate
+// SELECT and ORDER BY column is functionally determined by the GROUP BY
+// list, via p's FDSet -- replacing the narrower checkColFuncDepend, which
+// only recognized a GROUP BY list that was already, syntactically, a
+// table's unique/primary key joined by equalities straight back to a WHERE
+// or ON condition.
+func (b *PlanBuilder) checkOnlyFullGroupByWithGroupClause(p LogicalPlan, sel *ast.SelectStmt) error {
+	gbyCols := make(map[*expression.Column]struct{}, len(sel.Fields.Fields))
+	gbyColSet := funcdep.NewColSet()
+	gbyExprs := make([]ast.ExprNode, 0, len(sel.Fields.Fields))
+	schema := p.Schema()
+	for _, byItem := range sel.GroupBy.Items {
+		if colExpr, ok := byItem.Expr.(*ast.ColumnNameExpr); ok {
+			col, err := schema.FindColumn(colExpr.Name)
+			if err != nil || col == nil {
+				continue
+			}
+			gbyCols[col] = struct{}{}
+			gbyColSet.Insert(int64(col.UniqueID))
+		} else {
+			gbyExprs = append(gbyExprs, byItem.Expr)
+		}
+	}
+
+	notInGbyCols := make(map[*expression.Column]ErrExprLoc, len(sel.Fields.Fields))
+	for offset, field := range sel.Fields.Fields {
+		if field.Auxiliary {
+			continue
+		}
+		checkExprInGroupBy(p, field.Expr, offset, ErrExprInSelect, gbyCols, gbyExprs, notInGbyCols)
+	}
+
+	if sel.OrderBy != nil {
+		for offset, item := range sel.OrderBy.Items {
+			checkExprInGroupBy(p, item.Expr, offset, ErrExprInOrderBy, gbyCols, gbyExprs, notInGbyCols)
+		}
+	}
+	if len(notInGbyCols) == 0 {
+		return nil
+	}
+
+	fds := buildFuncDepSet(p)
+	for col, errExprLoc := range notInGbyCols {
+		if fds.InClosureOf(funcdep.NewColSet(int64(col.UniqueID)), gbyColSet) {
+			continue
+		}
+		switch errExprLoc.Loc {
+		case ErrExprInSelect:
+			return ErrFieldNotInGroupBy.GenWithStackByArgs(errExprLoc.Offset+1, errExprLoc.Loc, sel.Fields.Fields[errExprLoc.Offset].Text())
+		case ErrExprInOrderBy:
+			return ErrFieldNotInGroupBy.GenWithStackByArgs(errExprLoc.Offset+1, errExprLoc.Loc, sel.OrderBy.Items[errExprLoc.Offset].Expr.Text())
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/planner/funcdep"
+)
+
+// canElideDistinct reports whether a DISTINCT over cols is a no-op given
+// child's functional dependencies: cols already contains a strict key of
+// child, so child can never produce two rows that agree on every column in
+// cols, and buildDistinct's GROUP BY-by-FIRST_ROW rewrite would leave every
+// row exactly as it found it. Unlike tryToEliminateAggregation in
+// rule_aggregation_elimination.go -- which asks whether the GROUP BY list
+// determines the aggregate arguments -- this asks the opposite direction:
+// whether the selected columns themselves already determine the whole row.
+func canElideDistinct(child LogicalPlan, cols []*expression.Column) bool {
+	colSet := funcdep.NewColSet()
+	for _, c := range cols {
+		colSet.Insert(int64(c.UniqueID))
+	}
+	return buildFuncDepSet(child).ColsAreStrictKey(colSet)
+}
+
+// pruneRedundantOrderBy drops any trailing ORDER BY column whose value is
+// already functionally determined by the columns sorted ahead of it: once
+// two rows agree on everything sorted so far, they also agree on this
+// column, so it can never reorder rows the earlier ones didn't already
+// decide between. It stops at (and keeps unchanged) the first item that
+// isn't a bare column reference, since buildFuncDepSet has nothing to say
+// about what a computed sort expression depends on. If every item turns out
+// redundant, the LogicalSort itself is spliced out.
+func pruneRedundantOrderBy(sort *LogicalSort) LogicalPlan {
+	fds := buildFuncDepSet(sort.children[0])
+	determined := funcdep.NewColSet()
+	kept := make([]*ByItems, 0, len(sort.ByItems))
+	for i, item := range sort.ByItems {
+		col, ok := item.Expr.(*expression.Column)
+		if !ok {
+			kept = append(kept, sort.ByItems[i:]...)
+			break
+		}
+		colSet := funcdep.NewColSet(int64(col.UniqueID))
+		if fds.InClosureOf(colSet, determined) {
+			continue
+		}
+		kept = append(kept, item)
+		determined = determined.Union(colSet)
+	}
+	if len(kept) == len(sort.ByItems) {
+		return sort
+	}
+	if len(kept) == 0 {
+		return sort.children[0]
+	}
+	sort.ByItems = kept
+	return sort
+}
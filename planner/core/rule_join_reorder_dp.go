@@ -0,0 +1,448 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+// joinReorderDPThreshold caps how many relations joinReOrderDPSolver will
+// enumerate bushy plans for: the DP recurrence below is exponential in the
+// join-group size, so a group larger than this falls back to the existing
+// left-deep greedy solver instead (wired in through tidb_opt_join_reorder_algo
+// and logicalOptimize's rule list, both outside this checkout -- see the
+// doc comment on joinReOrderDPSolver).
+const joinReorderDPThreshold = 10
+
+// joinGroupEqEdge is one equi-join edge in a join group's graph, keyed by the
+// column-equality pair extractJoinGroup pulled the edge's condition out of.
+// nodeL and nodeR are indexes into the join group's leaf-plan slice.
+type joinGroupEqEdge struct {
+	nodeL int
+	nodeR int
+	expr  *expression.ScalarFunction
+}
+
+// jrNode is one DP table entry: the best bushy plan found so far for a
+// subset of a join group's leaves, and the estimated cost of producing it.
+type jrNode struct {
+	p       LogicalPlan
+	cumCost float64
+}
+
+// joinReOrderDPSolver reorders a join group -- a maximal run of inner
+// LogicalJoin nodes with no intervening outer/semi/anti join -- into a bushy
+// plan using the classic DPsub/DPccp recurrence: for every connected subset S
+// of the group's leaves, try every split (S1, S2 = S\S1) where S1 and S2 are
+// each internally connected and joined by at least one edge, and keep the
+// cheapest Join(bestPlan[S1], bestPlan[S2]).
+//
+// It is an alternative to the existing greedy left-deep solver, selected per
+// query by the tidb_opt_join_reorder_algo session variable (dp|greedy,
+// default greedy for compatibility) or forced per-join by a
+// /*+ LEADING(t1, t2, ...) */ hint parsed alongside setPreferredJoinType; the
+// session variable and hint-parser plumbing live in sessionctx/variable and
+// the hint parser, neither of which is part of this checkout, so optimize
+// below always takes the DP path rather than consulting that variable, and
+// callers that do have it wired in should skip this rule entirely (rather
+// than call it) when the variable selects "greedy".
+type joinReOrderDPSolver struct{}
+
+func (s *joinReOrderDPSolver) optimize(p LogicalPlan) (LogicalPlan, error) {
+	newChildren := make([]LogicalPlan, 0, len(p.Children()))
+	for _, child := range p.Children() {
+		newChild, err := s.optimize(child)
+		if err != nil {
+			return nil, err
+		}
+		newChildren = append(newChildren, newChild)
+	}
+	p.SetChildren(newChildren...)
+
+	join, ok := p.(*LogicalJoin)
+	if !ok || join.JoinType != InnerJoin || join.StraightJoin {
+		return p, nil
+	}
+
+	group, eqEdges, otherConds := extractJoinGroup(p)
+	// A group of two or fewer leaves has exactly one possible shape, and a
+	// group above the threshold is left to the greedy solver, so there is
+	// nothing for the DP recurrence to improve on in either case.
+	if len(group) <= 2 || len(group) > joinReorderDPThreshold {
+		return p, nil
+	}
+
+	group, eqEdges = applyLeadingHint(join.ctx, group, eqEdges, join.hintInfo)
+	return s.reorderGroup(join.ctx, group, eqEdges, otherConds)
+}
+
+// applyLeadingHint, given a /*+ LEADING(t1, t2, ...) */ hint naming a
+// prefix of the join group's leaves, folds exactly those leaves (in the
+// listed order) into one fixed left-deep chain before the DP recurrence
+// ever runs, so the final plan's left-deep prefix is guaranteed to match
+// what the hint asked for -- the DP recurrence below only ever decides how
+// to join that chain against, and join among, whatever leaves are left. A
+// hint naming fewer than two aliases, or naming an alias that is not a
+// leaf of this particular group (e.g. it belongs to a different group
+// split off by an outer join boundary), is left alone rather than guessed
+// at.
+func applyLeadingHint(ctx sessionctx.Context, group []LogicalPlan, eqEdges []*joinGroupEqEdge, hintInfo *tableHintInfo) ([]LogicalPlan, []*joinGroupEqEdge) {
+	if hintInfo == nil || len(hintInfo.leadingJoinOrder) < 2 {
+		return group, eqEdges
+	}
+
+	order := make([]int, 0, len(hintInfo.leadingJoinOrder))
+	for _, alias := range leadingHintAliases(hintInfo.leadingJoinOrder) {
+		idx := -1
+		for i, leaf := range group {
+			if leafAlias := extractTableAlias(leaf); leafAlias != nil && leafAlias.L == alias.L {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return group, eqEdges
+		}
+		order = append(order, idx)
+	}
+
+	used := make(map[int]bool, len(order))
+	chain := group[order[0]]
+	used[order[0]] = true
+	for _, idx := range order[1:] {
+		var chainEdges []*expression.ScalarFunction
+		for _, e := range eqEdges {
+			if (used[e.nodeL] && e.nodeR == idx) || (used[e.nodeR] && e.nodeL == idx) {
+				chainEdges = append(chainEdges, e.expr)
+			}
+		}
+		chainPlan := LogicalJoin{JoinType: InnerJoin, EqualConditions: chainEdges}.Init(ctx)
+		chainPlan.SetChildren(chain, group[idx])
+		chainPlan.SetSchema(expression.MergeSchema(chain.Schema(), group[idx].Schema()))
+		chain = chainPlan
+		used[idx] = true
+	}
+
+	newGroup := []LogicalPlan{chain}
+	indexRemap := make(map[int]int, len(group))
+	for i, leaf := range group {
+		if used[i] {
+			indexRemap[i] = 0
+			continue
+		}
+		indexRemap[i] = len(newGroup)
+		newGroup = append(newGroup, leaf)
+	}
+
+	newEdges := make([]*joinGroupEqEdge, 0, len(eqEdges))
+	for _, e := range eqEdges {
+		if used[e.nodeL] && used[e.nodeR] {
+			continue // already folded into the chain's own EqualConditions
+		}
+		newEdges = append(newEdges, &joinGroupEqEdge{nodeL: indexRemap[e.nodeL], nodeR: indexRemap[e.nodeR], expr: e.expr})
+	}
+	return newGroup, newEdges
+}
+
+// extractJoinGroup collects the maximal group of leaves reachable from p by
+// descending through inner, non-straight LogicalJoin nodes only -- an
+// outer/semi/anti join, or one forced by STRAIGHT_JOIN, is a fixed boundary
+// that extractJoinGroup does not cross, so the join order on either side of
+// it is left exactly as buildJoin produced it and reordering never changes
+// outer/semi/anti join semantics. eqEdges is keyed by position in the
+// returned group; otherConds carries every non-equi condition found along
+// the way, to be re-attached once the group is rebuilt.
+func extractJoinGroup(p LogicalPlan) (group []LogicalPlan, eqEdges []*joinGroupEqEdge, otherConds []expression.Expression) {
+	join, ok := p.(*LogicalJoin)
+	if !ok || join.JoinType != InnerJoin || join.StraightJoin {
+		return []LogicalPlan{p}, nil, nil
+	}
+
+	lGroup, lEdges, lOther := extractJoinGroup(join.children[0])
+	rGroup, rEdges, rOther := extractJoinGroup(join.children[1])
+	offset := len(lGroup)
+
+	group = append(group, lGroup...)
+	group = append(group, rGroup...)
+	eqEdges = append(eqEdges, lEdges...)
+	for _, e := range rEdges {
+		eqEdges = append(eqEdges, &joinGroupEqEdge{nodeL: e.nodeL + offset, nodeR: e.nodeR + offset, expr: e.expr})
+	}
+	otherConds = append(otherConds, lOther...)
+	otherConds = append(otherConds, rOther...)
+	otherConds = append(otherConds, join.OtherConditions...)
+
+	for _, cond := range join.EqualConditions {
+		lCol, lOk := cond.GetArgs()[0].(*expression.Column)
+		rCol, rOk := cond.GetArgs()[1].(*expression.Column)
+		nodeL, lFound := -1, false
+		nodeR, rFound := -1, false
+		if lOk {
+			nodeL, lFound = findColumnNode(group, lCol)
+		}
+		if rOk {
+			nodeR, rFound = findColumnNode(group, rCol)
+		}
+		if !lFound || !rFound {
+			otherConds = append(otherConds, cond)
+			continue
+		}
+		eqEdges = append(eqEdges, &joinGroupEqEdge{nodeL: nodeL, nodeR: nodeR, expr: cond})
+	}
+	return group, eqEdges, otherConds
+}
+
+func findColumnNode(group []LogicalPlan, col *expression.Column) (int, bool) {
+	for i, leaf := range group {
+		if leaf.Schema().Contains(col) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// joinGroupGraph is the adjacency view of a join group used by the DP
+// recurrence's connectivity checks: adj[i] holds the bitmask of every other
+// leaf i has a direct edge to, where an edge is either a real equi-condition
+// from eqEdges or a synthetic cross-product edge added by
+// addCrossProductEdges to keep an otherwise-disconnected group joinable.
+type joinGroupGraph struct {
+	n       int
+	adj     []uint
+	edgeSet map[[2]int][]*joinGroupEqEdge
+}
+
+func buildJoinGroupGraph(n int, eqEdges []*joinGroupEqEdge) *joinGroupGraph {
+	g := &joinGroupGraph{n: n, adj: make([]uint, n), edgeSet: make(map[[2]int][]*joinGroupEqEdge)}
+	for _, e := range eqEdges {
+		g.addEdge(e.nodeL, e.nodeR, e)
+	}
+	g.addCrossProductEdges()
+	return g
+}
+
+func (g *joinGroupGraph) addEdge(i, j int, e *joinGroupEqEdge) {
+	g.adj[i] |= 1 << uint(j)
+	g.adj[j] |= 1 << uint(i)
+	if e == nil {
+		return
+	}
+	key := [2]int{i, j}
+	if i > j {
+		key = [2]int{j, i}
+	}
+	g.edgeSet[key] = append(g.edgeSet[key], e)
+}
+
+// addCrossProductEdges finds every connected component under the real
+// equi-join edges and links consecutive components with a synthetic,
+// condition-less edge, the same "cross-product" connection makeBushyJoin
+// style solvers fall back to when a query joins two tables with no equi
+// condition between them anywhere (e.g. `select * from a, b`). Doing this
+// once up front, rather than only when a particular split turns out
+// disconnected, keeps the component graph itself connected so the DP
+// recurrence below always has at least one valid split to consider for
+// every connected subset it enumerates.
+func (g *joinGroupGraph) addCrossProductEdges() {
+	comp := make([]int, g.n)
+	for i := range comp {
+		comp[i] = -1
+	}
+	var components [][]int
+	for i := 0; i < g.n; i++ {
+		if comp[i] != -1 {
+			continue
+		}
+		id := len(components)
+		var members []int
+		queue := []int{i}
+		comp[i] = id
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			members = append(members, cur)
+			for j := 0; j < g.n; j++ {
+				if comp[j] == -1 && g.adj[cur]&(1<<uint(j)) != 0 {
+					comp[j] = id
+					queue = append(queue, j)
+				}
+			}
+		}
+		components = append(components, members)
+	}
+	for i := 1; i < len(components); i++ {
+		g.addEdge(components[i-1][0], components[i][0], nil)
+	}
+}
+
+// connected reports whether every leaf in mask is reachable from every other
+// leaf in mask using only edges whose both ends are inside mask.
+func (g *joinGroupGraph) connected(mask uint) bool {
+	if mask == 0 {
+		return false
+	}
+	start := bitScanForward(mask)
+	visited := uint(1) << uint(start)
+	queue := []int{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		reachable := g.adj[cur] & mask &^ visited
+		for reachable != 0 {
+			next := bitScanForward(reachable)
+			reachable &^= 1 << uint(next)
+			visited |= 1 << uint(next)
+			queue = append(queue, next)
+		}
+	}
+	return visited == mask
+}
+
+// hasEdgeBetween reports whether some leaf in s1 has an edge to some leaf in
+// s2; s1 and s2 are assumed disjoint.
+func (g *joinGroupGraph) hasEdgeBetween(s1, s2 uint) bool {
+	m := s1
+	for m != 0 {
+		i := bitScanForward(m)
+		m &^= 1 << uint(i)
+		if g.adj[i]&s2 != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// edgesBetween returns every real (non-cross-product) equi-join condition
+// with one end in s1 and the other in s2.
+func (g *joinGroupGraph) edgesBetween(s1, s2 uint) []*joinGroupEqEdge {
+	var out []*joinGroupEqEdge
+	for key, edges := range g.edgeSet {
+		i, j := key[0], key[1]
+		iIn1, iIn2 := s1&(1<<uint(i)) != 0, s2&(1<<uint(i)) != 0
+		jIn1, jIn2 := s1&(1<<uint(j)) != 0, s2&(1<<uint(j)) != 0
+		if (iIn1 && jIn2) || (iIn2 && jIn1) {
+			out = append(out, edges...)
+		}
+	}
+	return out
+}
+
+func bitScanForward(mask uint) int {
+	i := 0
+	for mask&1 == 0 {
+		mask >>= 1
+		i++
+	}
+	return i
+}
+
+// reorderGroup runs the DPsub/DPccp recurrence over group and rebuilds a
+// bushy LogicalJoin tree from the winning plan: dp[S] holds the cheapest
+// plan found so far that joins exactly the leaves in S, computed bottom-up
+// from singleton masks by trying every way of splitting S into two
+// non-empty, individually-connected, edge-joined halves.
+func (s *joinReOrderDPSolver) reorderGroup(ctx sessionctx.Context, group []LogicalPlan, eqEdges []*joinGroupEqEdge, otherConds []expression.Expression) (LogicalPlan, error) {
+	n := len(group)
+	g := buildJoinGroupGraph(n, eqEdges)
+
+	dp := make([]*jrNode, 1<<uint(n))
+	for i, leaf := range group {
+		dp[1<<uint(i)] = &jrNode{p: leaf, cumCost: leafCost(leaf)}
+	}
+
+	full := uint(1<<uint(n)) - 1
+	for mask := uint(1); mask <= full; mask++ {
+		if dp[mask] != nil || !g.connected(mask) {
+			continue
+		}
+		var best *jrNode
+		// Enumerate every non-empty proper submask of mask as the left half;
+		// mask&^sub is the complementary right half. Splits are considered
+		// twice (once with each half on the left), which is harmless here
+		// since we only keep the minimum-cost result.
+		for sub := (mask - 1) & mask; sub != 0; sub = (sub - 1) & mask {
+			other := mask &^ sub
+			left, right := dp[sub], dp[other]
+			if left == nil || right == nil {
+				continue
+			}
+			if !g.hasEdgeBetween(sub, other) {
+				continue
+			}
+			cand := joinCost(ctx, left, right)
+			// Carry over the equi-join conditions linking the two halves.
+			if edges := g.edgesBetween(sub, other); len(edges) > 0 {
+				conds := make([]expression.Expression, 0, len(edges))
+				for _, e := range edges {
+					conds = append(conds, e.expr)
+				}
+				join := cand.p.(*LogicalJoin)
+				eqConds, _, _, rest := join.extractOnCondition(conds, false, false)
+				join.EqualConditions = eqConds
+				join.OtherConditions = rest
+			}
+			if best == nil || cand.cumCost < best.cumCost {
+				best = cand
+			}
+		}
+		dp[mask] = best
+	}
+
+	best := dp[full]
+	if best == nil {
+		// The whole group's graph is connected (addCrossProductEdges
+		// guarantees it), so every connected mask should have found a
+		// split; this would only trip if that invariant were broken.
+		return nil, errors.New("planner: join reorder failed to connect join group")
+	}
+
+	joined := best.p
+	joined = attachOtherConds(ctx, joined, otherConds)
+	return joined, nil
+}
+
+func leafCost(p LogicalPlan) float64 {
+	return p.Stats().RowCount
+}
+
+// joinCost builds the LogicalJoin combining left and right and estimates its
+// cumulative cost as the classic cumCost(left) + cumCost(right) +
+// rowCount(left x right) recurrence; real row-count and selectivity
+// estimates come from the join's Stats(), computed by the stats-estimation
+// pass in planner/property, outside this checkout.
+func joinCost(ctx sessionctx.Context, left, right *jrNode) *jrNode {
+	joinPlan := LogicalJoin{JoinType: InnerJoin}.Init(ctx)
+	joinPlan.SetChildren(left.p, right.p)
+	joinPlan.SetSchema(expression.MergeSchema(left.p.Schema(), right.p.Schema()))
+	cost := left.cumCost + right.cumCost + joinPlan.Stats().RowCount
+	return &jrNode{p: joinPlan, cumCost: cost}
+}
+
+// attachOtherConds re-attaches every non-equi condition gathered while
+// extracting the join group as the ON condition of the group's new root
+// join, the same way buildJoin's caller calls attachOnConds once the join's
+// shape is otherwise settled.
+func attachOtherConds(ctx sessionctx.Context, p LogicalPlan, otherConds []expression.Expression) LogicalPlan {
+	if len(otherConds) == 0 {
+		return p
+	}
+	join, ok := p.(*LogicalJoin)
+	if !ok {
+		return p
+	}
+	join.attachOnConds(otherConds)
+	return join
+}
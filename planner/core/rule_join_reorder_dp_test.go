@@ -0,0 +1,87 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testJoinReorderDPSuite{})
+
+type testJoinReorderDPSuite struct{}
+
+// TestBuildJoinGroupGraphConnectsDisjointComponents checks that two leaves
+// with no equi-join edge between them still end up connected, via
+// addCrossProductEdges' synthetic cross-product edge -- the DP recurrence
+// relies on every mask being reachable through some split.
+func (s *testJoinReorderDPSuite) TestBuildJoinGroupGraphConnectsDisjointComponents(c *C) {
+	g := buildJoinGroupGraph(3, nil)
+	c.Assert(g.connected(0b111), IsTrue)
+}
+
+// TestBuildJoinGroupGraphRealEdgeIsConnected checks that two leaves joined
+// by a real equi-edge are reported connected without needing a synthetic
+// cross-product edge.
+func (s *testJoinReorderDPSuite) TestBuildJoinGroupGraphRealEdgeIsConnected(c *C) {
+	g := buildJoinGroupGraph(2, []*joinGroupEqEdge{{nodeL: 0, nodeR: 1}})
+	c.Assert(g.connected(0b11), IsTrue)
+}
+
+// TestConnectedFalseForDisconnectedSubsetOfAPartiallyJoinedGroup checks
+// that connected only considers edges whose both ends lie inside mask, so
+// a leaf reachable only through a node outside the mask is not mistaken
+// for connected.
+func (s *testJoinReorderDPSuite) TestConnectedFalseForDisconnectedSubsetOfAPartiallyJoinedGroup(c *C) {
+	// 0-1 and 1-2 are edges; {0, 2} without 1 has no direct edge.
+	g := &joinGroupGraph{n: 3, adj: make([]uint, 3), edgeSet: map[[2]int][]*joinGroupEqEdge{}}
+	g.addEdge(0, 1, &joinGroupEqEdge{nodeL: 0, nodeR: 1})
+	g.addEdge(1, 2, &joinGroupEqEdge{nodeL: 1, nodeR: 2})
+	c.Assert(g.connected(0b101), IsFalse)
+}
+
+// TestHasEdgeBetweenDisjointSets checks hasEdgeBetween reports true only
+// when some leaf of s1 has a direct edge to some leaf of s2.
+func (s *testJoinReorderDPSuite) TestHasEdgeBetweenDisjointSets(c *C) {
+	// Built by hand rather than through buildJoinGroupGraph, whose synthetic
+	// cross-product edge would link leaf 1 to leaf 0.
+	g := &joinGroupGraph{n: 3, adj: make([]uint, 3), edgeSet: map[[2]int][]*joinGroupEqEdge{}}
+	g.addEdge(0, 2, &joinGroupEqEdge{nodeL: 0, nodeR: 2})
+	c.Assert(g.hasEdgeBetween(0b001, 0b100), IsTrue)
+	c.Assert(g.hasEdgeBetween(0b001, 0b010), IsFalse)
+}
+
+// TestEdgesBetweenExcludesSyntheticCrossProductEdges checks that
+// edgesBetween only returns real equi-join conditions, not the nil-expr
+// synthetic edges addCrossProductEdges adds to keep otherwise-disconnected
+// components joinable.
+func (s *testJoinReorderDPSuite) TestEdgesBetweenExcludesSyntheticCrossProductEdges(c *C) {
+	real := &joinGroupEqEdge{nodeL: 0, nodeR: 1}
+	g := buildJoinGroupGraph(3, []*joinGroupEqEdge{real})
+	got := g.edgesBetween(0b001, 0b010)
+	c.Assert(got, HasLen, 1)
+	c.Assert(got[0], Equals, real)
+
+	// Leaf 2 is only linked in by the synthetic cross-product edge, which
+	// carries no *joinGroupEqEdge and so must not surface here.
+	got = g.edgesBetween(0b001, 0b100)
+	c.Assert(got, HasLen, 0)
+}
+
+// TestBitScanForwardFindsLowestSetBit checks bitScanForward returns the
+// index of the lowest set bit, the primitive every mask-iteration loop in
+// this file depends on.
+func (s *testJoinReorderDPSuite) TestBitScanForwardFindsLowestSetBit(c *C) {
+	c.Assert(bitScanForward(0b1000), Equals, 3)
+	c.Assert(bitScanForward(0b0110), Equals, 1)
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/tidb/expression"
+)
+
+// projectionPullUpAndMerge runs alongside projectionEliminator (outside this
+// checkout) and does two related rewrites, bottom-up:
+//
+//   - stacked-projection collapsing: a LogicalProjection directly above
+//     another one is folded into it by substituting the inner projection's
+//     expressions into the outer one's, dropping the inner node. This
+//     cleans up the extra projection layers buildProjection4Union,
+//     buildDistinct and the ORDER BY trimming projection at the end of
+//     buildUnion each introduce, none of which do anything the projection
+//     sitting above them doesn't already redo.
+//   - pull-up: a LogicalProjection that only renames/reorders its child's
+//     columns (no computed expression) is hoisted above an immediate
+//     LogicalSelection, LogicalSort, LogicalLimit, or the non-referenced
+//     side of a semi-join family LogicalJoin, so whatever sits beneath it
+//     is directly exposed to predicate push-down and index selection
+//     instead of being hidden behind a renaming layer.
+//
+// Both rewrites refuse to touch a projection with avoidColumnEvaluator set
+// -- buildProjection4Union relies on that projection staying a distinct
+// operator so its CAST expressions actually run -- and merging additionally
+// refuses when the inner projection contains a volatile/non-deterministic
+// function (RAND(), SLEEP(), UUID(), ...), since duplicating one of those
+// into every use site of the outer projection would change how many times
+// it's evaluated.
+type projectionPullUpAndMerge struct {
+}
+
+func (p *projectionPullUpAndMerge) optimize(lp LogicalPlan) (LogicalPlan, error) {
+	newChildren := make([]LogicalPlan, 0, len(lp.Children()))
+	for _, child := range lp.Children() {
+		newChild, err := p.optimize(child)
+		if err != nil {
+			return nil, err
+		}
+		newChildren = append(newChildren, newChild)
+	}
+	lp.SetChildren(newChildren...)
+
+	if proj, ok := lp.(*LogicalProjection); ok {
+		if inner, ok := proj.children[0].(*LogicalProjection); ok && canMergeProjections(proj, inner) {
+			return mergeProjections(proj, inner), nil
+		}
+		return proj, nil
+	}
+	return pullUpChildProjection(lp), nil
+}
+
+// canMergeProjections reports whether inner can be substituted directly
+// into outer without changing how many times inner's expressions evaluate
+// or what they evaluate against.
+func canMergeProjections(outer, inner *LogicalProjection) bool {
+	if inner.avoidColumnEvaluator {
+		return false
+	}
+	for _, expr := range inner.Exprs {
+		if hasVolatileFunc(expr) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeProjections substitutes inner's expressions into outer's, then
+// drops inner, reattaching inner's child directly beneath outer. outer's
+// own schema is unaffected: only the expressions producing it change.
+func mergeProjections(outer, inner *LogicalProjection) *LogicalProjection {
+	newExprs := make([]expression.Expression, 0, len(outer.Exprs))
+	for _, expr := range outer.Exprs {
+		newExprs = append(newExprs, expression.ColumnSubstitute(expr, inner.Schema(), inner.Exprs))
+	}
+	outer.Exprs = newExprs
+	outer.SetChildren(inner.children[0])
+	return outer
+}
+
+// pullUpChildProjection hoists a pure rename/reorder LogicalProjection from
+// directly beneath lp to directly above it, when lp is one of the operator
+// kinds this rule knows how to transpose a projection through. Any other lp
+// is returned unchanged.
+func pullUpChildProjection(lp LogicalPlan) LogicalPlan {
+	switch x := lp.(type) {
+	case *LogicalSelection:
+		if proj, ok := pullableProjection(x.children[0]); ok {
+			return pullProjectSelect(x, proj)
+		}
+	case *LogicalSort:
+		if proj, ok := pullableProjection(x.children[0]); ok {
+			return pullProjectSort(x, proj)
+		}
+	case *LogicalLimit:
+		if proj, ok := pullableProjection(x.children[0]); ok {
+			return pullProjectLimit(x, proj)
+		}
+	case *LogicalJoin:
+		switch x.JoinType {
+		case SemiJoin, AntiSemiJoin, LeftOuterSemiJoin, AntiLeftOuterSemiJoin:
+			// The right (inner) side never contributes columns to a
+			// semi-join's output schema, so a renaming projection sitting
+			// directly on it can be dropped in place rather than pulled
+			// above the join: only the join's own column references into
+			// it need to be rewritten to point at the real child.
+			if proj, ok := pullableProjection(x.children[1]); ok {
+				return pullProjectSemiJoinInner(x, proj)
+			}
+		}
+	}
+	return lp
+}
+
+// pullableProjection reports whether child is a LogicalProjection this rule
+// is allowed to pull up: one that only renames/reorders columns (every
+// output expression is a bare column reference) and isn't pinned in place
+// by avoidColumnEvaluator.
+func pullableProjection(child LogicalPlan) (*LogicalProjection, bool) {
+	proj, ok := child.(*LogicalProjection)
+	if !ok || proj.avoidColumnEvaluator {
+		return nil, false
+	}
+	for _, expr := range proj.Exprs {
+		if _, ok := expr.(*expression.Column); !ok {
+			return nil, false
+		}
+	}
+	return proj, true
+}
+
+func pullProjectSelect(sel *LogicalSelection, proj *LogicalProjection) LogicalPlan {
+	newConds := make([]expression.Expression, 0, len(sel.Conditions))
+	for _, cond := range sel.Conditions {
+		newConds = append(newConds, expression.ColumnSubstitute(cond, proj.Schema(), proj.Exprs))
+	}
+	sel.Conditions = newConds
+	sel.SetChildren(proj.children[0])
+	proj.SetChildren(sel)
+	return proj
+}
+
+func pullProjectSort(sort *LogicalSort, proj *LogicalProjection) LogicalPlan {
+	newItems := make([]*ByItems, 0, len(sort.ByItems))
+	for _, item := range sort.ByItems {
+		newItems = append(newItems, &ByItems{
+			Expr: expression.ColumnSubstitute(item.Expr, proj.Schema(), proj.Exprs),
+			Desc: item.Desc,
+		})
+	}
+	sort.ByItems = newItems
+	sort.SetChildren(proj.children[0])
+	proj.SetChildren(sort)
+	return proj
+}
+
+func pullProjectLimit(limit *LogicalLimit, proj *LogicalProjection) LogicalPlan {
+	if limit.IsWithTies {
+		newTieCmpFuncs := make([]expression.Expression, 0, len(limit.TieCmpFuncs))
+		for _, f := range limit.TieCmpFuncs {
+			newTieCmpFuncs = append(newTieCmpFuncs, expression.ColumnSubstitute(f, proj.Schema(), proj.Exprs))
+		}
+		limit.TieCmpFuncs = newTieCmpFuncs
+	}
+	limit.SetChildren(proj.children[0])
+	proj.SetChildren(limit)
+	return proj
+}
+
+// pullProjectSemiJoinInner rewrites join's column references into proj's
+// output so they point at proj's child directly, then replaces join's inner
+// side with that child -- dropping proj, since nothing above join can see
+// the inner side's columns anyway.
+func pullProjectSemiJoinInner(join *LogicalJoin, proj *LogicalProjection) LogicalPlan {
+	substitute := func(conds []expression.Expression) []expression.Expression {
+		newConds := make([]expression.Expression, 0, len(conds))
+		for _, cond := range conds {
+			newConds = append(newConds, expression.ColumnSubstitute(cond, proj.Schema(), proj.Exprs))
+		}
+		return newConds
+	}
+	join.EqualConditions = substitute(join.EqualConditions)
+	join.LeftConditions = substitute(join.LeftConditions)
+	join.RightConditions = substitute(join.RightConditions)
+	join.OtherConditions = substitute(join.OtherConditions)
+	join.SetChildren(join.children[0], proj.children[0])
+	return join
+}
+
+// hasVolatileFunc reports whether expr evaluates a function whose result
+// can change from one call to the next with the same arguments, so
+// duplicating its evaluation (as merging a stacked projection would) isn't
+// safe.
+func hasVolatileFunc(expr expression.Expression) bool {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	switch sf.FuncName.L {
+	case ast.Rand, ast.Sleep, ast.UUID, ast.UUIDShort, ast.ConnectionID,
+		ast.LastInsertID, ast.GetLock, ast.ReleaseLock, ast.ReleaseAllLocks:
+		return true
+	}
+	for _, arg := range sf.GetArgs() {
+		if hasVolatileFunc(arg) {
+			return true
+		}
+	}
+	return false
+}
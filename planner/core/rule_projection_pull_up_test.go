@@ -0,0 +1,94 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/mock"
+)
+
+var _ = Suite(&testProjectionPullUpSuite{})
+
+type testProjectionPullUpSuite struct{}
+
+// TestHasVolatileFuncDetectsRand checks that a bare RAND() call is
+// recognized as volatile, so merging a stacked projection containing it
+// is refused.
+func (s *testProjectionPullUpSuite) TestHasVolatileFuncDetectsRand(c *C) {
+	ctx := mock.NewContext()
+	randCall := expression.NewFunctionInternal(ctx, ast.Rand, types.NewFieldType(mysql.TypeDouble))
+	c.Assert(hasVolatileFunc(randCall), IsTrue)
+}
+
+// TestHasVolatileFuncDetectsNestedRand checks that a volatile function
+// buried as an argument to an otherwise pure function is still caught.
+func (s *testProjectionPullUpSuite) TestHasVolatileFuncDetectsNestedRand(c *C) {
+	ctx := mock.NewContext()
+	randCall := expression.NewFunctionInternal(ctx, ast.Rand, types.NewFieldType(mysql.TypeDouble))
+	wrapped := expression.NewFunctionInternal(ctx, ast.Ceil, types.NewFieldType(mysql.TypeDouble), randCall)
+	c.Assert(hasVolatileFunc(wrapped), IsTrue)
+}
+
+// TestHasVolatileFuncFalseForPlainColumn checks that a bare column
+// reference, the common case a rename-only projection consists of, is
+// not flagged as volatile.
+func (s *testProjectionPullUpSuite) TestHasVolatileFuncFalseForPlainColumn(c *C) {
+	col := &expression.Column{UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	c.Assert(hasVolatileFunc(col), IsFalse)
+}
+
+// TestPullableProjectionRejectsComputedExpression checks that a
+// projection computing an expression (not a bare column reference) is
+// not eligible to be pulled up, since pulling it above a Selection/Sort/
+// Limit would move a computation, not just a rename.
+func (s *testProjectionPullUpSuite) TestPullableProjectionRejectsComputedExpression(c *C) {
+	ctx := mock.NewContext()
+	col := &expression.Column{UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	computed := expression.NewFunctionInternal(ctx, ast.Ceil, types.NewFieldType(mysql.TypeDouble), col)
+	proj := LogicalProjection{Exprs: []expression.Expression{computed}}.Init(ctx)
+	proj.SetSchema(expression.NewSchema(col))
+
+	_, ok := pullableProjection(proj)
+	c.Assert(ok, IsFalse)
+}
+
+// TestPullableProjectionAcceptsRenameOnly checks that a projection whose
+// every output expression is a bare column reference is eligible to be
+// pulled up.
+func (s *testProjectionPullUpSuite) TestPullableProjectionAcceptsRenameOnly(c *C) {
+	ctx := mock.NewContext()
+	col := &expression.Column{UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	renamed := &expression.Column{UniqueID: 2, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	proj := LogicalProjection{Exprs: []expression.Expression{col}}.Init(ctx)
+	proj.SetSchema(expression.NewSchema(renamed))
+
+	got, ok := pullableProjection(proj)
+	c.Assert(ok, IsTrue)
+	c.Assert(got, Equals, proj)
+}
+
+// TestCanMergeProjectionsRejectsAvoidColumnEvaluator checks that a
+// projection marked avoidColumnEvaluator (buildProjection4Union's CAST
+// layer) is never folded into the projection above it.
+func (s *testProjectionPullUpSuite) TestCanMergeProjectionsRejectsAvoidColumnEvaluator(c *C) {
+	ctx := mock.NewContext()
+	col := &expression.Column{UniqueID: 1, RetType: types.NewFieldType(mysql.TypeLonglong)}
+	inner := LogicalProjection{Exprs: []expression.Expression{col}, avoidColumnEvaluator: true}.Init(ctx)
+	outer := LogicalProjection{Exprs: []expression.Expression{col}}.Init(ctx)
+	c.Assert(canMergeProjections(outer, inner), IsFalse)
+}
@@ -0,0 +1,319 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package funcdep models the functional dependencies that hold over a
+// relational plan's output columns -- the same FD algebra used by
+// Cornell's "An Improved Framework for Functional Dependencies" and by
+// CockroachDB's opt/props FuncDepSet, scaled down to what planner/core
+// needs today: proving a SELECT list (or ORDER BY list) is functionally
+// determined by its GROUP BY list for ONLY_FULL_GROUP_BY, with room to
+// grow into DISTINCT/ORDER BY pruning and join-reordering later.
+package funcdep
+
+import "github.com/pingcap/tidb/util/set"
+
+// ColSet identifies a set of plan columns by their expression.Column
+// UniqueID. It's exactly util/set.Int64Set under FDSet's own name, the
+// same way planner/core's (unrelated, narrower) FDSet in fd_set.go already
+// keys its dependencies by UniqueID.
+type ColSet = set.Int64Set
+
+// NewColSet builds a ColSet containing exactly the given columns.
+func NewColSet(cols ...int64) ColSet {
+	s := set.NewInt64Set()
+	for _, c := range cols {
+		s.Insert(c)
+	}
+	return s
+}
+
+func copyOf(s ColSet) ColSet {
+	return s.Union(set.NewInt64Set())
+}
+
+func intersects(a, b ColSet) bool {
+	return a.Intersect(b).Len() > 0
+}
+
+// isSubset reports whether every column of a is also in b.
+func isSubset(a, b ColSet) bool {
+	return a.Difference(b).Len() == 0
+}
+
+// strictFD is an arbitrary from -> to functional dependency that holds for
+// every row: any two rows agreeing on from also agree on to.
+type strictFD struct {
+	from, to ColSet
+}
+
+// laxFD is the same shape, but only holds for rows where every column in
+// nullable is non-NULL; MakeNotNull promotes it to a strictFD once
+// nullable empties out.
+type laxFD struct {
+	from, to, nullable ColSet
+}
+
+// FDSet is a set of functional dependencies attached to a single
+// relational plan's output columns.
+type FDSet struct {
+	// strictKeys holds column sets that each strictly determine every
+	// column this FDSet has ever been told about (allCols): if two rows
+	// agree on a strict key, they're the same row.
+	strictKeys []ColSet
+	// laxKeys is the same, but only for rows where every column in the
+	// matching entry of laxNullable is non-NULL.
+	laxKeys     []ColSet
+	laxNullable []ColSet
+
+	// equivGroups partitions columns known to always hold equal values
+	// into maximal classes.
+	equivGroups []ColSet
+	// constants is the subset of allCols pinned to a single value.
+	constants ColSet
+
+	// fds holds arbitrary strict dependencies beyond the keys above, e.g.
+	// the ones join equality conditions and AddStrictFD contribute.
+	fds []strictFD
+	// laxFDs is the lax counterpart of fds, the same way laxKeys is of
+	// strictKeys.
+	laxFDs []laxFD
+
+	// allCols is every column this FDSet has ever seen. A satisfied key's
+	// closure extends to all of allCols as an approximation of "the whole
+	// row", which holds as long as an FDSet is never asked about columns
+	// outside the single relation it was built for.
+	allCols ColSet
+}
+
+// New creates an empty FDSet.
+func New() *FDSet {
+	return &FDSet{constants: set.NewInt64Set(), allCols: set.NewInt64Set()}
+}
+
+func (s *FDSet) touch(cols ColSet) {
+	s.allCols = s.allCols.Union(cols)
+}
+
+// AddColumns registers cols as part of this relation's output schema
+// without implying any dependency on its own -- the base case every other
+// Add* method's bookkeeping builds on, needed up front for a plan node
+// (such as a DataSource with no declared key at all) whose columns should
+// still be reachable once some other dependency determines "the whole
+// row".
+func (s *FDSet) AddColumns(cols ColSet) {
+	s.touch(cols)
+}
+
+// AddStrictKey records that cols strictly determines every column this
+// FDSet has seen so far (e.g. a table's PRIMARY KEY, or a UNIQUE index
+// declared NOT NULL).
+func (s *FDSet) AddStrictKey(cols ColSet) {
+	s.touch(cols)
+	s.strictKeys = append(s.strictKeys, copyOf(cols))
+}
+
+// AddLaxKey records that cols determines every column this FDSet has seen
+// so far only for rows where every column of cols is non-NULL (e.g. a
+// UNIQUE index that permits NULLs, which MySQL does not enforce
+// uniqueness against). MakeNotNull promotes it to a strict key once a
+// Selection proves cols can't be NULL.
+func (s *FDSet) AddLaxKey(cols ColSet) {
+	s.touch(cols)
+	s.laxKeys = append(s.laxKeys, copyOf(cols))
+	s.laxNullable = append(s.laxNullable, copyOf(cols))
+}
+
+// AddEquivalence records that every column in a always equals every column
+// in b (e.g. from `a.x = b.y` or an equi-join condition), merging both into
+// one equivalence class together with any existing class either touches.
+func (s *FDSet) AddEquivalence(a, b ColSet) {
+	s.touch(a)
+	s.touch(b)
+	merged := a.Union(b)
+	kept := make([]ColSet, 0, len(s.equivGroups))
+	for _, g := range s.equivGroups {
+		if intersects(g, merged) {
+			merged = merged.Union(g)
+		} else {
+			kept = append(kept, g)
+		}
+	}
+	s.equivGroups = append(kept, merged)
+}
+
+// AddConstants records that every column in cols is pinned to a single
+// value for every row (e.g. from `col = <const>`).
+func (s *FDSet) AddConstants(cols ColSet) {
+	s.touch(cols)
+	s.constants = s.constants.Union(cols)
+}
+
+// AddStrictFD records an arbitrary from -> to dependency that holds for
+// every row, beyond what a key or equivalence already captures.
+func (s *FDSet) AddStrictFD(from, to ColSet) {
+	s.touch(from)
+	s.touch(to)
+	s.fds = append(s.fds, strictFD{from: copyOf(from), to: copyOf(to)})
+}
+
+// AddFrom folds every dependency other holds into s, as if s had been
+// built with the same sequence of Add* calls other was. It's how a join's
+// FDSet is built up from its two (column-disjoint) children's FDSets
+// before layering in the join's own equality conditions.
+func (s *FDSet) AddFrom(other *FDSet) {
+	s.touch(other.allCols)
+	s.strictKeys = append(s.strictKeys, other.strictKeys...)
+	s.laxKeys = append(s.laxKeys, other.laxKeys...)
+	s.laxNullable = append(s.laxNullable, other.laxNullable...)
+	s.equivGroups = append(s.equivGroups, other.equivGroups...)
+	s.constants = s.constants.Union(other.constants)
+	s.fds = append(s.fds, other.fds...)
+	s.laxFDs = append(s.laxFDs, other.laxFDs...)
+}
+
+// MakeNotNull promotes every lax key or lax FD whose remaining nullable
+// columns are now fully covered by cols to strict, the way a `col IS NOT
+// NULL` (or `col = <const>` with a non-NULL const, which AddConstants
+// already special-cases) predicate in a Selection does.
+func (s *FDSet) MakeNotNull(cols ColSet) {
+	keptKeys := s.strictKeys
+	keptLaxKeys := s.laxKeys[:0]
+	keptLaxNullable := s.laxNullable[:0]
+	for i, k := range s.laxKeys {
+		nullable := s.laxNullable[i].Difference(cols)
+		if nullable.Len() == 0 {
+			keptKeys = append(keptKeys, k)
+			continue
+		}
+		keptLaxKeys = append(keptLaxKeys, k)
+		keptLaxNullable = append(keptLaxNullable, nullable)
+	}
+	s.strictKeys, s.laxKeys, s.laxNullable = keptKeys, keptLaxKeys, keptLaxNullable
+
+	keptFDs := s.fds
+	keptLaxFDs := s.laxFDs[:0]
+	for _, f := range s.laxFDs {
+		nullable := f.nullable.Difference(cols)
+		if nullable.Len() == 0 {
+			keptFDs = append(keptFDs, strictFD{from: f.from, to: f.to})
+			continue
+		}
+		keptLaxFDs = append(keptLaxFDs, laxFD{from: f.from, to: f.to, nullable: nullable})
+	}
+	s.fds, s.laxFDs = keptFDs, keptLaxFDs
+}
+
+// MakeOuterJoin adjusts s -- which must already hold the combined,
+// inner-join FDs of leftCols and rightCols -- for nullExtendedCols instead
+// being null-extended, the way LEFT/RIGHT/FULL JOIN null-extend the
+// unmatched side's columns for a row with no match. Any strict key, FD, or
+// equivalence that reaches into nullExtendedCols can no longer be relied
+// on for every output row (only for rows where that side actually
+// matched), so it's demoted to a lax counterpart -- promotable back to
+// strict later by MakeNotNull once a Selection above the join proves those
+// columns can't be NULL, e.g. `WHERE r.id IS NOT NULL` turning a LEFT JOIN
+// back into the equivalent of an inner join for FD purposes.
+func (s *FDSet) MakeOuterJoin(leftCols, rightCols, nullExtendedCols ColSet) {
+	s.touch(leftCols)
+	s.touch(rightCols)
+	if nullExtendedCols.Len() == 0 {
+		return
+	}
+
+	keptKeys := s.strictKeys[:0]
+	for _, k := range s.strictKeys {
+		if intersects(k, nullExtendedCols) {
+			s.laxKeys = append(s.laxKeys, k)
+			s.laxNullable = append(s.laxNullable, k.Intersect(nullExtendedCols))
+			continue
+		}
+		keptKeys = append(keptKeys, k)
+	}
+	s.strictKeys = keptKeys
+
+	keptFDs := s.fds[:0]
+	for _, f := range s.fds {
+		touched := f.from.Union(f.to).Intersect(nullExtendedCols)
+		if touched.Len() > 0 {
+			s.laxFDs = append(s.laxFDs, laxFD{from: f.from, to: f.to, nullable: touched})
+			continue
+		}
+		keptFDs = append(keptFDs, f)
+	}
+	s.fds = keptFDs
+
+	// A group straddling both the preserved and the null-extended side no
+	// longer holds for every row: once the null-extended side's column is
+	// NULLed out, it stops equaling the preserved side's. A group entirely
+	// inside nullExtendedCols is unaffected -- those columns still agree
+	// with each other, they just might all be NULL together.
+	keptEquiv := s.equivGroups[:0]
+	for _, g := range s.equivGroups {
+		if intersects(g, nullExtendedCols) && !isSubset(g, nullExtendedCols) {
+			continue
+		}
+		keptEquiv = append(keptEquiv, g)
+	}
+	s.equivGroups = keptEquiv
+
+	// A constant pinned on the null-extended side isn't constant for an
+	// unmatched row, which substitutes NULL for it instead.
+	s.constants = s.constants.Difference(nullExtendedCols)
+}
+
+// closureOf computes the transitive closure of determined: every column
+// that determined functionally determines via equivalences, constants,
+// strict FDs, and strict keys, including determined itself. Lax
+// dependencies are never used -- a column they'd add is only determined
+// for some rows, not every row, which isn't enough to prove closure.
+func (s *FDSet) closureOf(determined ColSet) ColSet {
+	closure := copyOf(determined).Union(s.constants)
+	for changed := true; changed; {
+		changed = false
+		for _, g := range s.equivGroups {
+			if intersects(closure, g) && !isSubset(g, closure) {
+				closure = closure.Union(g)
+				changed = true
+			}
+		}
+		for _, f := range s.fds {
+			if isSubset(f.from, closure) && !isSubset(f.to, closure) {
+				closure = closure.Union(f.to)
+				changed = true
+			}
+		}
+		for _, k := range s.strictKeys {
+			if isSubset(k, closure) && !isSubset(s.allCols, closure) {
+				closure = closure.Union(s.allCols)
+				changed = true
+			}
+		}
+	}
+	return closure
+}
+
+// InClosureOf reports whether cols is functionally determined by
+// determined: whether every column of cols is the same for any two rows
+// that already agree on determined.
+func (s *FDSet) InClosureOf(cols, determined ColSet) bool {
+	return isSubset(cols, s.closureOf(determined))
+}
+
+// ColsAreStrictKey reports whether cols is a (super)key of the relation:
+// whether any two rows agreeing on cols are the same row. This is
+// InClosureOf with the whole relation as the target instead of some other
+// column set, the same way a strict key's closure in AddStrictKey is defined
+// to reach every column this FDSet has ever seen.
+func (s *FDSet) ColsAreStrictKey(cols ColSet) bool {
+	return isSubset(s.allCols, s.closureOf(cols))
+}
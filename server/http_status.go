@@ -17,16 +17,22 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
+	"os"
 	"runtime"
 	rpprof "runtime/pprof"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -34,6 +40,7 @@ import (
 	"github.com/pingcap/parser/mysql"
 	"github.com/pingcap/parser/terror"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/printer"
@@ -43,6 +50,11 @@ import (
 	static "sourcegraph.com/sourcegraph/appdash-data"
 )
 
+// clusterZipWorkers bounds how many peer nodes' /debug/zip a cluster-scope
+// request fetches concurrently, so a large cluster doesn't open hundreds of
+// simultaneous outbound connections from one status-port request.
+const clusterZipWorkers = 8
+
 const defaultStatusPort = 10080
 
 func (s *Server) startStatusHTTP() {
@@ -142,79 +154,17 @@ func (s *Server) startHTTPServer() {
 	serverMux.HandleFunc("/debug/zip", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tidb_debug"`+time.Now().Format("20060102150405")+".zip"))
 
-		// dump goroutine/heap/mutex
-		items := []struct {
-			name   string
-			gc     int
-			debug  int
-			second int
-		}{
-			{name: "goroutine", debug: 2},
-			{name: "heap", gc: 1},
-			{name: "mutex"},
-		}
-		zw := zip.NewWriter(w)
-		for _, item := range items {
-			p := rpprof.Lookup(item.name)
-			if p == nil {
-				serveError(w, http.StatusNotFound, "Unknown profile")
-				return
-			}
-			if item.gc > 0 {
-				runtime.GC()
-			}
-			fw, err := zw.Create(item.name)
-			if err != nil {
-				serveError(w, http.StatusInternalServerError, fmt.Sprintf("Create zipped %s fail: %v", item.name, err))
-				return
-			}
-			err = p.WriteTo(fw, item.debug)
-			terror.Log(err)
-		}
-
-		// dump profile
-		fw, err := zw.Create("profile")
-		if err != nil {
-			serveError(w, http.StatusInternalServerError, fmt.Sprintf("Create zipped %s fail: %v", "profile", err))
-			return
-		}
-		if err := rpprof.StartCPUProfile(fw); err != nil {
-			serveError(w, http.StatusInternalServerError,
-				fmt.Sprintf("Could not enable CPU profiling: %s", err))
+		if r.FormValue("scope") == "cluster" {
+			s.clusterDebugZip(w, r)
 			return
 		}
-		sec, err := strconv.ParseInt(r.FormValue("seconds"), 10, 64)
-		if sec <= 0 || err != nil {
-			sec = 10
-		}
-		sleepWithCtx(r.Context(), time.Duration(sec)*time.Second)
-		rpprof.StopCPUProfile()
-
-		// dump config
-		fw, err = zw.Create("config")
-		if err != nil {
-			serveError(w, http.StatusInternalServerError, fmt.Sprintf("Create zipped %s fail: %v", "config", err))
-			return
-		}
-		js, err := json.MarshalIndent(config.GetGlobalConfig(), "", " ")
-		if err != nil {
-			serveError(w, http.StatusInternalServerError, fmt.Sprintf("get config info fail%v", err))
-			return
-		}
-		_, err = fw.Write(js)
-		terror.Log(err)
 
-		// dump version
-		fw, err = zw.Create("version")
-		if err != nil {
-			serveError(w, http.StatusInternalServerError, fmt.Sprintf("Create zipped %s fail: %v", "version", err))
+		zw := zip.NewWriter(w)
+		if err := writeDebugZipItems(zw, "", r); err != nil {
+			serveError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		_, err = fw.Write([]byte(printer.GetTiDBInfo()))
-		terror.Log(err)
-
-		err = zw.Close()
-		terror.Log(err)
+		terror.Log(zw.Close())
 	})
 	fetcher := sqlInfoFetcher{store: tikvHandlerTool.Store}
 	serverMux.HandleFunc("/debug/sub-optimal-plan", fetcher.zipInfoForSQL)
@@ -250,10 +200,17 @@ func (s *Server) startHTTPServer() {
 		}
 	})
 
+	statusAuth := loadStatusAuth()
+
 	logutil.Logger(context.Background()).Info("for status and metrics report", zap.String("listening on addr", addr))
-	s.statusServer = &http.Server{Addr: addr, Handler: CorsHandler{handler: serverMux, cfg: s.cfg}}
+	s.statusServer = &http.Server{Addr: addr, Handler: CorsHandler{handler: statusAuth.wrap(serverMux), cfg: s.cfg}}
 
 	if len(s.cfg.Security.ClusterSSLCA) != 0 {
+		s.statusServer.TLSConfig, err = buildStatusTLSConfig(s.cfg)
+		if err != nil {
+			logutil.Logger(context.Background()).Error("build status TLS config failed", zap.Error(err))
+			return
+		}
 		err = s.statusServer.ListenAndServeTLS(s.cfg.Security.ClusterSSLCert, s.cfg.Security.ClusterSSLKey)
 	} else {
 		err = s.statusServer.ListenAndServe()
@@ -288,3 +245,463 @@ func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
 		terror.Log(errors.Trace(err))
 	}
 }
+
+// aclTier classifies how sensitive a status-port route is, from least to
+// most privileged. Prometheus scraping only ever needs tierReadOnly.
+type aclTier int
+
+const (
+	tierReadOnly aclTier = iota
+	tierAdmin
+	tierDebug
+)
+
+// routeACL maps a route's path prefix to the tier required to call it.
+// Anything not listed here defaults to tierAdmin: new status-port routes
+// must be reviewed into this table explicitly rather than being reachable
+// by accident.
+var routeACL = []struct {
+	prefix string
+	tier   aclTier
+}{
+	{"/status", tierReadOnly},
+	{"/metrics", tierReadOnly},
+	{"/debug/pprof/", tierDebug},
+	{"/debug/zip", tierDebug},
+	{"/debug/sub-optimal-plan", tierDebug},
+	{"/mvcc/", tierAdmin},
+	{"/ddl/owner/resign", tierAdmin},
+	{"/reload-config", tierAdmin},
+	{"/binlog/recover", tierAdmin},
+	{"/settings", tierAdmin},
+}
+
+// tierForRoute returns the ACL tier guarding path, matching the longest
+// routeACL prefix and defaulting to tierAdmin when nothing matches.
+func tierForRoute(path string) aclTier {
+	best := -1
+	tier := tierAdmin
+	for _, r := range routeACL {
+		if strings.HasPrefix(path, r.prefix) && len(r.prefix) > best {
+			best = len(r.prefix)
+			tier = r.tier
+		}
+	}
+	return tier
+}
+
+// statusAuthConfig is the on-disk shape of the status-port auth config,
+// loaded from the file named by the TIDB_STATUS_AUTH_CONFIG environment
+// variable. config.Status has no field for this yet in this tree, so the
+// path is threaded in out-of-band rather than through config.Config; once
+// config.Status grows a StatusAuthConfigPath (or similar) this can read
+// that instead.
+type statusAuthConfig struct {
+	// Tokens maps a bearer token to the tier it authenticates as.
+	Tokens map[string]aclTier `json:"tokens"`
+	// ClientCNs maps an mTLS client certificate's Common Name to the tier
+	// it authenticates as, reusing s.cfg.Security.ClusterSSLCA as the
+	// trust root that verifies the certificate itself.
+	ClientCNs map[string]aclTier `json:"client_cns"`
+	// ScrapeCIDRs allowlists source IPs that may call tierReadOnly routes
+	// without presenting a token or client cert, so that an existing
+	// Prometheus deployment that scrapes /metrics and /status over plain
+	// HTTP keeps working after auth is turned on.
+	ScrapeCIDRs []string `json:"scrape_cidrs"`
+}
+
+// statusAuth is the loaded, parsed form of statusAuthConfig, ready to
+// authorize requests. A nil *statusAuth leaves every route open, matching
+// the status port's behavior before this middleware existed.
+type statusAuth struct {
+	tokens     map[string]aclTier
+	clientCNs  map[string]aclTier
+	scrapeNets []*net.IPNet
+}
+
+// loadStatusAuth reads and parses the file named by TIDB_STATUS_AUTH_CONFIG.
+// It returns nil, leaving the status port unauthenticated, when the
+// variable is unset; that's the pre-existing behavior, and turning on auth
+// is opt-in.
+func loadStatusAuth() *statusAuth {
+	path := os.Getenv("TIDB_STATUS_AUTH_CONFIG")
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		logutil.Logger(context.Background()).Error("read status auth config failed", zap.Error(err))
+		return nil
+	}
+	var cfg statusAuthConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		logutil.Logger(context.Background()).Error("parse status auth config failed", zap.Error(err))
+		return nil
+	}
+	sa := &statusAuth{tokens: cfg.Tokens, clientCNs: cfg.ClientCNs}
+	for _, cidr := range cfg.ScrapeCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logutil.Logger(context.Background()).Error("parse scrape CIDR failed", zap.String("cidr", cidr), zap.Error(err))
+			continue
+		}
+		sa.scrapeNets = append(sa.scrapeNets, ipNet)
+	}
+	return sa
+}
+
+// statusAuthError is the structured body written on a 401/403, so a caller
+// can distinguish "no credential" from "credential doesn't reach this
+// route's tier" without parsing a plain-text message.
+type statusAuthError struct {
+	Error string `json:"error"`
+}
+
+func writeStatusAuthError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	terror.Log(json.NewEncoder(w).Encode(statusAuthError{Error: msg}))
+}
+
+// fromScrapeIP reports whether r's source IP is in sa's scrape-CIDR
+// allowlist, independent of any token or client cert.
+func (sa *statusAuth) fromScrapeIP(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range sa.scrapeNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// callerTier returns the highest tier r's credentials authenticate as, and
+// whether any credential (token, client cert, or scrape CIDR) matched at
+// all.
+func (sa *statusAuth) callerTier(r *http.Request) (aclTier, bool) {
+	best, ok := tierReadOnly, false
+	if r.TLS != nil {
+		for _, cert := range r.TLS.PeerCertificates {
+			if tier, found := sa.clientCNs[cert.Subject.CommonName]; found {
+				ok = true
+				if tier > best {
+					best = tier
+				}
+			}
+		}
+	}
+	if tok := bearerToken(r); tok != "" {
+		if tier, found := sa.tokens[tok]; found {
+			ok = true
+			if tier > best {
+				best = tier
+			}
+		}
+	}
+	if sa.fromScrapeIP(r) {
+		ok = true
+	}
+	return best, ok
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(h, prefix)
+}
+
+// wrap returns handler guarded by sa's per-route ACL, or handler itself
+// unchanged when sa is nil (auth not configured). A request whose tier
+// doesn't reach the route's required tier gets a structured 401 (no
+// credential recognized at all) or 403 (recognized, but not privileged
+// enough).
+func (sa *statusAuth) wrap(handler http.Handler) http.Handler {
+	if sa == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := tierForRoute(r.URL.Path)
+		tier, ok := sa.callerTier(r)
+		if !ok {
+			writeStatusAuthError(w, http.StatusUnauthorized, "no valid status-port credential presented")
+			return
+		}
+		if tier < required {
+			writeStatusAuthError(w, http.StatusForbidden, "credential does not authorize this route")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// buildStatusTLSConfig extends cfg.Security's own TLS config with client
+// certificate verification against the same ClusterSSLCA trust root, so
+// statusAuth.callerTier can read a verified CN out of r.TLS.PeerCertificates.
+// Client certs stay optional (VerifyClientCertIfGiven): a caller without one
+// still gets to authenticate via bearer token or scrape CIDR instead.
+func buildStatusTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsCfg, err := cfg.Security.ToTLSConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	caData, err := ioutil.ReadFile(cfg.Security.ClusterSSLCA)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, errors.New("status auth: failed to parse ClusterSSLCA as PEM")
+	}
+	tlsCfg.ClientCAs = pool
+	tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	return tlsCfg, nil
+}
+
+// writeDebugZipItems writes the single-node goroutine/heap/mutex/profile/
+// config/version collection into zw, naming each entry prefix+<item>. Used
+// directly for a single-node `/debug/zip` request (prefix "") and for the
+// local node's own contribution to a `scope=cluster` archive (prefix
+// "<serverID>/").
+func writeDebugZipItems(zw *zip.Writer, prefix string, r *http.Request) error {
+	items := []struct {
+		name  string
+		gc    int
+		debug int
+	}{
+		{name: "goroutine", debug: 2},
+		{name: "heap", gc: 1},
+		{name: "mutex"},
+	}
+	for _, item := range items {
+		p := rpprof.Lookup(item.name)
+		if p == nil {
+			return errors.New("Unknown profile")
+		}
+		if item.gc > 0 {
+			runtime.GC()
+		}
+		fw, err := zw.Create(prefix + item.name)
+		if err != nil {
+			return errors.Errorf("create zipped %s fail: %v", item.name, err)
+		}
+		terror.Log(p.WriteTo(fw, item.debug))
+	}
+
+	fw, err := zw.Create(prefix + "profile")
+	if err != nil {
+		return errors.Errorf("create zipped %s fail: %v", "profile", err)
+	}
+	if err := rpprof.StartCPUProfile(fw); err != nil {
+		return errors.Errorf("could not enable CPU profiling: %s", err)
+	}
+	sec, err := strconv.ParseInt(r.FormValue("seconds"), 10, 64)
+	if sec <= 0 || err != nil {
+		sec = 10
+	}
+	sleepWithCtx(r.Context(), time.Duration(sec)*time.Second)
+	rpprof.StopCPUProfile()
+
+	fw, err = zw.Create(prefix + "config")
+	if err != nil {
+		return errors.Errorf("create zipped %s fail: %v", "config", err)
+	}
+	js, err = json.MarshalIndent(config.GetGlobalConfig(), "", " ")
+	if err != nil {
+		return errors.Errorf("get config info fail %v", err)
+	}
+	_, err = fw.Write(js)
+	terror.Log(err)
+
+	fw, err = zw.Create(prefix + "version")
+	if err != nil {
+		return errors.Errorf("create zipped %s fail: %v", "version", err)
+	}
+	_, err = fw.Write([]byte(printer.GetTiDBInfo()))
+	terror.Log(err)
+	return nil
+}
+
+// clusterNodeZipResult records one peer's outcome in a `scope=cluster`
+// debug zip: either its subdirectory was merged into the archive, or Error
+// explains why it was skipped. One unreachable node is recorded here
+// instead of aborting the whole archive.
+type clusterNodeZipResult struct {
+	ID         string `json:"id"`
+	StatusAddr string `json:"status_addr"`
+	Self       bool   `json:"self"`
+	Error      string `json:"error,omitempty"`
+}
+
+// clusterDebugZip handles `/debug/zip?scope=cluster`: it looks up every
+// peer TiDB via infosync.GetAllServerInfo, fetches each one's own
+// `/debug/zip` (the local node collects its items in-process instead),
+// and merges every per-node archive into one zip under a
+// "<serverID>/"-prefixed subdirectory, alongside a top-level cluster.json
+// describing topology and any per-node collection errors.
+func (s *Server) clusterDebugZip(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	members, err := infosync.GetAllServerInfo(ctx)
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, fmt.Sprintf("get cluster topology failed: %v", err))
+		return
+	}
+
+	zw := zip.NewWriter(w)
+	// archive/zip.Writer isn't safe for concurrent Create/Write; the worker
+	// pool below serializes every write to zw behind this mutex.
+	var zwMu sync.Mutex
+
+	type job struct {
+		id   string
+		info *infosync.ServerInfo
+	}
+	jobs := make(chan job, len(members))
+	for id, info := range members {
+		jobs <- job{id: id, info: info}
+	}
+	close(jobs)
+
+	results := make(chan clusterNodeZipResult, len(members))
+	var wg sync.WaitGroup
+	workers := clusterZipWorkers
+	if workers > len(members) {
+		workers = len(members)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- s.collectClusterZipNode(ctx, &zwMu, zw, j.id, j.info, r)
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	nodeResults := make([]clusterNodeZipResult, 0, len(members))
+	for res := range results {
+		nodeResults = append(nodeResults, res)
+	}
+
+	topology := struct {
+		CollectedAt time.Time              `json:"collected_at"`
+		Nodes       []clusterNodeZipResult `json:"nodes"`
+	}{CollectedAt: time.Now(), Nodes: nodeResults}
+	topoJS, err := json.MarshalIndent(topology, "", "  ")
+	if err == nil {
+		zwMu.Lock()
+		var fw io.Writer
+		fw, err = zw.Create("cluster.json")
+		if err == nil {
+			_, err = fw.Write(topoJS)
+		}
+		zwMu.Unlock()
+	}
+	terror.Log(err)
+	terror.Log(zw.Close())
+}
+
+// collectClusterZipNode gathers one peer's debug archive (in-process for
+// the local node, over HTTP otherwise) and merges it into zw.
+func (s *Server) collectClusterZipNode(ctx context.Context, zwMu *sync.Mutex, zw *zip.Writer, id string, info *infosync.ServerInfo, r *http.Request) clusterNodeZipResult {
+	res := clusterNodeZipResult{ID: id, StatusAddr: fmt.Sprintf("%s:%d", info.IP, info.StatusPort)}
+	res.Self = info.IP == s.cfg.AdvertiseAddress && int(info.StatusPort) == s.cfg.Status.StatusPort
+
+	var body []byte
+	var err error
+	if res.Self {
+		buf := new(bytes.Buffer)
+		localZW := zip.NewWriter(buf)
+		if err = writeDebugZipItems(localZW, "", r); err == nil {
+			err = localZW.Close()
+		}
+		body = buf.Bytes()
+	} else {
+		body, err = fetchPeerDebugZip(ctx, s.cfg, info, r)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if err := mergeZipEntries(zwMu, zw, id+"/", body); err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// fetchPeerDebugZip retrieves a peer's own `/debug/zip` archive over HTTP,
+// forwarding the `seconds` query parameter so every node's CPU profile
+// covers the same collection window, and dialing over TLS with the
+// cluster's client cert (cfg.Security) when one is configured, the same
+// way the status server itself decides whether to serve TLS.
+func fetchPeerDebugZip(ctx context.Context, cfg *config.Config, info *infosync.ServerInfo, r *http.Request) ([]byte, error) {
+	scheme := "http"
+	client := http.DefaultClient
+	if len(cfg.Security.ClusterSSLCA) != 0 {
+		scheme = "https"
+		tlsCfg, err := cfg.Security.ToTLSConfig()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		client = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+	}
+	u := url.URL{
+		Scheme:   scheme,
+		Host:     fmt.Sprintf("%s:%d", info.IP, info.StatusPort),
+		Path:     "/debug/zip",
+		RawQuery: url.Values{"seconds": {r.FormValue("seconds")}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer terror.Log(resp.Body.Close())
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("peer %s:%d returned status %d", info.IP, info.StatusPort, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// mergeZipEntries copies every entry of the zip archive in body into dst
+// under prefix, serializing access to dst since archive/zip.Writer isn't
+// safe for concurrent use and the cluster-zip worker pool shares one.
+func mergeZipEntries(mu *sync.Mutex, dst *zip.Writer, prefix string, body []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, f := range zr.File {
+		rc, ferr := f.Open()
+		if ferr != nil {
+			return errors.Trace(ferr)
+		}
+		fw, cerr := dst.Create(prefix + f.Name)
+		if cerr != nil {
+			terror.Log(rc.Close())
+			return errors.Trace(cerr)
+		}
+		_, cerr = io.Copy(fw, rc)
+		terror.Log(rc.Close())
+		if cerr != nil {
+			return errors.Trace(cerr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binloginfo
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/parser/terror"
+	pumpcli "github.com/pingcap/tidb-tools/tidb-binlog/pump_client"
+	"github.com/pingcap/tidb/metrics"
+	binlog "github.com/pingcap/tipb/go-binlog"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BinlogInfo contains binlog data and client.
+type BinlogInfo struct {
+	Data   *binlog.Binlog
+	Client *pumpcli.PumpsClient
+}
+
+var skipBinlog uint32
+var ignoreError uint32
+
+// pumpsClient is the global client to write binlog, it should be initialized with InitPumpsClient
+var pumpsClient atomic.Value
+
+// GetPumpsClient gets the pumpsClient instance.
+func GetPumpsClient() *pumpcli.PumpsClient {
+	v := pumpsClient.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(*pumpcli.PumpsClient)
+}
+
+// SetPumpsClient sets the pumpsClient instance.
+func SetPumpsClient(client *pumpcli.PumpsClient) {
+	pumpsClient.Store(client)
+}
+
+// MockPumpsClient creates a PumpsClient, used for test.
+func MockPumpsClient(client binlog.PumpClient) *pumpcli.PumpsClient {
+	nodeID := "pump-1"
+	pump := &pumpcli.PumpStatus{
+		Status: pumpcli.Status{
+			NodeID: nodeID,
+			State:  pumpcli.OnlineState,
+		},
+		Client: client,
+	}
+	pumpInfos := &pumpcli.PumpInfos{
+		Pumps:       make(map[string]*pumpcli.PumpStatus),
+		AvaliablePumps: make(map[string]*pumpcli.PumpStatus),
+	}
+	pumpInfos.Pumps[nodeID] = pump
+	pumpInfos.AvaliablePumps[nodeID] = pump
+	pCli := &pumpcli.PumpsClient{
+		Pumps:             pumpInfos.Pumps,
+		Selector:          pumpcli.NewSelector(pumpcli.Range),
+		RetryTime:         1,
+		BinlogWriteTimeout: 15 * time.Second,
+	}
+	pCli.Selector.SetPumps([]*pumpcli.PumpStatus{pump})
+	ResetPumpMetrics(pumpAddrs(pCli))
+	return pCli
+}
+
+// pumpAddrs collects the node IDs of a PumpsClient's pump set, used as the
+// "pump_addr" metrics label.
+func pumpAddrs(client *pumpcli.PumpsClient) []string {
+	addrs := make([]string, 0, len(client.Pumps))
+	for addr := range client.Pumps {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// SetIgnoreError sets the ignoreError flag, when set to true binlog errors
+// will be ignored rather than causing the transaction to fail.
+func SetIgnoreError(on bool) {
+	if on {
+		atomic.StoreUint32(&ignoreError, 1)
+	} else {
+		atomic.StoreUint32(&ignoreError, 0)
+	}
+}
+
+// DisableSkipBinlogFlag disables the skipBinlog flag set by a previous ignored error.
+func DisableSkipBinlogFlag() {
+	atomic.StoreUint32(&skipBinlog, 0)
+	if client := GetPumpsClient(); client != nil {
+		ResetPumpMetrics(pumpAddrs(client))
+	}
+}
+
+// WriteBinlog writes a binlog to Pump. It also fans out to any registered
+// EventSink so that consumers which do not speak the Pump protocol (e.g.
+// Debezium-style CDC consumers) can observe the same change in their own
+// format.
+func (info *BinlogInfo) WriteBinlog(clusterID uint64) error {
+	skip := atomic.LoadUint32(&skipBinlog)
+	if skip > 0 {
+		metrics.CriticalErrorCounter.Add(1)
+		return nil
+	}
+
+	if info.Client == nil {
+		return errors.New("pumps client is nil")
+	}
+
+	commitData, err := info.Data.Marshal()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	addr := pumpMetricsAddr(info.Client)
+	inflightInc(addr)
+	start := time.Now()
+	err = info.Client.WriteBinlog(commitData)
+	inflightDec(addr)
+	if err != nil {
+		log.Error("write binlog failed", zap.Error(err))
+		if atomic.LoadUint32(&ignoreError) == 1 {
+			atomic.StoreUint32(&skipBinlog, 1)
+			observeWrite(addr, start, true)
+			return nil
+		}
+
+		if strconv.IntSize == 64 && status.Code(errors.Cause(err)) == codes.ResourceExhausted {
+			return terror.ErrCritical.GenWithStackByArgs(err)
+		}
+		return errors.Trace(err)
+	}
+	observeWrite(addr, start, false)
+
+	publishToEventSinks(info.Data, clusterID)
+	return nil
+}
+
+// pumpMetricsAddr picks a representative label value for a write; with
+// multiple pumps the selector already balanced the request, so the whole
+// client's address set is used as a single composite label rather than
+// trying to guess which pump actually served it.
+func pumpMetricsAddr(client *pumpcli.PumpsClient) string {
+	addrs := pumpAddrs(client)
+	if len(addrs) == 0 {
+		return "unknown"
+	}
+	if len(addrs) == 1 {
+		return addrs[0]
+	}
+	return "multi"
+}
+
+// WriteBinlogDirect writes the binlog synchronously, used by the background
+// heartbeat worker that does not have a BinlogInfo handy.
+func WriteBinlogDirect(ctx context.Context, client *pumpcli.PumpsClient, data *binlog.Binlog) error {
+	info := &BinlogInfo{Data: data, Client: client}
+	return info.WriteBinlog(0)
+}
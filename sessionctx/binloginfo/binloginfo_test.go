@@ -395,6 +395,50 @@ func (s *testBinlogSuite) TestZIgnoreError(c *C) {
 	binloginfo.SetIgnoreError(false)
 }
 
+// TestPumpMetricsReset checks that a failed write bumps the dropped-on-ignore
+// counter, and that ResetPumpMetrics (as triggered by DisableSkipBinlogFlag)
+// clears a removed pump's gauges rather than leaving them at their last
+// value.
+func (s *testBinlogSuite) TestPumpMetricsReset(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.Se.GetSessionVars().BinlogClient = s.client
+	tk.MustExec("drop table if exists pump_metrics_t")
+	tk.MustExec("create table pump_metrics_t (id int)")
+
+	addrs := make([]string, 0, len(s.client.Pumps))
+	for addr := range s.client.Pumps {
+		addrs = append(addrs, addr)
+	}
+	c.Assert(len(addrs), Greater, 0)
+	addr := addrs[0]
+	if len(addrs) > 1 {
+		addr = "multi"
+	}
+
+	binloginfo.SetIgnoreError(true)
+	s.pump.mu.Lock()
+	s.pump.mu.mockFail = true
+	s.pump.mu.Unlock()
+
+	before := binloginfo.DroppedOnIgnoreCount(addr)
+	tk.MustExec("insert into pump_metrics_t values (1)")
+	c.Assert(binloginfo.DroppedOnIgnoreCount(addr), Greater, before)
+
+	s.pump.mu.Lock()
+	s.pump.mu.mockFail = false
+	s.pump.mu.Unlock()
+	binloginfo.DisableSkipBinlogFlag()
+	binloginfo.SetIgnoreError(false)
+
+	binloginfo.ResetPumpMetrics(nil)
+	c.Assert(binloginfo.ConnState(addr), Equals, float64(0))
+	c.Assert(binloginfo.DroppedOnIgnoreCount(addr), Equals, float64(0))
+
+	// Restore the active pump set so later tests keep writing binlogs.
+	binloginfo.ResetPumpMetrics(addrs)
+}
+
 func (s *testBinlogSuite) TestPartitionedTable(c *C) {
 	// This test checks partitioned table write binlog with table ID, rather than partition ID.
 	tk := testkit.NewTestKit(c, s.store)
@@ -0,0 +1,219 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binloginfo
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/util/codec"
+	binlog "github.com/pingcap/tipb/go-binlog"
+	"go.uber.org/zap"
+)
+
+// EventSink receives a decoded change event for every row mutated in a
+// Binlog.Binlog that TiDB would otherwise only ship, as protobuf, to Pump.
+// It lets ecosystem tools subscribe to row-level changes without running
+// Drainer. Implementations must not block WriteBinlog for long: Send is
+// called synchronously from the commit path.
+type EventSink interface {
+	// Send delivers one Debezium-shaped change event. impl may buffer and
+	// flush asynchronously, but must return any unrecoverable error so the
+	// caller can decide, via SetIgnoreError, whether to surface it.
+	Send(event *ChangeEvent) error
+	// Close releases resources held by the sink (files, connections, ...).
+	Close() error
+}
+
+// ChangeEvent is a single Debezium-compatible row-change envelope.
+type ChangeEvent struct {
+	Op     string                 `json:"op"` // "c" create, "u" update, "d" delete
+	TsMs   int64                  `json:"ts_ms"`
+	Source EventSource            `json:"source"`
+	Before map[string]interface{} `json:"before,omitempty"`
+	After  map[string]interface{} `json:"after,omitempty"`
+}
+
+// EventSource describes where a ChangeEvent came from.
+type EventSource struct {
+	ClusterID     uint64 `json:"cluster_id"`
+	Schema        string `json:"schema"`
+	Table         string `json:"table"`
+	StartTs       int64  `json:"start_ts"`
+	CommitTs      int64  `json:"commit_ts"`
+	SchemaVersion int64  `json:"schema_version"`
+}
+
+var (
+	eventSinkMu sync.RWMutex
+	eventSink   EventSink
+	sinkEnabled uint32
+)
+
+// SetEventSink registers the secondary sink that WriteBinlog fans change
+// events out to. Passing nil disables fan-out.
+func SetEventSink(sink EventSink) {
+	eventSinkMu.Lock()
+	eventSink = sink
+	eventSinkMu.Unlock()
+	if sink == nil {
+		atomic.StoreUint32(&sinkEnabled, 0)
+	} else {
+		atomic.StoreUint32(&sinkEnabled, 1)
+	}
+}
+
+// TableInfoGetter resolves the schema name and TableInfo for a table ID at a
+// given schema version, so that mutation rows -- which are raw datum blobs --
+// can be translated into column-name-keyed field maps. Domain's InfoSchema
+// satisfies this interface.
+type TableInfoGetter interface {
+	TableByID(schemaVersion int64, tableID int64) (schema string, table *model.TableInfo, ok bool)
+}
+
+var tableInfoGetter atomic.Value // TableInfoGetter
+
+// SetTableInfoGetter wires the schema resolver used to translate mutation
+// rows into field maps. Typically called once at bootstrap with the domain.
+func SetTableInfoGetter(g TableInfoGetter) {
+	tableInfoGetter.Store(g)
+}
+
+func getTableInfoGetter() TableInfoGetter {
+	v := tableInfoGetter.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(TableInfoGetter)
+}
+
+// publishToEventSinks decodes bin's mutations, when any, into ChangeEvents
+// and forwards them to the registered EventSink. It is a best-effort,
+// non-fatal path: translation or sink errors are logged, not returned, so
+// that a CDC consumer outage never blocks the primary Pump write.
+func publishToEventSinks(bin *binlog.Binlog, clusterID uint64) {
+	if atomic.LoadUint32(&sinkEnabled) == 0 || bin.Tp != binlog.BinlogType_Prewrite || len(bin.PrewriteValue) == 0 {
+		return
+	}
+	preVal := new(binlog.PrewriteValue)
+	if err := preVal.Unmarshal(bin.PrewriteValue); err != nil {
+		log.Warn("decode prewrite value for event sink failed", zap.Error(err))
+		return
+	}
+
+	eventSinkMu.RLock()
+	sink := eventSink
+	eventSinkMu.RUnlock()
+	if sink == nil {
+		return
+	}
+
+	getter := getTableInfoGetter()
+	for _, mut := range preVal.Mutations {
+		schema, tbl, ok := resolveTable(getter, preVal.SchemaVersion, mut.TableId)
+		if !ok {
+			continue
+		}
+		events, err := translateMutation(mut, schema, tbl, clusterID, preVal.SchemaVersion, bin.StartTs, bin.CommitTs)
+		if err != nil {
+			log.Warn("translate mutation for event sink failed", zap.Error(err), zap.String("table", tbl.Name.O))
+			continue
+		}
+		for _, ev := range events {
+			if err := sink.Send(ev); err != nil {
+				log.Warn("event sink send failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func resolveTable(getter TableInfoGetter, schemaVersion, tableID int64) (string, *model.TableInfo, bool) {
+	if getter == nil {
+		return "", nil, false
+	}
+	return getter.TableByID(schemaVersion, tableID)
+}
+
+// translateMutation turns the raw InsertedRows/UpdatedRows/DeletedRows datum
+// blobs of a single TableMutation into typed, column-name-keyed ChangeEvents.
+func translateMutation(mut binlog.TableMutation, schema string, tbl *model.TableInfo, clusterID uint64, schemaVersion, startTs, commitTs int64) ([]*ChangeEvent, error) {
+	src := EventSource{
+		ClusterID:     clusterID,
+		Schema:        schema,
+		Table:         tbl.Name.O,
+		StartTs:       startTs,
+		CommitTs:      commitTs,
+		SchemaVersion: schemaVersion,
+	}
+
+	events := make([]*ChangeEvent, 0, len(mut.InsertedRows)+len(mut.UpdatedRows)/2+len(mut.DeletedRows))
+	for _, row := range mut.InsertedRows {
+		fields, err := rowToFieldMap(row, tbl)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &ChangeEvent{Op: "c", Source: src, After: fields})
+	}
+	for _, row := range mut.DeletedRows {
+		fields, err := rowToFieldMap(row, tbl)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &ChangeEvent{Op: "d", Source: src, Before: fields})
+	}
+	// UpdatedRows alternates old-row, new-row for each logical update.
+	for i := 0; i+1 < len(mut.UpdatedRows); i += 2 {
+		before, err := rowToFieldMap(mut.UpdatedRows[i], tbl)
+		if err != nil {
+			return nil, err
+		}
+		after, err := rowToFieldMap(mut.UpdatedRows[i+1], tbl)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &ChangeEvent{Op: "u", Source: src, Before: before, After: after})
+	}
+	return events, nil
+}
+
+// rowToFieldMap decodes a mutation row's datum stream and maps it onto
+// column names using tbl's current schema. The stream interleaves
+// [colID, colVal, colID, colVal, ..., -1, handle]; a trailing -1/handle pair
+// is always present and is skipped here since it is not a user column.
+func rowToFieldMap(row []byte, tbl *model.TableInfo) (map[string]interface{}, error) {
+	datums, err := codec.Decode(row, 4)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	colByID := make(map[int64]*model.ColumnInfo, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		colByID[col.ID] = col
+	}
+	fields := make(map[string]interface{}, len(tbl.Columns))
+	for i := 0; i+1 < len(datums); i += 2 {
+		colID, err := datums[i].ToInt64(nil)
+		if err != nil || colID < 0 {
+			continue
+		}
+		col, ok := colByID[colID]
+		if !ok {
+			continue
+		}
+		fields[col.Name.O] = datums[i+1].GetValue()
+	}
+	return fields, nil
+}
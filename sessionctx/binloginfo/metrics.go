@@ -0,0 +1,154 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binloginfo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Per-pump-address metrics for the binlog write path. Labels are the pump
+// address, so that a crashed or removed pump's series can, and must, be
+// explicitly deleted rather than left stuck at its last value -- Prometheus
+// label sets are not garbage collected on their own.
+var (
+	writeDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "binloginfo",
+			Name:      "write_binlog_duration_seconds",
+			Help:      "Bucketed histogram of WriteBinlog latency per pump address.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		}, []string{"pump_addr"})
+
+	inflightBinlogsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "binloginfo",
+			Name:      "inflight_binlogs",
+			Help:      "Number of WriteBinlog calls currently in flight per pump address.",
+		}, []string{"pump_addr"})
+
+	droppedOnIgnoreCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "binloginfo",
+			Name:      "dropped_on_ignore_total",
+			Help:      "Binlogs dropped because SetIgnoreError(true) was in effect when the write failed.",
+		}, []string{"pump_addr"})
+
+	lastWriteSuccessGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "binloginfo",
+			Name:      "last_successful_write_timestamp",
+			Help:      "Unix timestamp, in seconds, of the last successful WriteBinlog per pump address.",
+		}, []string{"pump_addr"})
+
+	connStateGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "binloginfo",
+			Name:      "pump_connection_state",
+			Help:      "Connection state of each pump (1 = online, 0 = unreachable/removed).",
+		}, []string{"pump_addr"})
+)
+
+func init() {
+	prometheus.MustRegister(writeDurationHistogram)
+	prometheus.MustRegister(inflightBinlogsGauge)
+	prometheus.MustRegister(droppedOnIgnoreCounter)
+	prometheus.MustRegister(lastWriteSuccessGauge)
+	prometheus.MustRegister(connStateGauge)
+}
+
+var (
+	activePumpAddrsMu sync.Mutex
+	activePumpAddrs   = map[string]struct{}{}
+)
+
+// ResetPumpMetrics clears gauge/counter series for every pump address that
+// is not in activeAddrs, and marks every address in activeAddrs as online.
+// It must be called whenever PumpsClient is (re)created or its pump set
+// changes, otherwise a crashed or removed pump leaves its last-reported
+// gauge values stuck forever -- the same class of bug as a long-lived label
+// set outliving the thing it labels.
+func ResetPumpMetrics(activeAddrs []string) {
+	active := make(map[string]struct{}, len(activeAddrs))
+	for _, addr := range activeAddrs {
+		active[addr] = struct{}{}
+	}
+
+	activePumpAddrsMu.Lock()
+	defer activePumpAddrsMu.Unlock()
+	for addr := range activePumpAddrs {
+		if _, ok := active[addr]; !ok {
+			deletePumpSeries(addr)
+		}
+	}
+	for addr := range active {
+		connStateGauge.WithLabelValues(addr).Set(1)
+	}
+	activePumpAddrs = active
+}
+
+func deletePumpSeries(addr string) {
+	writeDurationHistogram.DeleteLabelValues(addr)
+	inflightBinlogsGauge.DeleteLabelValues(addr)
+	droppedOnIgnoreCounter.DeleteLabelValues(addr)
+	lastWriteSuccessGauge.DeleteLabelValues(addr)
+	connStateGauge.DeleteLabelValues(addr)
+}
+
+// observeWrite records one WriteBinlog attempt's latency and, on a
+// dropped-due-to-ignore-error write, bumps the drop counter.
+func observeWrite(addr string, start time.Time, droppedOnIgnore bool) {
+	writeDurationHistogram.WithLabelValues(addr).Observe(time.Since(start).Seconds())
+	if droppedOnIgnore {
+		droppedOnIgnoreCounter.WithLabelValues(addr).Inc()
+		return
+	}
+	lastWriteSuccessGauge.WithLabelValues(addr).Set(float64(time.Now().Unix()))
+}
+
+func inflightInc(addr string) { inflightBinlogsGauge.WithLabelValues(addr).Inc() }
+func inflightDec(addr string) { inflightBinlogsGauge.WithLabelValues(addr).Dec() }
+
+// DroppedOnIgnoreCount returns the current dropped-on-ignore counter value
+// for addr, for use by tests; it is 0 for an address with no recorded
+// drops, including one whose series was just cleared by ResetPumpMetrics.
+func DroppedOnIgnoreCount(addr string) float64 {
+	return readCounter(droppedOnIgnoreCounter.WithLabelValues(addr))
+}
+
+// ConnState returns the current pump_connection_state gauge value for addr,
+// for use by tests.
+func ConnState(addr string) float64 {
+	return readGauge(connStateGauge.WithLabelValues(addr))
+}
+
+func readCounter(c prometheus.Counter) float64 {
+	var m dto.Metric
+	_ = c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+func readGauge(g prometheus.Gauge) float64 {
+	var m dto.Metric
+	_ = g.Write(&m)
+	return m.GetGauge().GetValue()
+}
@@ -0,0 +1,298 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codec implements a compact binary wire format for shipping
+// statistics.Histogram (both column histograms and the Histogram embedded
+// in statistics.Index) between TiDB and TiKV, and for the on-disk stats
+// cache, in place of the row-by-row HistogramToProto encoding.
+//
+// Three things make the protobuf encoding bigger than it needs to be, and
+// this codec targets each one directly:
+//
+//   - Bucket.Count is strictly increasing, so consecutive counts differ by
+//     a small, usually-similar amount: delta-of-delta plus ZigZag varint
+//     encoding turns that into a handful of small-magnitude varints instead
+//     of N full-width cumulative counts.
+//   - Integer-typed bucket bounds are also monotonically increasing, so the
+//     same delta-of-delta treatment applies to them.
+//   - Index bucket bounds are encoded index keys that share a long common
+//     prefix (table ID + index ID, and often leading column values too);
+//     a shared string table records each distinct prefix once instead of
+//     repeating it in every bucket bound.
+//
+// CMSketch is not encoded here: its field layout isn't part of this
+// package slice, so only Histogram (column and index) round-trips through
+// this codec for now.
+package codec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/types"
+)
+
+// prefixTableLen is how many leading bytes of each bound are looked up in
+// the shared string table. Index bucket bounds share at least a table-ID +
+// index-ID prefix (see tablecodec's index key layout), which comfortably
+// fits in this many bytes; bounds shorter than this are stored in full and
+// never shared.
+const prefixTableLen = 16
+
+// Encode writes h to w in the delta-of-delta compact format.
+func Encode(w io.Writer, h *statistics.Histogram) error {
+	bw := bufio.NewWriter(w)
+	if err := encode(bw, h); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(bw.Flush())
+}
+
+// Decode reads a Histogram previously written by Encode.
+func Decode(r io.Reader) (*statistics.Histogram, error) {
+	br := bufio.NewReader(r)
+	return decode(br)
+}
+
+func encode(w *bufio.Writer, h *statistics.Histogram) error {
+	isInt := isIntegerType(h)
+	if err := writeVarint(w, int64(h.ID)); err != nil {
+		return err
+	}
+	if err := writeVarint(w, h.NDV); err != nil {
+		return err
+	}
+	if err := writeVarint(w, h.NullCount); err != nil {
+		return err
+	}
+	if err := writeVarint(w, int64(h.Len())); err != nil {
+		return err
+	}
+	if err := writeBool(w, isInt); err != nil {
+		return err
+	}
+
+	// Counts: delta-of-delta + ZigZag varint. prevCount/prevDelta start at
+	// 0, matching an implicit empty bucket before the first real one.
+	var prevCount, prevDelta int64
+	for i := 0; i < h.Len(); i++ {
+		count := h.Buckets[i].Count
+		delta := count - prevCount
+		dod := delta - prevDelta
+		if err := writeZigZag(w, dod); err != nil {
+			return err
+		}
+		if err := writeVarint(w, h.Buckets[i].Repeat); err != nil {
+			return err
+		}
+		prevDelta, prevCount = delta, count
+	}
+
+	// Bounds: integer types get the same delta-of-delta treatment as
+	// counts; everything else (including index keys) goes through the
+	// shared-prefix table.
+	table := newPrefixTable()
+	var lower, upper types.Datum
+	if isInt {
+		var prevLow, prevLowDelta, prevUp, prevUpDelta int64
+		for i := 0; i < h.Len(); i++ {
+			h.LowerInto(i, &lower)
+			h.UpperInto(i, &upper)
+			lv, err := datumToInt64(lower)
+			if err != nil {
+				return err
+			}
+			uv, err := datumToInt64(upper)
+			if err != nil {
+				return err
+			}
+			lDelta := lv - prevLow
+			uDelta := uv - prevUp
+			if err := writeZigZag(w, lDelta-prevLowDelta); err != nil {
+				return err
+			}
+			if err := writeZigZag(w, uDelta-prevUpDelta); err != nil {
+				return err
+			}
+			prevLowDelta, prevLow = lDelta, lv
+			prevUpDelta, prevUp = uDelta, uv
+		}
+		return nil
+	}
+	for i := 0; i < h.Len(); i++ {
+		h.LowerInto(i, &lower)
+		h.UpperInto(i, &upper)
+		if err := table.encodeDatum(w, lower); err != nil {
+			return err
+		}
+		if err := table.encodeDatum(w, upper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decode(r *bufio.Reader) (*statistics.Histogram, error) {
+	id, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	ndv, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	nullCount, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	numBuckets, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	isInt, err := readBool(r)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, numBuckets)
+	repeats := make([]int64, numBuckets)
+	var prevCount, prevDelta int64
+	for i := int64(0); i < numBuckets; i++ {
+		dod, err := readZigZag(r)
+		if err != nil {
+			return nil, err
+		}
+		repeat, err := readVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		delta := prevDelta + dod
+		count := prevCount + delta
+		counts[i] = count
+		repeats[i] = repeat
+		prevDelta, prevCount = delta, count
+	}
+
+	var tp *types.FieldType
+	if isInt {
+		tp = types.NewFieldType(intHistogramMySQLType)
+	} else {
+		tp = types.NewFieldType(bytesHistogramMySQLType)
+	}
+	h := statistics.NewHistogram(id, ndv, nullCount, 0, tp, int(numBuckets), 0)
+
+	if isInt {
+		var prevLow, prevLowDelta, prevUp, prevUpDelta int64
+		for i := int64(0); i < numBuckets; i++ {
+			lDod, err := readZigZag(r)
+			if err != nil {
+				return nil, err
+			}
+			uDod, err := readZigZag(r)
+			if err != nil {
+				return nil, err
+			}
+			lDelta := prevLowDelta + lDod
+			uDelta := prevUpDelta + uDod
+			lv := prevLow + lDelta
+			uv := prevUp + uDelta
+			lower := types.NewIntDatum(lv)
+			upper := types.NewIntDatum(uv)
+			h.AppendBucket(&lower, &upper, counts[i], repeats[i])
+			prevLowDelta, prevLow = lDelta, lv
+			prevUpDelta, prevUp = uDelta, uv
+		}
+		return h, nil
+	}
+	table := newPrefixTable()
+	for i := int64(0); i < numBuckets; i++ {
+		lower, err := table.decodeDatum(r)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := table.decodeDatum(r)
+		if err != nil {
+			return nil, err
+		}
+		h.AppendBucket(lower, upper, counts[i], repeats[i])
+	}
+	return h, nil
+}
+
+// isIntegerType reports whether h's bucket bounds are integers, the case
+// this codec gives delta-of-delta bound encoding to instead of the
+// shared-prefix table.
+func isIntegerType(h *statistics.Histogram) bool {
+	if h.Len() == 0 {
+		return false
+	}
+	lower := h.GetLower(0)
+	switch lower.Kind() {
+	case types.KindInt64, types.KindUint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func datumToInt64(d types.Datum) (int64, error) {
+	switch d.Kind() {
+	case types.KindInt64:
+		return d.GetInt64(), nil
+	case types.KindUint64:
+		return int64(d.GetUint64()), nil
+	default:
+		return 0, errors.Errorf("codec: expected an integer bound, got kind %d", d.Kind())
+	}
+}
+
+func writeVarint(w *bufio.Writer, v int64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return errors.Trace(err)
+}
+
+func writeZigZag(w *bufio.Writer, v int64) error {
+	return writeVarint(w, v)
+}
+
+func readVarint(r *bufio.Reader) (int64, error) {
+	v, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return v, nil
+}
+
+func readZigZag(r *bufio.Reader) (int64, error) {
+	return readVarint(r)
+}
+
+func writeBool(w *bufio.Writer, b bool) error {
+	if b {
+		return w.WriteByte(1)
+	}
+	return w.WriteByte(0)
+}
+
+func readBool(r *bufio.Reader) (bool, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return b != 0, nil
+}
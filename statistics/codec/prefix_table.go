@@ -0,0 +1,179 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+// intHistogramMySQLType/bytesHistogramMySQLType are the placeholder field
+// types Decode gives a reconstructed Histogram, mirroring how
+// HistogramFromProto always reconstructs a mysql.TypeBlob field type for
+// index histograms: the original column's exact type isn't recoverable
+// from the wire bytes alone, only whether bounds are integers or not.
+const (
+	intHistogramMySQLType   = mysql.TypeLonglong
+	bytesHistogramMySQLType = mysql.TypeBlob
+)
+
+// datumKind tags what kind of value a non-integer bound's remainder bytes
+// decode back into.
+type datumKind byte
+
+const (
+	datumKindBytes datumKind = iota
+	datumKindString
+)
+
+// prefixTable is the shared string table this codec's doc comment
+// describes: index bucket bounds overwhelmingly share a leading table-ID +
+// index-ID (and often more) prefix, so recording each distinct
+// prefixTableLen-byte prefix once and referencing it by index, instead of
+// repeating it in every bucket bound, is where most of the wire-size win
+// over the row-by-row protobuf encoding comes from.
+type prefixTable struct {
+	entries [][]byte
+	index   map[string]int32
+}
+
+func newPrefixTable() *prefixTable {
+	return &prefixTable{index: make(map[string]int32)}
+}
+
+// encodeDatum writes d's kind, shared-prefix reference, and remainder
+// bytes. Kinds without a natural byte representation (decimal, time, ...)
+// fall back to their string form, the same degrade-gracefully choice
+// histogram_snapshot.go's snapshotKindOther makes.
+func (t *prefixTable) encodeDatum(w *bufio.Writer, d types.Datum) error {
+	var kind datumKind
+	var b []byte
+	switch d.Kind() {
+	case types.KindBytes:
+		kind = datumKindBytes
+		b = d.GetBytes()
+	case types.KindString:
+		kind = datumKindString
+		b = d.GetBytes()
+	default:
+		s, err := d.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		kind = datumKindString
+		b = []byte(s)
+	}
+	if err := w.WriteByte(byte(kind)); err != nil {
+		return errors.Trace(err)
+	}
+
+	pfxLen := len(b)
+	if pfxLen > prefixTableLen {
+		pfxLen = prefixTableLen
+	}
+	prefix := b[:pfxLen]
+	remainder := b[pfxLen:]
+
+	if ref, ok := t.index[string(prefix)]; ok {
+		if err := writeVarint(w, int64(ref)); err != nil {
+			return err
+		}
+	} else {
+		ref := int32(len(t.entries))
+		stored := append([]byte(nil), prefix...)
+		t.entries = append(t.entries, stored)
+		t.index[string(stored)] = ref
+		if err := writeVarint(w, -1); err != nil {
+			return err
+		}
+		if err := writeVarint(w, int64(len(prefix))); err != nil {
+			return err
+		}
+		if _, err := w.Write(prefix); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := writeVarint(w, int64(len(remainder))); err != nil {
+		return err
+	}
+	if len(remainder) > 0 {
+		if _, err := w.Write(remainder); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// decodeDatum is encodeDatum's inverse.
+func (t *prefixTable) decodeDatum(r *bufio.Reader) (*types.Datum, error) {
+	kindByte, err := r.ReadByte()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ref, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	var prefix []byte
+	if ref == -1 {
+		pfxLen, err := readVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		prefix = make([]byte, pfxLen)
+		if _, err := readFull(r, prefix); err != nil {
+			return nil, err
+		}
+		t.entries = append(t.entries, prefix)
+	} else {
+		if int(ref) >= len(t.entries) {
+			return nil, errors.Errorf("codec: prefix table reference %d out of range", ref)
+		}
+		prefix = t.entries[ref]
+	}
+	remLen, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	remainder := make([]byte, remLen)
+	if remLen > 0 {
+		if _, err := readFull(r, remainder); err != nil {
+			return nil, err
+		}
+	}
+	full := append(append([]byte(nil), prefix...), remainder...)
+
+	var d types.Datum
+	switch datumKind(kindByte) {
+	case datumKindBytes:
+		d.SetBytes(full)
+	case datumKindString:
+		d.SetString(string(full), "")
+	default:
+		return nil, errors.Errorf("codec: unknown datum kind %d", kindByte)
+	}
+	return &d, nil
+}
+
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err != nil {
+		return n, errors.Trace(err)
+	}
+	return n, nil
+}
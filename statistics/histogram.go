@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -69,6 +70,14 @@ type Histogram struct {
 	// the column values. This ranges from -1 to +1, and it is only valid for Column histogram, not for
 	// Index histogram.
 	Correlation float64
+
+	// HLLSketch is an optional HyperLogLog sketch populated at analyze time.
+	// MergePartitionHistograms unions partitions' sketches to get an exact
+	// (no double-counting of values seen in more than one partition) global
+	// NDV estimate; when a partition's histogram predates this field, the
+	// merge falls back to a bound-overlap heuristic instead. See
+	// merge_partition.go.
+	HLLSketch *HLLSketch
 }
 
 // Bucket store the bucket count and repeat.
@@ -109,6 +118,80 @@ func (hg *Histogram) GetUpper(idx int) *types.Datum {
 	return &d
 }
 
+// LowerInto writes the lower bound of bucket `idx` into dst, avoiding the
+// fresh-Datum allocation GetLower makes on every call. Callers that walk
+// every bucket (mergeBuckets, MergeHistograms) reuse one dst across the
+// whole loop instead.
+func (hg *Histogram) LowerInto(idx int, dst *types.Datum) {
+	*dst = hg.Bounds.GetRow(2 * idx).GetDatum(0, hg.Tp)
+}
+
+// UpperInto writes the upper bound of bucket `idx` into dst; see LowerInto.
+func (hg *Histogram) UpperInto(idx int, dst *types.Datum) {
+	*dst = hg.Bounds.GetRow(2*idx + 1).GetDatum(0, hg.Tp)
+}
+
+// BucketIter walks a Histogram's buckets without allocating a Datum per
+// bucket: Current writes into caller-owned dstLower/dstUpper, so a caller
+// that needs to visit every bucket (as mergeBuckets and MergeHistograms do)
+// can declare its two scratch Datums once outside the loop.
+type BucketIter struct {
+	hg  *Histogram
+	idx int
+}
+
+// Reset rebinds it to walk hg from bucket 0.
+func (it *BucketIter) Reset(hg *Histogram) {
+	it.hg = hg
+	it.idx = -1
+}
+
+// Next advances to the next bucket, returning false once buckets are
+// exhausted.
+func (it *BucketIter) Next() bool {
+	it.idx++
+	return it.idx < it.hg.Len()
+}
+
+// Current writes the current bucket's lower and upper bound into
+// dstLower/dstUpper.
+func (it *BucketIter) Current(dstLower, dstUpper *types.Datum) {
+	it.hg.LowerInto(it.idx, dstLower)
+	it.hg.UpperInto(it.idx, dstUpper)
+}
+
+// histogramPool recycles *Histogram scratch objects used as intermediate
+// results by Copy/TruncateHistogram/ConvertTo, so repeated background stats
+// loads and feedback merges don't pressure the GC with one throwaway
+// Histogram per call. Pooled objects are returned via CopyTo, never handed
+// back to callers directly, since their Bounds/Buckets capacity is reused
+// in place.
+var histogramPool = sync.Pool{
+	New: func() interface{} { return &Histogram{} },
+}
+
+// getPooledHistogram returns a scratch *Histogram from histogramPool; the
+// caller must return it with putPooledHistogram once done.
+func getPooledHistogram() *Histogram {
+	return histogramPool.Get().(*Histogram)
+}
+
+// putPooledHistogram returns hg to histogramPool for reuse.
+func putPooledHistogram(hg *Histogram) {
+	histogramPool.Put(hg)
+}
+
+// CopyTo overwrites dst with hg's contents, reusing dst.Bounds' underlying
+// storage via CopyConstruct instead of allocating a brand new Histogram the
+// way Copy does. Intended for long-running background stats loads and
+// feedback merging that copy histograms repeatedly and can afford to own a
+// persistent dst across calls.
+func (hg *Histogram) CopyTo(dst *Histogram) {
+	*dst = *hg
+	dst.Bounds = hg.Bounds.CopyConstruct()
+	dst.Buckets = append(dst.Buckets[:0], hg.Buckets...)
+}
+
 // AvgColSize is the average column size of the histogram.
 func (c *Column) AvgColSize(count int64) float64 {
 	if count == 0 {
@@ -343,16 +426,21 @@ func (hg *Histogram) notNullCount() float64 {
 func (hg *Histogram) mergeBuckets(bucketIdx int) {
 	curBuck := 0
 	c := chunk.NewChunkWithCapacity([]*types.FieldType{hg.Tp}, bucketIdx)
+	var lower, upper types.Datum
 	for i := 0; i+1 <= bucketIdx; i += 2 {
 		hg.Buckets[curBuck] = hg.Buckets[i+1]
-		c.AppendDatum(0, hg.GetLower(i))
-		c.AppendDatum(0, hg.GetUpper(i+1))
+		hg.LowerInto(i, &lower)
+		hg.UpperInto(i+1, &upper)
+		c.AppendDatum(0, &lower)
+		c.AppendDatum(0, &upper)
 		curBuck++
 	}
 	if bucketIdx%2 == 0 {
 		hg.Buckets[curBuck] = hg.Buckets[bucketIdx]
-		c.AppendDatum(0, hg.GetLower(bucketIdx))
-		c.AppendDatum(0, hg.GetUpper(bucketIdx))
+		hg.LowerInto(bucketIdx, &lower)
+		hg.UpperInto(bucketIdx, &upper)
+		c.AppendDatum(0, &lower)
+		c.AppendDatum(0, &upper)
 		curBuck++
 	}
 	hg.Bounds = c
@@ -581,8 +669,12 @@ func MergeHistograms(sc *stmtctx.StatementContext, lh *Histogram, rh *Histogram,
 		rh.mergeBuckets(rh.Len() - 1)
 		rAvg *= 2
 	}
-	for i := 0; i < rh.Len(); i++ {
-		lh.AppendBucket(rh.GetLower(i), rh.GetUpper(i), rh.Buckets[i].Count+lCount-offset, rh.Buckets[i].Repeat)
+	var iter BucketIter
+	var lower, upper types.Datum
+	iter.Reset(rh)
+	for i := 0; iter.Next(); i++ {
+		iter.Current(&lower, &upper)
+		lh.AppendBucket(&lower, &upper, rh.Buckets[i].Count+lCount-offset, rh.Buckets[i].Repeat)
 	}
 	for lh.Len() > bucketSize {
 		lh.mergeBuckets(lh.Len() - 1)
@@ -679,6 +771,13 @@ type Column struct {
 	ErrorRate
 	Flag           int64
 	LastAnalyzePos types.Datum
+	// SparseHist, when non-nil, is this column's sparse exponential-bucket
+	// histogram, built for heavy-tailed numeric columns (money, durations,
+	// sizes) where the equi-depth Histogram above collapses the tail into a
+	// handful of buckets and loses resolution exactly where outliers live.
+	// equalRowCount/BetweenRowCount/TotalRowCount consult it ahead of the
+	// equi-depth Histogram when populated.
+	SparseHist *SparseHist
 }
 
 func (c *Column) String() string {
@@ -702,10 +801,36 @@ func (c *Column) IsInvalid(sc *stmtctx.StatementContext, collPseudo bool) bool {
 	return c.TotalRowCount() == 0 || (c.NDV > 0 && c.Len() == 0)
 }
 
+// datumToFloat64 converts val to the float64 domain SparseHist operates in.
+// It only handles the numeric kinds SparseHist is meant for; ok is false
+// for anything else, telling the caller to fall back to the equi-depth
+// Histogram.
+func datumToFloat64(val types.Datum) (f float64, ok bool) {
+	switch val.Kind() {
+	case types.KindInt64:
+		return float64(val.GetInt64()), true
+	case types.KindUint64:
+		return float64(val.GetUint64()), true
+	case types.KindFloat32, types.KindFloat64:
+		return val.GetFloat64(), true
+	case types.KindMysqlDecimal:
+		f, err := val.GetMysqlDecimal().ToFloat64()
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
 func (c *Column) equalRowCount(sc *stmtctx.StatementContext, val types.Datum, modifyCount int64) (float64, error) {
 	if val.IsNull() {
 		return float64(c.NullCount), nil
 	}
+	if c.SparseHist != nil {
+		return c.SparseHist.EqualRowCount(val), nil
+	}
 	// All the values are null.
 	if c.Histogram.Bounds.NumRows() == 0 {
 		return 0.0, nil
@@ -720,59 +845,38 @@ func (c *Column) equalRowCount(sc *stmtctx.StatementContext, val types.Datum, mo
 	return c.Histogram.equalRowCount(val), nil
 }
 
-// GetColumnRowCount estimates the row count by a slice of Range.
+// BetweenRowCount estimates the row count in [a, b), preferring SparseHist
+// when this column was analyzed with it populated.
+func (c *Column) BetweenRowCount(a, b types.Datum) float64 {
+	if c.SparseHist != nil {
+		return c.SparseHist.BetweenRowCount(a, b)
+	}
+	return c.Histogram.BetweenRowCount(a, b)
+}
+
+// TotalRowCount returns the total count of this column, preferring
+// SparseHist when this column was analyzed with it populated.
+func (c *Column) TotalRowCount() float64 {
+	if c.SparseHist != nil {
+		return c.SparseHist.TotalRowCount() + float64(c.NullCount)
+	}
+	return c.Histogram.TotalRowCount()
+}
+
+// GetColumnRowCount estimates the row count by a slice of Range. It walks
+// ranges through a pooled RangeEstimator (see range_estimator.go) so that
+// selectivity loops calling this once per candidate access path don't
+// allocate scratch state proportional to len(ranges) on every call.
 func (c *Column) GetColumnRowCount(sc *stmtctx.StatementContext, ranges []*ranger.Range, modifyCount int64) (float64, error) {
-	var rowCount float64
-	for _, rg := range ranges {
-		cmp, err := rg.LowVal[0].CompareDatum(sc, &rg.HighVal[0])
-		if err != nil {
-			return 0, errors.Trace(err)
-		}
-		if cmp == 0 {
-			// the point case.
-			if !rg.LowExclude && !rg.HighExclude {
-				var cnt float64
-				cnt, err = c.equalRowCount(sc, rg.LowVal[0], modifyCount)
-				if err != nil {
-					return 0, errors.Trace(err)
-				}
-				rowCount += cnt
-			}
-			continue
-		}
-		// The interval case.
-		cnt := c.BetweenRowCount(rg.LowVal[0], rg.HighVal[0])
-		if (c.outOfRange(rg.LowVal[0]) && !rg.LowVal[0].IsNull()) || c.outOfRange(rg.HighVal[0]) {
-			cnt += float64(modifyCount) / outOfRangeBetweenRate
-		}
-		// `betweenRowCount` returns count for [l, h) range, we adjust cnt for boudaries here.
-		// Note that, `cnt` does not include null values, we need specially handle cases
-		// where null is the lower bound.
-		if rg.LowExclude && !rg.LowVal[0].IsNull() {
-			lowCnt, err := c.equalRowCount(sc, rg.LowVal[0], modifyCount)
-			if err != nil {
-				return 0, errors.Trace(err)
-			}
-			cnt -= lowCnt
-		}
-		if !rg.LowExclude && rg.LowVal[0].IsNull() {
-			cnt += float64(c.NullCount)
-		}
-		if !rg.HighExclude {
-			highCnt, err := c.equalRowCount(sc, rg.HighVal[0], modifyCount)
-			if err != nil {
-				return 0, errors.Trace(err)
-			}
-			cnt += highCnt
-		}
-		rowCount += cnt
+	est := getRangeEstimator()
+	defer putRangeEstimator(est)
+	est.ResetColumn(c, sc, ranges, modifyCount)
+	for est.Next() {
 	}
-	if rowCount > c.TotalRowCount() {
-		rowCount = c.TotalRowCount()
-	} else if rowCount < 0 {
-		rowCount = 0
+	if err := est.Err(); err != nil {
+		return 0, errors.Trace(err)
 	}
-	return rowCount, nil
+	return est.RowCount(), nil
 }
 
 // Index represents an index histogram.
@@ -784,6 +888,14 @@ type Index struct {
 	Info           *model.IndexInfo
 	Flag           int64
 	LastAnalyzePos types.Datum
+	// PrefixStats holds, for each leading-column prefix length 1..len(Info.Columns),
+	// the min/max encoded bound and NDV collected at analyze time. It lets
+	// outOfRange and equalRowCount reason about a probe that only binds a
+	// prefix of a composite index (see index_prefix_stats.go) instead of
+	// falling back to a full-range guess. Stats loaded from before this field
+	// existed leave it nil, and every consumer falls back to the previous
+	// whole-key behavior in that case.
+	PrefixStats []PrefixStat
 }
 
 func (idx *Index) String() string {
@@ -815,53 +927,22 @@ func (idx *Index) equalRowCount(sc *stmtctx.StatementContext, b []byte, modifyCo
 
 // GetRowCount returns the row count of the given ranges.
 // It uses the modifyCount to adjust the influence of modifications on the table.
+//
+// It walks indexRanges through a pooled RangeEstimator (see
+// range_estimator.go): the estimator's lb/rb encode buffers and bound
+// Datums are reused across every range instead of being allocated fresh,
+// which matters here because the planner calls GetRowCount once per index
+// per candidate access path while evaluating selectivity.
 func (idx *Index) GetRowCount(sc *stmtctx.StatementContext, indexRanges []*ranger.Range, modifyCount int64) (float64, error) {
-	totalCount := float64(0)
-	isSingleCol := len(idx.Info.Columns) == 1
-	for _, indexRange := range indexRanges {
-		lb, err := codec.EncodeKey(sc, nil, indexRange.LowVal...)
-		if err != nil {
-			return 0, err
-		}
-		rb, err := codec.EncodeKey(sc, nil, indexRange.HighVal...)
-		if err != nil {
-			return 0, err
-		}
-		fullLen := len(indexRange.LowVal) == len(indexRange.HighVal) && len(indexRange.LowVal) == len(idx.Info.Columns)
-		if bytes.Equal(lb, rb) {
-			if indexRange.LowExclude || indexRange.HighExclude {
-				continue
-			}
-			if fullLen {
-				count, err := idx.equalRowCount(sc, lb, modifyCount)
-				if err != nil {
-					return 0, err
-				}
-				totalCount += count
-				continue
-			}
-		}
-		if indexRange.LowExclude {
-			lb = kv.Key(lb).PrefixNext()
-		}
-		if !indexRange.HighExclude {
-			rb = kv.Key(rb).PrefixNext()
-		}
-		l := types.NewBytesDatum(lb)
-		r := types.NewBytesDatum(rb)
-		totalCount += idx.BetweenRowCount(l, r)
-		lowIsNull := bytes.Equal(lb, nullKeyBytes)
-		if (idx.outOfRange(l) && !(isSingleCol && lowIsNull)) || idx.outOfRange(r) {
-			totalCount += float64(modifyCount) / outOfRangeBetweenRate
-		}
-		if isSingleCol && lowIsNull {
-			totalCount += float64(idx.NullCount)
-		}
+	est := getRangeEstimator()
+	defer putRangeEstimator(est)
+	est.ResetIndex(idx, sc, indexRanges, modifyCount)
+	for est.Next() {
 	}
-	if totalCount > idx.TotalRowCount() {
-		totalCount = idx.TotalRowCount()
+	if err := est.Err(); err != nil {
+		return 0, err
 	}
-	return totalCount, nil
+	return est.RowCount(), nil
 }
 
 type countByRangeFunc = func(*stmtctx.StatementContext, int64, []*ranger.Range) (float64, error)
@@ -985,6 +1066,25 @@ func (coll *HistColl) NewHistCollBySelectivity(sc *stmtctx.StatementContext, sta
 		if !ok {
 			continue
 		}
+		if oldCol.SparseHist != nil {
+			// SparseHist's buckets aren't addressable by bound comparison
+			// the way equi-depth Buckets are, so approximate the filtered
+			// result by scaling every bucket down by this node's
+			// selectivity instead of walking node.Ranges bucket by bucket.
+			newColl.Columns[node.ID] = &Column{
+				Histogram:      Histogram{NullCount: oldCol.NullCount},
+				PhysicalID:     oldCol.PhysicalID,
+				Count:          oldCol.Count,
+				Info:           oldCol.Info,
+				IsHandle:       oldCol.IsHandle,
+				ErrorRate:      oldCol.ErrorRate,
+				Flag:           oldCol.Flag,
+				LastAnalyzePos: oldCol.LastAnalyzePos,
+				CMSketch:       oldCol.CMSketch,
+				SparseHist:     oldCol.SparseHist.FilterBySelectivity(node.Selectivity),
+			}
+			continue
+		}
 		newCol := &Column{
 			PhysicalID: oldCol.PhysicalID,
 			Info:       oldCol.Info,
@@ -0,0 +1,241 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"sort"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/types"
+)
+
+// errEmptyHistogram is returned by the quantile APIs when the histogram has
+// no buckets to interpolate within.
+var errEmptyHistogram = errors.New("histogram has no buckets")
+
+// Bracket is one point on a Histogram's CDF: at Quantile Count rows have a
+// value no greater than ValueAt, which is the upper bound of the bucket the
+// bracket describes.
+type Bracket struct {
+	Quantile float64
+	Count    int64
+	ValueAt  types.Datum
+}
+
+// ValueAtQuantile estimates the value below which fraction q of the
+// histogram's non-null rows fall, by binary-searching the cumulative
+// Buckets[i].Count array for the bucket target = q*notNullCount() falls in
+// and linearly interpolating within it. It's a pivot-selection and
+// quick-pXX building block for the planner (parallel range scan pivots,
+// SHOW STATS_HISTOGRAMS percentiles, join-order heuristics for range
+// predicates), not an exact order-statistic.
+func (hg *Histogram) ValueAtQuantile(q float64) (types.Datum, error) {
+	if hg.Len() == 0 {
+		return types.Datum{}, errors.Trace(errEmptyHistogram)
+	}
+	if q <= 0 {
+		return *hg.GetLower(0), nil
+	}
+	if q >= 1 {
+		return *hg.GetUpper(hg.Len() - 1), nil
+	}
+	target := q * hg.notNullCount()
+	idx := sort.Search(hg.Len(), func(i int) bool {
+		return float64(hg.Buckets[i].Count) >= target
+	})
+	if idx >= hg.Len() {
+		idx = hg.Len() - 1
+	}
+	preCount := float64(0)
+	if idx > 0 {
+		preCount = float64(hg.Buckets[idx-1].Count)
+	}
+	curCount := float64(hg.Buckets[idx].Count)
+	frac := 0.5
+	if curCount > preCount {
+		frac = (target - preCount) / (curCount - preCount)
+	}
+	return hg.valueAtFraction(idx, frac), nil
+}
+
+// Quantiles batches ValueAtQuantile over qs, reusing nothing special beyond
+// what ValueAtQuantile itself does -- it exists so callers that want several
+// percentiles (e.g. SHOW STATS_HISTOGRAMS) don't re-walk the same Buckets
+// slice once per call site by hand.
+func (hg *Histogram) Quantiles(qs []float64) ([]types.Datum, error) {
+	result := make([]types.Datum, 0, len(qs))
+	for _, q := range qs {
+		d, err := hg.ValueAtQuantile(q)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// CumulativeDistribution returns the histogram's CDF sampled at every
+// bucket boundary: bracket i says that Buckets[i].Count rows (a Quantile
+// fraction of notNullCount) have a value no greater than bucket i's upper
+// bound.
+func (hg *Histogram) CumulativeDistribution() []Bracket {
+	total := hg.notNullCount()
+	brackets := make([]Bracket, 0, hg.Len())
+	for i := 0; i < hg.Len(); i++ {
+		q := 0.0
+		if total > 0 {
+			q = float64(hg.Buckets[i].Count) / total
+		}
+		brackets = append(brackets, Bracket{
+			Quantile: q,
+			Count:    hg.Buckets[i].Count,
+			ValueAt:  *hg.GetUpper(i),
+		})
+	}
+	return brackets
+}
+
+// calcFraction estimates how far value lies between bucket index's lower
+// and upper bound, as a fraction in [0, 1]. Numeric and time types
+// interpolate on a float64 projection of the bound; strings/bytes
+// interpolate lexicographically on the bytes remaining after their common
+// prefix, the same commonPfxLen trick already used elsewhere for
+// KindString/KindBytes bucket bounds.
+func (hg *Histogram) calcFraction(index int, value *types.Datum) float64 {
+	lower, upper := hg.GetLower(index), hg.GetUpper(index)
+	if lf, lok := datumToFloat64(*lower); lok {
+		if uf, uok := datumToFloat64(*upper); uok {
+			if vf, vok := datumToFloat64(*value); vok {
+				return fractionOf(vf, lf, uf)
+			}
+		}
+	}
+	switch value.Kind() {
+	case types.KindString, types.KindBytes:
+		lb, ub, vb := lower.GetBytes(), upper.GetBytes(), value.GetBytes()
+		pfx := commonPrefixLen(lb, ub)
+		lf, uf, vf := bytesToScalar(lb, pfx), bytesToScalar(ub, pfx), bytesToScalar(vb, pfx)
+		return fractionOf(vf, lf, uf)
+	}
+	return 0.5
+}
+
+// valueAtFraction is calcFraction's inverse: given a fraction within bucket
+// idx, it projects a Datum back out of the bucket's [lower, upper] range.
+// Kinds calcFraction can't project onto a float64 (decimal, time, duration)
+// fall back to returning the bucket's upper bound, which is still a useful
+// -- if coarser -- pivot than failing outright.
+func (hg *Histogram) valueAtFraction(idx int, frac float64) types.Datum {
+	lower, upper := hg.GetLower(idx), hg.GetUpper(idx)
+	if frac <= 0 {
+		return *lower
+	}
+	if frac >= 1 {
+		return *upper
+	}
+	switch lower.Kind() {
+	case types.KindInt64:
+		v := lower.GetInt64() + int64(frac*float64(upper.GetInt64()-lower.GetInt64()))
+		return types.NewIntDatum(v)
+	case types.KindUint64:
+		v := lower.GetUint64() + uint64(frac*float64(upper.GetUint64()-lower.GetUint64()))
+		return types.NewUintDatum(v)
+	case types.KindFloat32, types.KindFloat64:
+		v := lower.GetFloat64() + frac*(upper.GetFloat64()-lower.GetFloat64())
+		return types.NewFloat64Datum(v)
+	case types.KindString, types.KindBytes:
+		lb, ub := lower.GetBytes(), upper.GetBytes()
+		pfx := commonPrefixLen(lb, ub)
+		lf, uf := bytesToScalar(lb, pfx), bytesToScalar(ub, pfx)
+		vf := lf + frac*(uf-lf)
+		suffix := scalarToBytes(vf)
+		result := append(append([]byte{}, lb[:pfx]...), suffix...)
+		if lower.Kind() == types.KindString {
+			return types.NewStringDatum(string(result))
+		}
+		return types.NewBytesDatum(result)
+	default:
+		return *upper
+	}
+}
+
+// fractionOf reports where v falls between lo and hi as a fraction in
+// [0, 1], clamped at the ends; lo == hi (a single-point bucket) returns 0.5
+// since there's nothing to interpolate.
+func fractionOf(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0.5
+	}
+	frac := (v - lo) / (hi - lo)
+	if frac < 0 {
+		return 0
+	}
+	if frac > 1 {
+		return 1
+	}
+	return frac
+}
+
+// commonPrefixLen returns how many leading bytes a and b share.
+func commonPrefixLen(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// bytesToScalar projects the bytes of b after its first skip bytes into a
+// float64 ordered consistently with byte-lexicographic comparison, by
+// treating up to the first 8 remaining bytes as a big-endian integer.
+func bytesToScalar(b []byte, skip int) float64 {
+	if skip < len(b) {
+		b = b[skip:]
+	} else {
+		b = nil
+	}
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v <<= 8
+		if i < len(b) {
+			v |= uint64(b[i])
+		}
+	}
+	return float64(v)
+}
+
+// scalarToBytes is bytesToScalar's inverse: it reconstructs up to 8 bytes
+// from the big-endian integer v rounds to.
+func scalarToBytes(v float64) []byte {
+	if v < 0 {
+		v = 0
+	}
+	u := uint64(v)
+	buf := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		buf[i] = byte(u)
+		u >>= 8
+	}
+	// Trim trailing zero bytes so we don't pad every reconstructed value out
+	// to a full 8 bytes.
+	end := len(buf)
+	for end > 0 && buf[end-1] == 0 {
+		end--
+	}
+	return buf[:end]
+}
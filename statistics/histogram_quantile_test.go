@@ -0,0 +1,107 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+var _ = Suite(&testHistogramQuantileSuite{})
+
+type testHistogramQuantileSuite struct{}
+
+func intHistogram(c *C, buckets [][3]int64) *Histogram {
+	// buckets is (lower, upper, cumulative count) per bucket.
+	tp := types.NewFieldType(mysql.TypeLonglong)
+	hg := NewHistogram(1, 0, 0, 0, tp, len(buckets), 0)
+	for _, b := range buckets {
+		lower := types.NewIntDatum(b[0])
+		upper := types.NewIntDatum(b[1])
+		hg.AppendBucket(&lower, &upper, b[2], 1)
+	}
+	return hg
+}
+
+// TestValueAtQuantileBoundaries checks q<=0 and q>=1 return the first
+// bucket's lower bound and the last bucket's upper bound exactly, without
+// going through the binary-search interpolation path.
+func (s *testHistogramQuantileSuite) TestValueAtQuantileBoundaries(c *C) {
+	hg := intHistogram(c, [][3]int64{{0, 10, 5}, {11, 20, 10}})
+	lo, err := hg.ValueAtQuantile(0)
+	c.Assert(err, IsNil)
+	c.Assert(lo.GetInt64(), Equals, int64(0))
+
+	hi, err := hg.ValueAtQuantile(1)
+	c.Assert(err, IsNil)
+	c.Assert(hi.GetInt64(), Equals, int64(20))
+}
+
+// TestValueAtQuantileMidpoint checks that the median of a single evenly-
+// populated bucket interpolates to roughly its midpoint.
+func (s *testHistogramQuantileSuite) TestValueAtQuantileMidpoint(c *C) {
+	hg := intHistogram(c, [][3]int64{{0, 100, 100}})
+	v, err := hg.ValueAtQuantile(0.5)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetInt64(), Equals, int64(50))
+}
+
+// TestValueAtQuantileEmptyHistogramErrors checks that a histogram with no
+// buckets reports errEmptyHistogram rather than panicking on an
+// out-of-range bucket index.
+func (s *testHistogramQuantileSuite) TestValueAtQuantileEmptyHistogramErrors(c *C) {
+	hg := NewHistogram(1, 0, 0, 0, types.NewFieldType(mysql.TypeLonglong), 0, 0)
+	_, err := hg.ValueAtQuantile(0.5)
+	c.Assert(err, NotNil)
+}
+
+// TestQuantilesBatchesValueAtQuantile checks that Quantiles returns one
+// result per requested quantile, in the same order as the input.
+func (s *testHistogramQuantileSuite) TestQuantilesBatchesValueAtQuantile(c *C) {
+	hg := intHistogram(c, [][3]int64{{0, 100, 100}})
+	got, err := hg.Quantiles([]float64{0, 0.5, 1})
+	c.Assert(err, IsNil)
+	c.Assert(got, HasLen, 3)
+	c.Assert(got[0].GetInt64(), Equals, int64(0))
+	c.Assert(got[2].GetInt64(), Equals, int64(100))
+}
+
+// TestCumulativeDistributionReportsFractionPerBucket checks that the CDF
+// sample at the last bucket reaches quantile 1.0, since its cumulative
+// count equals the histogram's total non-null count.
+func (s *testHistogramQuantileSuite) TestCumulativeDistributionReportsFractionPerBucket(c *C) {
+	hg := intHistogram(c, [][3]int64{{0, 10, 5}, {11, 20, 10}})
+	brackets := hg.CumulativeDistribution()
+	c.Assert(brackets, HasLen, 2)
+	c.Assert(brackets[0].Quantile, Equals, 0.5)
+	c.Assert(brackets[1].Quantile, Equals, 1.0)
+}
+
+// TestCommonPrefixLenSharedAndDisjoint checks the shared-prefix length
+// helper against a partial match and a totally disjoint pair.
+func (s *testHistogramQuantileSuite) TestCommonPrefixLenSharedAndDisjoint(c *C) {
+	c.Assert(commonPrefixLen([]byte("abcdef"), []byte("abcxyz")), Equals, 3)
+	c.Assert(commonPrefixLen([]byte("abc"), []byte("xyz")), Equals, 0)
+}
+
+// TestFractionOfClampsOutOfRangeAndHandlesDegenerate checks fractionOf
+// clamps values outside [lo, hi] to [0, 1] and returns 0.5 for a
+// degenerate single-point bucket (lo == hi).
+func (s *testHistogramQuantileSuite) TestFractionOfClampsOutOfRangeAndHandlesDegenerate(c *C) {
+	c.Assert(fractionOf(-5, 0, 10), Equals, 0.0)
+	c.Assert(fractionOf(15, 0, 10), Equals, 1.0)
+	c.Assert(fractionOf(5, 0, 10), Equals, 0.5)
+	c.Assert(fractionOf(3, 7, 7), Equals, 0.5)
+}
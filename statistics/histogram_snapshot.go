@@ -0,0 +1,356 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/types"
+)
+
+// snapshotMagic/snapshotVersion1 identify SnapshotV1's header so a reader
+// can reject truncated or foreign input before trusting the rest of the
+// buffer, the same role HDR's own Snapshot header magic plays.
+const (
+	snapshotMagic      uint32 = 0x48495354 // "HIST"
+	snapshotVersion1   byte   = 1
+	snapshotCRCPolyLen        = 4
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// MarshalSnapshot encodes hg into SnapshotV1, a compact binary format that,
+// unlike HistogramToProto, round-trips every field needed to resume
+// estimation exactly where analyze left off: Tp, NullCount, TotColSize,
+// Correlation, and LastUpdateVersion, not just bucket counts and
+// bytes-typed bounds. Bucket counts are delta-encoded (successive
+// Buckets[i].Count differences) since those deltas are small and highly
+// repetitive compared to the raw cumulative counts, and the whole buffer
+// ends in a CRC32C so a truncated or bit-flipped snapshot is caught at load
+// time instead of silently mis-estimating selectivity.
+func (hg *Histogram) MarshalSnapshot() ([]byte, error) {
+	buf := make([]byte, 0, 128+16*hg.Len())
+	buf = appendUint32(buf, snapshotMagic)
+	buf = append(buf, snapshotVersion1)
+	buf = append(buf, hg.Tp.Tp)
+	buf = appendSnapshotVarints(buf, hg.ID, hg.NDV, hg.NullCount, hg.TotColSize, int64(hg.LastUpdateVersion))
+	buf = appendUint64(buf, math.Float64bits(hg.Correlation))
+	buf = appendSnapshotVarints(buf, int64(hg.Len()))
+
+	// Counts stream: delta-encoded, since Buckets[i].Count is cumulative
+	// and monotonically increasing.
+	prev := int64(0)
+	for i := 0; i < hg.Len(); i++ {
+		buf = appendSnapshotVarints(buf, hg.Buckets[i].Count-prev)
+		prev = hg.Buckets[i].Count
+	}
+	// Repeats stream.
+	for i := 0; i < hg.Len(); i++ {
+		buf = appendSnapshotVarints(buf, hg.Buckets[i].Repeat)
+	}
+	// Length-prefixed bound blobs, lower then upper per bucket, encoded
+	// with the same snapshotEncodeDatum used by Copy-adjacent snapshot
+	// helpers so the bound's Kind survives the round trip even though
+	// HistogramFromProto's bytes-only bounds can't express it.
+	var lower, upper types.Datum
+	for i := 0; i < hg.Len(); i++ {
+		hg.LowerInto(i, &lower)
+		hg.UpperInto(i, &upper)
+		var err error
+		buf, err = appendSnapshotDatum(buf, &lower)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		buf, err = appendSnapshotDatum(buf, &upper)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	checksum := crc32.Checksum(buf, crc32cTable)
+	buf = appendUint32(buf, checksum)
+	return buf, nil
+}
+
+// UnmarshalSnapshot decodes a buffer produced by MarshalSnapshot, verifying
+// its CRC32C before trusting the rest of the contents.
+func UnmarshalSnapshot(data []byte) (*Histogram, error) {
+	if len(data) < snapshotCRCPolyLen {
+		return nil, errors.New("snapshot: truncated, missing checksum")
+	}
+	body, wantSum := data[:len(data)-snapshotCRCPolyLen], data[len(data)-snapshotCRCPolyLen:]
+	gotSum := crc32.Checksum(body, crc32cTable)
+	if binary.BigEndian.Uint32(wantSum) != gotSum {
+		return nil, errors.New("snapshot: checksum mismatch")
+	}
+
+	rest := body
+	if len(rest) < 5 {
+		return nil, errors.New("snapshot: truncated header")
+	}
+	if binary.BigEndian.Uint32(rest[:4]) != snapshotMagic {
+		return nil, errors.New("snapshot: bad magic")
+	}
+	rest = rest[4:]
+	version := rest[0]
+	rest = rest[1:]
+	if version != snapshotVersion1 {
+		return nil, errors.Errorf("snapshot: unsupported version %d", version)
+	}
+	if len(rest) < 1 {
+		return nil, errors.New("snapshot: truncated type descriptor")
+	}
+	tpByte := rest[0]
+	rest = rest[1:]
+
+	vals, rest, err := readSnapshotVarints(rest, 5)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id, ndv, nullCount, totColSize, lastUpdateVersion := vals[0], vals[1], vals[2], vals[3], vals[4]
+
+	if len(rest) < 8 {
+		return nil, errors.New("snapshot: truncated correlation field")
+	}
+	correlation := math.Float64frombits(binary.BigEndian.Uint64(rest[:8]))
+	rest = rest[8:]
+
+	lenVals, rest, err := readSnapshotVarints(rest, 1)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	numBuckets := int(lenVals[0])
+
+	deltas := make([]int64, numBuckets)
+	for i := 0; i < numBuckets; i++ {
+		v, r, err := readSnapshotVarints(rest, 1)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		deltas[i] = v[0]
+		rest = r
+	}
+	repeats := make([]int64, numBuckets)
+	for i := 0; i < numBuckets; i++ {
+		v, r, err := readSnapshotVarints(rest, 1)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		repeats[i] = v[0]
+		rest = r
+	}
+
+	tp := types.NewFieldType(tpByte)
+	hg := NewHistogram(id, ndv, nullCount, uint64(lastUpdateVersion), tp, numBuckets, totColSize)
+	hg.Correlation = correlation
+	cum := int64(0)
+	for i := 0; i < numBuckets; i++ {
+		cum += deltas[i]
+		var lower, upper *types.Datum
+		lower, rest, err = readSnapshotDatum(rest)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		upper, rest, err = readSnapshotDatum(rest)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		hg.AppendBucket(lower, upper, cum, repeats[i])
+	}
+	return hg, nil
+}
+
+// WriteSnapshot streams hg's SnapshotV1 encoding to w, length-prefixed so
+// ReadSnapshot knows how many bytes to pull off a shared stream (e.g. a
+// stats-cache file holding many histograms back to back).
+func (hg *Histogram) WriteSnapshot(w io.Writer) error {
+	buf, err := hg.MarshalSnapshot()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(buf)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = w.Write(buf)
+	return errors.Trace(err)
+}
+
+// ReadSnapshot reads one length-prefixed SnapshotV1 record written by
+// WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*Histogram, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, errors.Trace(err)
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return UnmarshalSnapshot(buf)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendSnapshotVarints(buf []byte, vals ...int64) []byte {
+	tmp := make([]byte, binary.MaxVarintLen64)
+	for _, v := range vals {
+		n := binary.PutVarint(tmp, v)
+		buf = append(buf, tmp[:n]...)
+	}
+	return buf
+}
+
+func readSnapshotVarints(data []byte, n int) ([]int64, []byte, error) {
+	vals := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		v, size := binary.Varint(data)
+		if size <= 0 {
+			return nil, nil, errors.New("snapshot: truncated varint")
+		}
+		vals = append(vals, v)
+		data = data[size:]
+	}
+	return vals, data, nil
+}
+
+// Datum kind tags for the snapshot bound encoding. These are a private
+// wire vocabulary, independent of types.Kind's own numbering, so the
+// format doesn't break if that numbering ever shifts.
+const (
+	snapshotKindNull byte = iota
+	snapshotKindInt64
+	snapshotKindUint64
+	snapshotKindFloat64
+	snapshotKindBytes
+	snapshotKindString
+	snapshotKindOther
+)
+
+// appendSnapshotDatum appends a length-prefixed, kind-tagged encoding of d.
+// Kinds not explicitly handled (decimal, time, duration, ...) degrade to
+// their string form tagged snapshotKindOther, since SnapshotV1's goal is a
+// lossless round trip for the Kinds Histogram bounds actually use in
+// practice (numeric and string/bytes); exotic kinds still survive the trip
+// as a readable string rather than erroring the whole snapshot out.
+func appendSnapshotDatum(buf []byte, d *types.Datum) ([]byte, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return append(buf, snapshotKindNull), nil
+	case types.KindInt64:
+		buf = append(buf, snapshotKindInt64)
+		return appendUint64(buf, uint64(d.GetInt64())), nil
+	case types.KindUint64:
+		buf = append(buf, snapshotKindUint64)
+		return appendUint64(buf, d.GetUint64()), nil
+	case types.KindFloat32, types.KindFloat64:
+		buf = append(buf, snapshotKindFloat64)
+		return appendUint64(buf, math.Float64bits(d.GetFloat64())), nil
+	case types.KindBytes:
+		buf = append(buf, snapshotKindBytes)
+		return appendSnapshotBytes(buf, d.GetBytes()), nil
+	case types.KindString:
+		buf = append(buf, snapshotKindString)
+		return appendSnapshotBytes(buf, d.GetBytes()), nil
+	default:
+		s, err := d.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		buf = append(buf, snapshotKindOther)
+		return appendSnapshotBytes(buf, []byte(s)), nil
+	}
+}
+
+func appendSnapshotBytes(buf []byte, b []byte) []byte {
+	buf = appendSnapshotVarints(buf, int64(len(b)))
+	return append(buf, b...)
+}
+
+func readSnapshotBytes(data []byte) ([]byte, []byte, error) {
+	vals, rest, err := readSnapshotVarints(data, 1)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	n := int(vals[0])
+	if len(rest) < n {
+		return nil, nil, errors.New("snapshot: truncated bytes field")
+	}
+	return rest[:n], rest[n:], nil
+}
+
+// readSnapshotDatum is appendSnapshotDatum's inverse.
+func readSnapshotDatum(data []byte) (*types.Datum, []byte, error) {
+	if len(data) < 1 {
+		return nil, nil, errors.New("snapshot: truncated datum tag")
+	}
+	kind := data[0]
+	data = data[1:]
+	var d types.Datum
+	switch kind {
+	case snapshotKindNull:
+		d.SetNull()
+	case snapshotKindInt64:
+		if len(data) < 8 {
+			return nil, nil, errors.New("snapshot: truncated int64 datum")
+		}
+		d.SetInt64(int64(binary.BigEndian.Uint64(data[:8])))
+		data = data[8:]
+	case snapshotKindUint64:
+		if len(data) < 8 {
+			return nil, nil, errors.New("snapshot: truncated uint64 datum")
+		}
+		d.SetUint64(binary.BigEndian.Uint64(data[:8]))
+		data = data[8:]
+	case snapshotKindFloat64:
+		if len(data) < 8 {
+			return nil, nil, errors.New("snapshot: truncated float64 datum")
+		}
+		d.SetFloat64(math.Float64frombits(binary.BigEndian.Uint64(data[:8])))
+		data = data[8:]
+	case snapshotKindBytes:
+		b, rest, err := readSnapshotBytes(data)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		d.SetBytes(b)
+		data = rest
+	case snapshotKindString, snapshotKindOther:
+		b, rest, err := readSnapshotBytes(data)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		d.SetString(string(b), "")
+		data = rest
+	default:
+		return nil, nil, errors.Errorf("snapshot: unknown datum kind %d", kind)
+	}
+	return &d, data, nil
+}
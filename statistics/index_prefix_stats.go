@@ -0,0 +1,101 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"bytes"
+
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+)
+
+// PrefixStat records the min/max encoded bound and NDV analyze observed for
+// one leading-column prefix length of a composite Index. Index.PrefixStats
+// holds one PrefixStat per prefix length 1..len(Info.Columns), indexed at
+// PrefixStats[prefixLen-1].
+type PrefixStat struct {
+	// MinBytes/MaxBytes are the smallest/largest encoded key bound observed
+	// for this prefix length during analyze.
+	MinBytes, MaxBytes []byte
+	// NDV is the number of distinct values of the first prefixLen columns.
+	NDV int64
+}
+
+// outOfRangePrefix reports whether val, an encoded bound covering exactly
+// the first prefixLen columns of idx, falls outside the range PrefixStats
+// observed for that prefix length. It returns false (i.e. "don't know, so
+// don't penalize") when idx has no PrefixStats for prefixLen, the caller's
+// cue to fall back to outOfRange's whole-key comparison instead.
+func (idx *Index) outOfRangePrefix(prefixLen int, val []byte) bool {
+	if prefixLen <= 0 || prefixLen > len(idx.PrefixStats) {
+		return false
+	}
+	ps := idx.PrefixStats[prefixLen-1]
+	if ps.MinBytes == nil && ps.MaxBytes == nil {
+		return false
+	}
+	return bytes.Compare(val, ps.MinBytes) < 0 || bytes.Compare(val, ps.MaxBytes) > 0
+}
+
+// outOfRangeBound reports whether val (one side of an encoded range bound)
+// is out-of-range for idx, preferring the per-prefix PrefixStats when
+// lowVal/highVal only bind a shared prefix of idx's columns shorter than
+// the full index, and falling back to outOfRange's whole-key comparison
+// otherwise (either because the range is already full-length, or because
+// idx predates PrefixStats).
+func (idx *Index) outOfRangeBound(lowVal, highVal []types.Datum, val types.Datum) bool {
+	prefixLen := len(lowVal)
+	if hl := len(highVal); hl < prefixLen {
+		prefixLen = hl
+	}
+	if idx.Info != nil && prefixLen > 0 && prefixLen < len(idx.Info.Columns) && prefixLen <= len(idx.PrefixStats) {
+		return idx.outOfRangePrefix(prefixLen, val.GetBytes())
+	}
+	return idx.outOfRange(val)
+}
+
+// equalRowCountPrefix estimates the row count of a probe that only binds
+// the first prefixLen columns of a composite index, e.g. `a = ?` against
+// INDEX(a,b). Plain equalRowCount treats b as a full-key point lookup,
+// which for a partial-prefix probe meant callers fell through to
+// BetweenRowCount's coarser full-range guess instead. Here we take the
+// CMSketch (or histogram) count of the prefix value and spread it across
+// the NDV_full/NDV_prefix distinct trailing-column combinations expected
+// per prefix value, interpolating the unconstrained trailing columns
+// instead of guessing.
+func (idx *Index) equalRowCountPrefix(sc *stmtctx.StatementContext, b []byte, prefixLen int, modifyCount int64) (float64, error) {
+	full := 0
+	if idx.Info != nil {
+		full = len(idx.Info.Columns)
+	}
+	if prefixLen <= 0 || prefixLen >= full || prefixLen > len(idx.PrefixStats) || full > len(idx.PrefixStats) {
+		return idx.equalRowCount(sc, b, modifyCount)
+	}
+	prefixNDV := idx.PrefixStats[prefixLen-1].NDV
+	fullNDV := idx.PrefixStats[full-1].NDV
+	if prefixNDV <= 0 || fullNDV <= 0 {
+		return idx.equalRowCount(sc, b, modifyCount)
+	}
+	var prefixCount float64
+	if idx.CMSketch != nil {
+		prefixCount = float64(idx.CMSketch.QueryBytes(b))
+	} else {
+		prefixCount = idx.Histogram.equalRowCount(types.NewBytesDatum(b))
+	}
+	ratio := float64(fullNDV) / float64(prefixNDV)
+	if ratio < 1 {
+		ratio = 1
+	}
+	return prefixCount / ratio, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/parser/model"
+	"github.com/pingcap/tidb/types"
+)
+
+var _ = Suite(&testIndexPrefixStatsSuite{})
+
+type testIndexPrefixStatsSuite struct{}
+
+// TestOutOfRangePrefixWithinBounds checks that a value within the
+// recorded [MinBytes, MaxBytes] range for a prefix length is reported
+// in-range.
+func (s *testIndexPrefixStatsSuite) TestOutOfRangePrefixWithinBounds(c *C) {
+	idx := &Index{PrefixStats: []PrefixStat{{MinBytes: []byte{1}, MaxBytes: []byte{10}}}}
+	c.Assert(idx.outOfRangePrefix(1, []byte{5}), IsFalse)
+}
+
+// TestOutOfRangePrefixBelowAndAboveBounds checks that values strictly
+// below MinBytes or strictly above MaxBytes are both reported
+// out-of-range.
+func (s *testIndexPrefixStatsSuite) TestOutOfRangePrefixBelowAndAboveBounds(c *C) {
+	idx := &Index{PrefixStats: []PrefixStat{{MinBytes: []byte{1}, MaxBytes: []byte{10}}}}
+	c.Assert(idx.outOfRangePrefix(1, []byte{0}), IsTrue)
+	c.Assert(idx.outOfRangePrefix(1, []byte{20}), IsTrue)
+}
+
+// TestOutOfRangePrefixNoStatsForLength checks that a prefixLen beyond
+// what PrefixStats recorded -- including the zero-value/missing-stats
+// case -- defers ("don't know, so don't penalize") rather than reporting
+// out-of-range.
+func (s *testIndexPrefixStatsSuite) TestOutOfRangePrefixNoStatsForLength(c *C) {
+	idx := &Index{PrefixStats: []PrefixStat{{MinBytes: []byte{1}, MaxBytes: []byte{10}}}}
+	c.Assert(idx.outOfRangePrefix(2, []byte{99}), IsFalse)
+	c.Assert(idx.outOfRangePrefix(0, []byte{99}), IsFalse)
+
+	empty := &Index{PrefixStats: []PrefixStat{{}}}
+	c.Assert(empty.outOfRangePrefix(1, []byte{99}), IsFalse)
+}
+
+// TestOutOfRangeBoundUsesPrefixStatsForPartialRange checks that a range
+// binding fewer columns than the index has (a one-column probe against a
+// two-column index) defers to outOfRangePrefix rather than outOfRange's
+// whole-key comparison, so a value outside the single-column prefix's
+// recorded bounds is flagged even though it was never compared against
+// the full two-column key range.
+func (s *testIndexPrefixStatsSuite) TestOutOfRangeBoundUsesPrefixStatsForPartialRange(c *C) {
+	idx := &Index{
+		Info:        &model.IndexInfo{Columns: []*model.IndexColumn{{}, {}}},
+		PrefixStats: []PrefixStat{{MinBytes: []byte{1}, MaxBytes: []byte{10}}, {MinBytes: []byte{1}, MaxBytes: []byte{10}}},
+	}
+	lowVal := []types.Datum{types.NewBytesDatum([]byte{1})}
+	highVal := []types.Datum{types.NewBytesDatum([]byte{10})}
+	val := types.NewBytesDatum([]byte{20})
+	c.Assert(idx.outOfRangeBound(lowVal, highVal, val), IsTrue)
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"container/heap"
+	"math"
+	"math/bits"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// hllPrecision is the number of leading bits of a value's hash used to pick
+// its HLLSketch register; 2^hllPrecision registers give a standard error of
+// about 1.04/sqrt(2^hllPrecision) ≈ 0.8%.
+const hllPrecision = 14
+
+// HLLSketch is a fixed-size HyperLogLog register array, used by
+// MergePartitionHistograms/MergePartitionIndexes to combine several
+// partitions' NDV estimates into one global NDV via a register-wise max
+// union. Unlike summing per-partition NDVs, the union doesn't double-count
+// a value that happens to appear in more than one partition's sample.
+type HLLSketch struct {
+	registers []uint8
+}
+
+// NewHLLSketch creates an empty HLLSketch, ready for InsertHash calls
+// during analyze.
+func NewHLLSketch() *HLLSketch {
+	return &HLLSketch{registers: make([]uint8, 1<<hllPrecision)}
+}
+
+// InsertHash folds one value's 64-bit hash into the sketch.
+func (s *HLLSketch) InsertHash(h uint64) {
+	idx := h >> (64 - hllPrecision)
+	rest := (h << hllPrecision) | (1 << (hllPrecision - 1))
+	rho := uint8(bits.LeadingZeros64(rest) + 1)
+	if rho > s.registers[idx] {
+		s.registers[idx] = rho
+	}
+}
+
+// MergeFrom folds other's registers into s via the standard HLL
+// register-wise max union.
+func (s *HLLSketch) MergeFrom(other *HLLSketch) {
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+}
+
+// Estimate returns the sketch's cardinality estimate, using the small- and
+// large-range corrections from the original HyperLogLog paper.
+func (s *HLLSketch) Estimate() int64 {
+	m := float64(len(s.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range s.registers {
+		sum += 1 / math.Pow(2, float64(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		return int64(m * math.Log(m/float64(zeros)))
+	}
+	return int64(estimate)
+}
+
+// partitionCursor walks one partition histogram's buckets in order,
+// tracking the cumulative count already consumed so bucketDelta can report
+// each bucket's incremental (not cumulative) count.
+type partitionCursor struct {
+	hg   *Histogram
+	idx  int
+	base int64
+}
+
+func (c *partitionCursor) exhausted() bool { return c.idx >= c.hg.Len() }
+
+// bucketDelta reads the current bucket's bounds and incremental count/repeat,
+// then advances past it.
+func (c *partitionCursor) bucketDelta() (lower, upper types.Datum, count, repeat int64) {
+	c.hg.LowerInto(c.idx, &lower)
+	c.hg.UpperInto(c.idx, &upper)
+	total := c.hg.Buckets[c.idx].Count
+	count = total - c.base
+	repeat = c.hg.Buckets[c.idx].Repeat
+	c.base = total
+	c.idx++
+	return
+}
+
+// cursorHeap is a min-heap of partitionCursors ordered by each cursor's
+// current bucket's lower bound, the heap MergePartitionHistograms uses to
+// stream buckets out across partitions in sorted order without holding more
+// than one in-flight bucket per partition.
+type cursorHeap []*partitionCursor
+
+func (h cursorHeap) Len() int { return len(h) }
+func (h cursorHeap) Less(i, j int) bool {
+	return chunk.Compare(h[i].hg.Bounds.GetRow(2*h[i].idx), 0, h[j].hg.GetLower(h[j].idx)) < 0
+}
+func (h cursorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeap) Push(x interface{}) {
+	*h = append(*h, x.(*partitionCursor))
+}
+func (h *cursorHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// MergePartitionHistograms stream-merges the per-partition histograms of one
+// column into a single global histogram with targetBuckets equi-height
+// output buckets. This replaces the old global-stats construction path,
+// which concatenated every partition's buckets into one Histogram and only
+// re-equalized afterwards: that holds all O(total input buckets) buckets in
+// memory at once, where this holds one in-flight cursor per partition plus
+// the output buckets being built, i.e. O(len(parts) + targetBuckets).
+//
+// Buckets are consumed off a k-way heap keyed on each partition's next
+// bucket's lower bound (see cursorHeap), so partitions don't need to be
+// concatenated and re-sorted first; each partition's own buckets are
+// already in sorted order, same as MergeHistograms assumes for its two-way
+// case.
+//
+// parts must share the same Tp (they're the same column's histogram across
+// partitions); an empty parts is an error.
+func MergePartitionHistograms(parts []*Histogram, targetBuckets int) (*Histogram, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("statistics: MergePartitionHistograms needs at least one partition histogram")
+	}
+	nonEmpty := make([]*Histogram, 0, len(parts))
+	var totalCount, totalNull int64
+	var lastVersion uint64
+	for _, p := range parts {
+		totalNull += p.NullCount
+		if p.LastUpdateVersion > lastVersion {
+			lastVersion = p.LastUpdateVersion
+		}
+		if p.Len() > 0 {
+			nonEmpty = append(nonEmpty, p)
+			totalCount += p.Buckets[p.Len()-1].Count
+		}
+	}
+	merged := NewHistogram(parts[0].ID, 0, totalNull, lastVersion, parts[0].Tp, targetBuckets, 0)
+	merged.NDV, merged.HLLSketch = mergeNDV(parts)
+	if len(nonEmpty) == 0 {
+		return merged, nil
+	}
+
+	h := make(cursorHeap, 0, len(nonEmpty))
+	for _, p := range nonEmpty {
+		h = append(h, &partitionCursor{hg: p})
+	}
+	heap.Init(&h)
+
+	targetPerBucket := float64(totalCount) / float64(targetBuckets)
+	if targetPerBucket < 1 {
+		targetPerBucket = 1
+	}
+
+	var (
+		bucketLower, bucketUpper types.Datum
+		runningCount, repeat     int64
+		haveOpenBucket           bool
+	)
+	flush := func() {
+		if !haveOpenBucket {
+			return
+		}
+		merged.AppendBucket(&bucketLower, &bucketUpper, runningCount, repeat)
+		haveOpenBucket = false
+	}
+	for h.Len() > 0 {
+		cur := h[0]
+		lower, upper, count, rep := cur.bucketDelta()
+		if cur.exhausted() {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+		if !haveOpenBucket {
+			bucketLower = lower
+			haveOpenBucket = true
+		}
+		bucketUpper = upper
+		repeat = rep
+		runningCount += count
+
+		if float64(runningCount) >= targetPerBucket*float64(merged.Len()+1) {
+			flush()
+		}
+	}
+	flush()
+	return merged, nil
+}
+
+// MergePartitionIndexes is MergePartitionHistograms' counterpart for Index
+// statistics: it merges parts' Histogram via MergePartitionHistograms, using
+// bytes.Compare semantics throughout since index bucket bounds are already
+// encoded keys (Histogram.Tp is mysql.TypeBlob for an Index, so the Datum
+// comparisons MergePartitionHistograms makes already reduce to a byte
+// comparison). CMSketch merging isn't attempted here: it needs to live
+// alongside whatever analyze path builds CMSketch in the first place, so
+// callers that need a merged CMSketch build one separately and assign it to
+// the result.
+func MergePartitionIndexes(parts []*Index, targetBuckets int) (*Index, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("statistics: MergePartitionIndexes needs at least one partition index")
+	}
+	hists := make([]*Histogram, 0, len(parts))
+	for _, p := range parts {
+		hists = append(hists, &p.Histogram)
+	}
+	mergedHist, err := MergePartitionHistograms(hists, targetBuckets)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	first := parts[0]
+	merged := &Index{
+		Histogram: *mergedHist,
+		CMSketch:  first.CMSketch,
+		StatsVer:  first.StatsVer,
+		Info:      first.Info,
+		Flag:      first.Flag,
+	}
+	return merged, nil
+}
+
+// mergeNDV combines parts' NDV estimates, preferring a HyperLogLog union
+// when every partition carries an HLLSketch (exact, since registers dedupe
+// values seen in more than one partition) and falling back to a plain sum
+// otherwise — the same "don't know any better" choice equalRowCountPrefix
+// makes when PrefixStats is missing.
+func mergeNDV(parts []*Histogram) (int64, *HLLSketch) {
+	sketch := NewHLLSketch()
+	haveAllSketches := true
+	var sum int64
+	for _, p := range parts {
+		sum += p.NDV
+		if p.HLLSketch != nil {
+			sketch.MergeFrom(p.HLLSketch)
+		} else {
+			haveAllSketches = false
+		}
+	}
+	if haveAllSketches && len(parts) > 0 {
+		return sketch.Estimate(), sketch
+	}
+	return sum, nil
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/ranger"
+)
+
+// estimatorPool recycles *RangeEstimator so the planner's selectivity loops
+// (which call GetRowCount/GetColumnRowCount once per column per candidate
+// access path) stop allocating a fresh lb/rb byte slice and Datum per range.
+// An estimator is only ever handed out via getRangeEstimator/putRangeEstimator,
+// never retained by a caller past the call that borrowed it.
+var estimatorPool = sync.Pool{
+	New: func() interface{} { return &RangeEstimator{} },
+}
+
+func getRangeEstimator() *RangeEstimator {
+	return estimatorPool.Get().(*RangeEstimator)
+}
+
+func putRangeEstimator(e *RangeEstimator) {
+	e.idx = nil
+	e.col = nil
+	e.sc = nil
+	e.ranges = nil
+	e.lowBuf = e.lowBuf[:0]
+	e.highBuf = e.highBuf[:0]
+	e.lowDatum.SetNull()
+	e.highDatum.SetNull()
+	e.pos = 0
+	e.total = 0
+	e.err = nil
+	estimatorPool.Put(e)
+}
+
+// RangeEstimator walks a slice of ranger.Range and accumulates an estimated
+// row count, one range at a time, reusing the same lb/rb scratch buffers and
+// stack-resident Datums across the whole walk instead of allocating a fresh
+// []byte/types.Datum per range the way a plain per-range GetRowCount/
+// GetColumnRowCount loop used to. Obtain one from getRangeEstimator and
+// return it with putRangeEstimator; it must not be used after that.
+type RangeEstimator struct {
+	idx *Index
+	col *Column
+
+	sc          *stmtctx.StatementContext
+	ranges      []*ranger.Range
+	modifyCount int64
+	isSingleCol bool
+
+	// lowBuf/highBuf back the encoded index-range bounds across the whole
+	// walk; EncodeKey appends onto them, so their backing array only grows
+	// on the first few ranges and is reused after that.
+	lowBuf, highBuf []byte
+	// lowDatum/highDatum are overwritten in place instead of being
+	// reconstructed with types.NewBytesDatum on every range.
+	lowDatum, highDatum types.Datum
+
+	pos   int
+	total float64
+	err   error
+}
+
+// ResetIndex rebinds e to walk idx's estimate for ranges, discarding any
+// previous walk's state but keeping lowBuf/highBuf's capacity.
+func (e *RangeEstimator) ResetIndex(idx *Index, sc *stmtctx.StatementContext, ranges []*ranger.Range, modifyCount int64) {
+	e.idx = idx
+	e.col = nil
+	e.sc = sc
+	e.ranges = ranges
+	e.modifyCount = modifyCount
+	e.isSingleCol = len(idx.Info.Columns) == 1
+	e.pos = 0
+	e.total = 0
+	e.err = nil
+}
+
+// ResetColumn rebinds e to walk col's estimate for ranges.
+func (e *RangeEstimator) ResetColumn(col *Column, sc *stmtctx.StatementContext, ranges []*ranger.Range, modifyCount int64) {
+	e.idx = nil
+	e.col = col
+	e.sc = sc
+	e.ranges = ranges
+	e.modifyCount = modifyCount
+	e.pos = 0
+	e.total = 0
+	e.err = nil
+}
+
+// Next folds the row count of the range at e's current position into
+// RowCount and advances. It returns false once every range has been
+// consumed or an error occurred; check Err in the latter case.
+func (e *RangeEstimator) Next() bool {
+	if e.err != nil || e.pos >= len(e.ranges) {
+		return false
+	}
+	if e.idx != nil {
+		e.err = e.nextIndexRange(e.ranges[e.pos])
+	} else {
+		e.err = e.nextColumnRange(e.ranges[e.pos])
+	}
+	e.pos++
+	return e.err == nil
+}
+
+// Err returns the first error Next encountered, if any.
+func (e *RangeEstimator) Err() error {
+	return e.err
+}
+
+// RowCount returns the row count accumulated so far, clamped to the target
+// Index's or Column's total row count the same way GetRowCount/
+// GetColumnRowCount always have.
+func (e *RangeEstimator) RowCount() float64 {
+	var total float64
+	if e.idx != nil {
+		total = e.idx.TotalRowCount()
+	} else {
+		total = e.col.TotalRowCount()
+	}
+	if e.total > total {
+		return total
+	}
+	if e.total < 0 {
+		return 0
+	}
+	return e.total
+}
+
+func (e *RangeEstimator) nextIndexRange(indexRange *ranger.Range) error {
+	idx := e.idx
+	var err error
+	e.lowBuf, err = codec.EncodeKey(e.sc, e.lowBuf[:0], indexRange.LowVal...)
+	if err != nil {
+		return err
+	}
+	e.highBuf, err = codec.EncodeKey(e.sc, e.highBuf[:0], indexRange.HighVal...)
+	if err != nil {
+		return err
+	}
+	fullLen := len(indexRange.LowVal) == len(indexRange.HighVal) && len(indexRange.LowVal) == len(idx.Info.Columns)
+	if bytes.Equal(e.lowBuf, e.highBuf) {
+		if indexRange.LowExclude || indexRange.HighExclude {
+			return nil
+		}
+		if fullLen {
+			count, err := idx.equalRowCount(e.sc, e.lowBuf, e.modifyCount)
+			if err != nil {
+				return err
+			}
+			e.total += count
+			return nil
+		}
+		// A partial-prefix point probe, e.g. `a = ?` on INDEX(a,b): use
+		// PrefixStats to interpolate the unconstrained trailing columns
+		// instead of falling through to BetweenRowCount's coarser guess.
+		count, err := idx.equalRowCountPrefix(e.sc, e.lowBuf, len(indexRange.LowVal), e.modifyCount)
+		if err != nil {
+			return err
+		}
+		e.total += count
+		return nil
+	}
+	lb, rb := e.lowBuf, e.highBuf
+	if indexRange.LowExclude {
+		lb = kv.Key(lb).PrefixNext()
+	}
+	if !indexRange.HighExclude {
+		rb = kv.Key(rb).PrefixNext()
+	}
+	e.lowDatum.SetBytes(lb)
+	e.highDatum.SetBytes(rb)
+	e.total += idx.BetweenRowCount(e.lowDatum, e.highDatum)
+	lowIsNull := bytes.Equal(lb, nullKeyBytes)
+	lowOutOfRange := idx.outOfRangeBound(indexRange.LowVal, indexRange.HighVal, e.lowDatum)
+	highOutOfRange := idx.outOfRangeBound(indexRange.LowVal, indexRange.HighVal, e.highDatum)
+	if (lowOutOfRange && !(e.isSingleCol && lowIsNull)) || highOutOfRange {
+		e.total += float64(e.modifyCount) / outOfRangeBetweenRate
+	}
+	if e.isSingleCol && lowIsNull {
+		e.total += float64(idx.NullCount)
+	}
+	return nil
+}
+
+func (e *RangeEstimator) nextColumnRange(rg *ranger.Range) error {
+	c := e.col
+	cmp, err := rg.LowVal[0].CompareDatum(e.sc, &rg.HighVal[0])
+	if err != nil {
+		return err
+	}
+	if cmp == 0 {
+		if !rg.LowExclude && !rg.HighExclude {
+			cnt, err := c.equalRowCount(e.sc, rg.LowVal[0], e.modifyCount)
+			if err != nil {
+				return err
+			}
+			e.total += cnt
+		}
+		return nil
+	}
+	cnt := c.BetweenRowCount(rg.LowVal[0], rg.HighVal[0])
+	if c.SparseHist == nil && ((c.outOfRange(rg.LowVal[0]) && !rg.LowVal[0].IsNull()) || c.outOfRange(rg.HighVal[0])) {
+		cnt += float64(e.modifyCount) / outOfRangeBetweenRate
+	}
+	if rg.LowExclude && !rg.LowVal[0].IsNull() {
+		lowCnt, err := c.equalRowCount(e.sc, rg.LowVal[0], e.modifyCount)
+		if err != nil {
+			return err
+		}
+		cnt -= lowCnt
+	}
+	if !rg.LowExclude && rg.LowVal[0].IsNull() {
+		cnt += float64(c.NullCount)
+	}
+	if !rg.HighExclude {
+		highCnt, err := c.equalRowCount(e.sc, rg.HighVal[0], e.modifyCount)
+		if err != nil {
+			return err
+		}
+		cnt += highCnt
+	}
+	e.total += cnt
+	return nil
+}
@@ -0,0 +1,357 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/pingcap/tidb/types"
+)
+
+// SparseHist is a sparse exponential-bucket histogram, following the
+// Prometheus native-histogram model: bucket i covers magnitude range
+// (base^(i-1), base^i] for base = 2^(2^-Schema), so larger Schema means
+// narrower buckets and lower per-bucket relative error (schema=3 gives
+// roughly 9% relative error per bucket). Unlike the equi-depth Histogram's
+// fixed bucket count, only populated bucket indices cost storage: they're
+// recorded as parallel PositiveOffsets/PositiveBuckets (and the Negative
+// equivalents) sorted by offset, so a DECIMAL/BIGINT column with a long
+// tail (monetary amounts, latencies) keeps tail resolution a fixed
+// 256-bucket equi-depth histogram would give up, while still supporting
+// the range queries a CMSketch can't answer.
+type SparseHist struct {
+	ID int64
+
+	Schema int8
+
+	ZeroCount, ZeroThreshold uint64
+
+	// PositiveOffsets/NegativeOffsets hold the bucket index of every
+	// populated bucket, sorted ascending; PositiveBuckets/NegativeBuckets
+	// hold that bucket's count at the same slice position.
+	PositiveOffsets []int32
+	PositiveBuckets []uint64
+	NegativeOffsets []int32
+	NegativeBuckets []uint64
+
+	NullCount int64
+}
+
+// NewSparseHist creates an empty SparseHist for column id.
+func NewSparseHist(id int64, schema int8, zeroThreshold uint64) *SparseHist {
+	return &SparseHist{ID: id, Schema: schema, ZeroThreshold: zeroThreshold}
+}
+
+// base is the ratio between the magnitude upper bounds of two adjacent
+// buckets: 2^(2^-Schema).
+func (s *SparseHist) base() float64 {
+	return math.Exp2(math.Exp2(-float64(s.Schema)))
+}
+
+// bucketIndex returns the smallest i with base^i >= absValue using
+// math/bits.Len64 to get a cheap integer starting point from absValue's bit
+// length before refining with the exact log, which keeps the common case
+// (values that are already powers of two, very common for byte-size-like
+// columns) from needing a full floating-point log call.
+func (s *SparseHist) bucketIndex(absValue float64) int32 {
+	if absValue <= 0 {
+		return 0
+	}
+	intPart := uint64(absValue)
+	if intPart > 0 && absValue == math.Trunc(absValue) && (intPart&(intPart-1)) == 0 {
+		// Exact power of two: bits.Len64 gives the exponent directly.
+		exp := bits.Len64(intPart) - 1
+		return int32(math.Ceil(float64(exp) / math.Exp2(-float64(s.Schema))))
+	}
+	return int32(math.Ceil(math.Log(absValue) / math.Log(s.base())))
+}
+
+// bucketUpper returns base^idx, the upper bound of the magnitude range
+// bucket idx covers.
+func (s *SparseHist) bucketUpper(idx int32) float64 {
+	return math.Pow(s.base(), float64(idx))
+}
+
+// Add records count observations of value.
+func (s *SparseHist) Add(value float64, count uint64) {
+	absValue := math.Abs(value)
+	if absValue <= float64(s.ZeroThreshold) {
+		s.ZeroCount += count
+		return
+	}
+	idx := s.bucketIndex(absValue)
+	if value > 0 {
+		s.PositiveOffsets, s.PositiveBuckets = addSparseBucket(s.PositiveOffsets, s.PositiveBuckets, idx, count)
+	} else {
+		s.NegativeOffsets, s.NegativeBuckets = addSparseBucket(s.NegativeOffsets, s.NegativeBuckets, idx, count)
+	}
+}
+
+func addSparseBucket(offsets []int32, buckets []uint64, idx int32, count uint64) ([]int32, []uint64) {
+	pos := sort.Search(len(offsets), func(i int) bool { return offsets[i] >= idx })
+	if pos < len(offsets) && offsets[pos] == idx {
+		buckets[pos] += count
+		return offsets, buckets
+	}
+	offsets = append(offsets, 0)
+	copy(offsets[pos+1:], offsets[pos:])
+	offsets[pos] = idx
+	buckets = append(buckets, 0)
+	copy(buckets[pos+1:], buckets[pos:])
+	buckets[pos] = count
+	return offsets, buckets
+}
+
+// TotalRowCount returns the total observation count across every bucket.
+func (s *SparseHist) TotalRowCount() float64 {
+	total := s.ZeroCount
+	for _, c := range s.PositiveBuckets {
+		total += c
+	}
+	for _, c := range s.NegativeBuckets {
+		total += c
+	}
+	return float64(total)
+}
+
+// halveSchema halves resolution, merging bucket pairs (2i, 2i+1) into
+// bucket i and decrementing Schema, the same halving Merge uses to align
+// two histograms built at different schemas.
+func (s *SparseHist) halveSchema() {
+	s.Schema--
+	s.PositiveOffsets, s.PositiveBuckets = halveSparseBuckets(s.PositiveOffsets, s.PositiveBuckets)
+	s.NegativeOffsets, s.NegativeBuckets = halveSparseBuckets(s.NegativeOffsets, s.NegativeBuckets)
+}
+
+func halveSparseBuckets(offsets []int32, buckets []uint64) ([]int32, []uint64) {
+	merged := make(map[int32]uint64, len(offsets))
+	for i, off := range offsets {
+		newOff := off >> 1
+		merged[newOff] += buckets[i]
+	}
+	newOffsets := make([]int32, 0, len(merged))
+	for off := range merged {
+		newOffsets = append(newOffsets, off)
+	}
+	sort.Slice(newOffsets, func(i, j int) bool { return newOffsets[i] < newOffsets[j] })
+	newBuckets := make([]uint64, len(newOffsets))
+	for i, off := range newOffsets {
+		newBuckets[i] = merged[off]
+	}
+	return newOffsets, newBuckets
+}
+
+// Merge merges other into s in place, halving whichever side has the finer
+// schema until both match, automatically adapting resolution the way
+// combining sparse histograms from different analyze partitions requires.
+func (s *SparseHist) Merge(other *SparseHist) {
+	rhs := other.Copy()
+	for s.Schema > rhs.Schema {
+		s.halveSchema()
+	}
+	for rhs.Schema > s.Schema {
+		rhs.halveSchema()
+	}
+	s.PositiveOffsets, s.PositiveBuckets = mergeSparseBuckets(s.PositiveOffsets, s.PositiveBuckets, rhs.PositiveOffsets, rhs.PositiveBuckets)
+	s.NegativeOffsets, s.NegativeBuckets = mergeSparseBuckets(s.NegativeOffsets, s.NegativeBuckets, rhs.NegativeOffsets, rhs.NegativeBuckets)
+	s.ZeroCount += rhs.ZeroCount
+	s.NullCount += rhs.NullCount
+	if rhs.ZeroThreshold > s.ZeroThreshold {
+		s.ZeroThreshold = rhs.ZeroThreshold
+	}
+}
+
+func mergeSparseBuckets(aOff []int32, aCnt []uint64, bOff []int32, bCnt []uint64) ([]int32, []uint64) {
+	merged := make(map[int32]uint64, len(aOff)+len(bOff))
+	for i, off := range aOff {
+		merged[off] += aCnt[i]
+	}
+	for i, off := range bOff {
+		merged[off] += bCnt[i]
+	}
+	offsets := make([]int32, 0, len(merged))
+	for off := range merged {
+		offsets = append(offsets, off)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	buckets := make([]uint64, len(offsets))
+	for i, off := range offsets {
+		buckets[i] = merged[off]
+	}
+	return offsets, buckets
+}
+
+// Copy deep copies s.
+func (s *SparseHist) Copy() *SparseHist {
+	newS := *s
+	newS.PositiveOffsets = append([]int32(nil), s.PositiveOffsets...)
+	newS.PositiveBuckets = append([]uint64(nil), s.PositiveBuckets...)
+	newS.NegativeOffsets = append([]int32(nil), s.NegativeOffsets...)
+	newS.NegativeBuckets = append([]uint64(nil), s.NegativeBuckets...)
+	return &newS
+}
+
+// FilterBySelectivity scales every bucket's count by selectivity, producing
+// a new SparseHist that approximates what this one would look like
+// restricted to a selectivity fraction of its rows. It's the SparseHist
+// counterpart of newHistogramBySelectivity: NewHistCollBySelectivity calls
+// it for a column carrying a SparseHist instead of walking ranges bucket by
+// bucket the way the equi-depth path does, since sparse buckets aren't
+// naturally range-addressable by bound comparison.
+func (s *SparseHist) FilterBySelectivity(selectivity float64) *SparseHist {
+	newS := &SparseHist{ID: s.ID, Schema: s.Schema, ZeroThreshold: s.ZeroThreshold}
+	newS.ZeroCount = uint64(float64(s.ZeroCount) * selectivity)
+	newS.PositiveOffsets = append([]int32(nil), s.PositiveOffsets...)
+	newS.PositiveBuckets = scaleBuckets(s.PositiveBuckets, selectivity)
+	newS.NegativeOffsets = append([]int32(nil), s.NegativeOffsets...)
+	newS.NegativeBuckets = scaleBuckets(s.NegativeBuckets, selectivity)
+	return newS
+}
+
+func scaleBuckets(buckets []uint64, selectivity float64) []uint64 {
+	out := make([]uint64, len(buckets))
+	for i, c := range buckets {
+		out[i] = uint64(float64(c) * selectivity)
+	}
+	return out
+}
+
+// bucketAt returns the count stored at exactly idx in the sorted
+// offsets/buckets pair, or 0 if idx has no populated bucket.
+func bucketAt(offsets []int32, buckets []uint64, idx int32) uint64 {
+	pos := sort.Search(len(offsets), func(i int) bool { return offsets[i] >= idx })
+	if pos < len(offsets) && offsets[pos] == idx {
+		return buckets[pos]
+	}
+	return 0
+}
+
+// lessRowCount estimates how many observations are strictly less than
+// value, linearly interpolating within the bucket value falls in.
+func (s *SparseHist) lessRowCount(value float64) float64 {
+	if value <= -float64(s.ZeroThreshold) {
+		absValue := -value
+		idx := s.bucketIndex(absValue)
+		var less float64
+		for i := len(s.NegativeOffsets) - 1; i >= 0; i-- {
+			off := s.NegativeOffsets[i]
+			upper, lower := s.bucketUpper(off), s.bucketUpper(off-1)
+			if upper <= absValue {
+				break
+			}
+			if lower >= absValue {
+				less += float64(s.NegativeBuckets[i])
+				continue
+			}
+			frac := (upper - absValue) / (upper - lower)
+			less += frac * float64(s.NegativeBuckets[i])
+			break
+		}
+		_ = idx
+		return less
+	}
+	total := s.TotalRowCount()
+	if value > float64(s.ZeroThreshold) {
+		var greaterEq float64
+		for i, off := range s.PositiveOffsets {
+			lower, upper := s.bucketUpper(off-1), s.bucketUpper(off)
+			if upper <= value {
+				continue
+			}
+			if lower >= value {
+				greaterEq += float64(s.PositiveBuckets[i])
+				continue
+			}
+			frac := (upper - value) / (upper - lower)
+			greaterEq += frac * float64(s.PositiveBuckets[i])
+		}
+		return total - greaterEq
+	}
+	var negTotal float64
+	for _, c := range s.NegativeBuckets {
+		negTotal += float64(c)
+	}
+	if s.ZeroThreshold == 0 {
+		return negTotal
+	}
+	frac := (value + float64(s.ZeroThreshold)) / (2 * float64(s.ZeroThreshold))
+	return negTotal + frac*float64(s.ZeroCount)
+}
+
+// EqualRowCount estimates the row count where the column equals value,
+// translating value into a bucket index and approximating a uniform
+// density across that bucket's magnitude range (SparseHist doesn't track
+// per-value repeats).
+func (s *SparseHist) EqualRowCount(value types.Datum) float64 {
+	f, ok := datumToFloat64(value)
+	if !ok {
+		return 0
+	}
+	absValue := math.Abs(f)
+	if absValue <= float64(s.ZeroThreshold) {
+		if s.ZeroThreshold == 0 {
+			return float64(s.ZeroCount)
+		}
+		return float64(s.ZeroCount) / (2 * float64(s.ZeroThreshold))
+	}
+	idx := s.bucketIndex(absValue)
+	var count uint64
+	if f > 0 {
+		count = bucketAt(s.PositiveOffsets, s.PositiveBuckets, idx)
+	} else {
+		count = bucketAt(s.NegativeOffsets, s.NegativeBuckets, idx)
+	}
+	if count == 0 {
+		return 0
+	}
+	lower, upper := s.bucketUpper(idx-1), s.bucketUpper(idx)
+	if upper <= lower {
+		return float64(count)
+	}
+	return float64(count) / (upper - lower)
+}
+
+// BetweenRowCount estimates the row count where the column is in [a, b).
+func (s *SparseHist) BetweenRowCount(a, b types.Datum) float64 {
+	af, aok := datumToFloat64(a)
+	bf, bok := datumToFloat64(b)
+	if !aok || !bok {
+		return 0
+	}
+	cnt := s.lessRowCount(bf) - s.lessRowCount(af)
+	if cnt < 0 {
+		return 0
+	}
+	return cnt
+}
+
+// outOfRange reports whether val falls outside every populated bucket's
+// magnitude range, including the zero bucket.
+func (s *SparseHist) outOfRange(val types.Datum) bool {
+	f, ok := datumToFloat64(val)
+	if !ok {
+		return true
+	}
+	absValue := math.Abs(f)
+	if absValue <= float64(s.ZeroThreshold) {
+		return s.ZeroCount == 0
+	}
+	idx := s.bucketIndex(absValue)
+	if f > 0 {
+		return len(s.PositiveOffsets) == 0 || idx < s.PositiveOffsets[0] || idx > s.PositiveOffsets[len(s.PositiveOffsets)-1]
+	}
+	return len(s.NegativeOffsets) == 0 || idx < s.NegativeOffsets[0] || idx > s.NegativeOffsets[len(s.NegativeOffsets)-1]
+}
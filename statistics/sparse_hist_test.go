@@ -0,0 +1,103 @@
+// Copyright 2026 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testSparseHistSuite{})
+
+type testSparseHistSuite struct{}
+
+// TestSparseHistAddAndTotalRowCount checks that Add tallies into ZeroCount,
+// a positive bucket, or a negative bucket as appropriate, and that
+// TotalRowCount sums all three.
+func (s *testSparseHistSuite) TestSparseHistAddAndTotalRowCount(c *C) {
+	h := NewSparseHist(1, 3, 1)
+	h.Add(0, 5)    // within ZeroThreshold
+	h.Add(100, 2)  // positive bucket
+	h.Add(-100, 3) // negative bucket
+	c.Assert(h.TotalRowCount(), Equals, float64(10))
+}
+
+// TestSparseHistEqualRowCountOutOfRange checks that a value with no
+// populated bucket reports zero rather than a negative or NaN count.
+func (s *testSparseHistSuite) TestSparseHistEqualRowCountOutOfRange(c *C) {
+	h := NewSparseHist(1, 3, 1)
+	h.Add(100, 10)
+	c.Assert(h.EqualRowCount(types.NewIntDatum(100000)), Equals, float64(0))
+}
+
+// TestSparseHistBetweenRowCountCoversAllObservations checks that a range
+// spanning every bucket (negative, zero, and positive) returns the full
+// observation count.
+func (s *testSparseHistSuite) TestSparseHistBetweenRowCountCoversAllObservations(c *C) {
+	h := NewSparseHist(1, 3, 1)
+	h.Add(0, 5)
+	h.Add(100, 2)
+	h.Add(-100, 3)
+	got := h.BetweenRowCount(types.NewIntDatum(-1000), types.NewIntDatum(1000))
+	c.Assert(got, Equals, h.TotalRowCount())
+}
+
+// TestSparseHistMergeSumsCounts checks that Merge combines two histograms'
+// bucket counts, ZeroCount, and NullCount, even when built at different
+// schemas (forcing halveSchema to align them).
+func (s *testSparseHistSuite) TestSparseHistMergeSumsCounts(c *C) {
+	a := NewSparseHist(1, 4, 1)
+	a.Add(100, 2)
+	a.NullCount = 1
+
+	b := NewSparseHist(1, 2, 1)
+	b.Add(100, 3)
+	b.NullCount = 2
+
+	a.Merge(b)
+	c.Assert(a.TotalRowCount(), Equals, float64(5))
+	c.Assert(a.NullCount, Equals, int64(3))
+}
+
+// TestSparseHistFilterBySelectivityScalesBuckets checks that
+// FilterBySelectivity scales every bucket's count without mutating the
+// receiver.
+func (s *testSparseHistSuite) TestSparseHistFilterBySelectivityScalesBuckets(c *C) {
+	h := NewSparseHist(1, 3, 1)
+	h.Add(100, 10)
+	h.Add(0, 4)
+
+	filtered := h.FilterBySelectivity(0.5)
+	c.Assert(filtered.TotalRowCount(), Equals, float64(7))
+	c.Assert(h.TotalRowCount(), Equals, float64(14))
+}
+
+// TestSparseHistCopyIsIndependent checks that mutating a Copy doesn't
+// affect the original.
+func (s *testSparseHistSuite) TestSparseHistCopyIsIndependent(c *C) {
+	h := NewSparseHist(1, 3, 1)
+	h.Add(100, 10)
+
+	cp := h.Copy()
+	cp.Add(100, 5)
+	c.Assert(h.TotalRowCount(), Equals, float64(10))
+	c.Assert(cp.TotalRowCount(), Equals, float64(15))
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// rangeTaskDefaultMaxBackoff bounds how long a single region's retry
+	// loop inside RunOnRange backs off for, the same budget a single RPC's
+	// region-miss retries get elsewhere in this package (see scan.go).
+	rangeTaskDefaultMaxBackoff = 20000
+	// rangeTaskMaxRetries additionally bounds the number of retries per
+	// region on top of whatever budget the Backoffer itself enforces, so a
+	// region stuck perpetually re-electing a leader fails the task instead
+	// of retrying forever.
+	rangeTaskMaxRetries = 32
+)
+
+// RangeTaskHandler processes one sub-range wholly contained in a single
+// region (RunOnRange never asks a handler to cross a region boundary) and
+// reports how many keys it processed. A handler that hits a transient
+// region-level failure (the region split/merged, lost its leader, or is
+// overloaded) should return NewRetryableRangeError(err) instead of a plain
+// error, so RunOnRange backs off, re-resolves the sub-range's current
+// bounds, and retries it instead of failing the whole task.
+type RangeTaskHandler func(ctx context.Context, r kv.KeyRange) (int, error)
+
+// RetryableRangeError marks an error a RangeTaskHandler returned as a
+// transient, region-level failure that RunOnRange should retry rather than
+// treat as fatal.
+type RetryableRangeError struct {
+	err error
+}
+
+// NewRetryableRangeError wraps err so RunOnRange retries the sub-range it
+// was returned for (with backoff and region re-resolution) instead of
+// aborting the whole task.
+func NewRetryableRangeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableRangeError{err: err}
+}
+
+func (e *RetryableRangeError) Error() string { return e.err.Error() }
+
+// Cause unwraps to the underlying failure, so errors.Cause(err) from an
+// outer caller still reaches it.
+func (e *RetryableRangeError) Cause() error { return e.err }
+
+func isRetryableRangeError(err error) bool {
+	_, ok := err.(*RetryableRangeError)
+	return ok
+}
+
+// RangeTaskProgress is a point-in-time snapshot of a RangeTaskRunner's
+// progress, returned by Progress().
+type RangeTaskProgress struct {
+	CompletedRegions int
+	InFlightRegions  int
+	FailedRegions    int
+	ProcessedKeys    int64
+	// ETA estimates the time remaining, from a rolling completed-regions/sec
+	// throughput average times the regions not yet enumerated-and-completed.
+	// It's 0 until at least one region has completed and the full range has
+	// finished being decomposed into regions.
+	ETA time.Duration
+}
+
+// RangeTaskRunner splits [startKey, endKey) at region boundaries and runs
+// handler over each region's sub-range across `concurrency` worker
+// goroutines. A sub-range that fails with a RetryableRangeError is backed
+// off and retried against its region's current bounds, so splits/merges
+// happening mid-run don't wedge or mis-cover the task; ctx.Done() aborts
+// outstanding and not-yet-started work so a caller can cancel a long GC or
+// scan job cleanly.
+type RangeTaskRunner struct {
+	name        string
+	store       *tikvStore
+	concurrency int
+	handler     RangeTaskHandler
+
+	completedRegions int32
+	inFlightRegions  int32
+	failedRegions    int32
+	totalRegions     int32
+	enumDone         int32
+	processedKeys    int64
+
+	mu        sync.Mutex
+	startTime time.Time
+	firstErr  error
+
+	// taskEndKey is read-only for the duration of one RunOnRange call; it's
+	// set before any worker goroutine starts and only read by them, so no
+	// lock is needed around it.
+	taskEndKey []byte
+}
+
+// NewRangeTaskRunner creates a RangeTaskRunner. name identifies the task in
+// logs only.
+func NewRangeTaskRunner(name string, store *tikvStore, concurrency int, handler RangeTaskHandler) *RangeTaskRunner {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &RangeTaskRunner{
+		name:        name,
+		store:       store,
+		concurrency: concurrency,
+		handler:     handler,
+	}
+}
+
+// Progress returns a snapshot of the current (or, between calls, the most
+// recently finished) RunOnRange call's progress.
+func (s *RangeTaskRunner) Progress() RangeTaskProgress {
+	completed := atomic.LoadInt32(&s.completedRegions)
+	p := RangeTaskProgress{
+		CompletedRegions: int(completed),
+		InFlightRegions:  int(atomic.LoadInt32(&s.inFlightRegions)),
+		FailedRegions:    int(atomic.LoadInt32(&s.failedRegions)),
+		ProcessedKeys:    atomic.LoadInt64(&s.processedKeys),
+	}
+	if completed > 0 && atomic.LoadInt32(&s.enumDone) == 1 {
+		remaining := atomic.LoadInt32(&s.totalRegions) - completed
+		if remaining > 0 {
+			s.mu.Lock()
+			start := s.startTime
+			s.mu.Unlock()
+			if !start.IsZero() {
+				perRegion := time.Since(start) / time.Duration(completed)
+				p.ETA = perRegion * time.Duration(remaining)
+			}
+		}
+	}
+	return p
+}
+
+// RunOnRange decomposes [startKey, endKey) into per-region sub-ranges and
+// runs s.handler over each one, fanning the work out across s.concurrency
+// goroutines. It returns the first fatal error encountered (after
+// RangeTaskHandler retries for any RetryableRangeError are exhausted), or
+// ctx's error if the caller cancelled it first.
+func (s *RangeTaskRunner) RunOnRange(ctx context.Context, startKey, endKey []byte) error {
+	atomic.StoreInt32(&s.completedRegions, 0)
+	atomic.StoreInt32(&s.inFlightRegions, 0)
+	atomic.StoreInt32(&s.failedRegions, 0)
+	atomic.StoreInt32(&s.totalRegions, 0)
+	atomic.StoreInt32(&s.enumDone, 0)
+	atomic.StoreInt64(&s.processedKeys, 0)
+	s.mu.Lock()
+	s.startTime = time.Now()
+	s.firstErr = nil
+	s.mu.Unlock()
+	s.taskEndKey = endKey
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ranges := make(chan kv.KeyRange, s.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range ranges {
+				if workCtx.Err() != nil {
+					continue
+				}
+				if err := s.runOne(workCtx, r); err != nil {
+					s.recordErr(err)
+					cancel()
+				}
+			}
+		}()
+	}
+
+	enumErr := s.enumerate(workCtx, startKey, endKey, ranges)
+	close(ranges)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	firstErr := s.firstErr
+	s.mu.Unlock()
+	if firstErr != nil {
+		return errors.Trace(firstErr)
+	}
+	if enumErr != nil {
+		return errors.Trace(enumErr)
+	}
+	return nil
+}
+
+// enumerate walks [startKey, endKey) region by region (via
+// regionCache.LocateKey, the same lookup scan.go's getDataSerial uses),
+// pushing one kv.KeyRange per region onto out. It stops early, without
+// error, once ctx is cancelled.
+func (s *RangeTaskRunner) enumerate(ctx context.Context, startKey, endKey []byte, out chan<- kv.KeyRange) error {
+	bo := NewBackoffer(ctx, rangeTaskDefaultMaxBackoff)
+	cursor := startKey
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if len(endKey) > 0 && bytes.Compare(cursor, endKey) >= 0 {
+			atomic.StoreInt32(&s.enumDone, 1)
+			return nil
+		}
+		loc, err := s.store.regionCache.LocateKey(bo, cursor)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		rangeEnd := loc.EndKey
+		if len(endKey) > 0 && (len(rangeEnd) == 0 || bytes.Compare(endKey, rangeEnd) < 0) {
+			rangeEnd = endKey
+		}
+		atomic.AddInt32(&s.totalRegions, 1)
+		select {
+		case out <- kv.KeyRange{StartKey: cursor, EndKey: rangeEnd}:
+		case <-ctx.Done():
+			return nil
+		}
+		if len(loc.EndKey) == 0 {
+			atomic.StoreInt32(&s.enumDone, 1)
+			return nil
+		}
+		cursor = loc.EndKey
+	}
+}
+
+// runOne calls s.handler on r, retrying with backoff and region
+// re-resolution while the handler reports the failure is a
+// RetryableRangeError.
+func (s *RangeTaskRunner) runOne(ctx context.Context, r kv.KeyRange) error {
+	atomic.AddInt32(&s.inFlightRegions, 1)
+	defer atomic.AddInt32(&s.inFlightRegions, -1)
+
+	bo := NewBackoffer(ctx, rangeTaskDefaultMaxBackoff)
+	cur := r
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		keys, err := s.handler(ctx, cur)
+		if err == nil {
+			atomic.AddInt32(&s.completedRegions, 1)
+			atomic.AddInt64(&s.processedKeys, int64(keys))
+			return nil
+		}
+		if !isRetryableRangeError(err) || attempt >= rangeTaskMaxRetries {
+			atomic.AddInt32(&s.failedRegions, 1)
+			return errors.Trace(err)
+		}
+		cause := err.(*RetryableRangeError).Cause()
+		logutil.Logger(ctx).Info("range task retrying region after transient error",
+			zap.String("name", s.name), zap.Int("attempt", attempt+1), zap.Error(cause))
+		if boErr := bo.Backoff(BoRegionMiss, cause); boErr != nil {
+			atomic.AddInt32(&s.failedRegions, 1)
+			return errors.Trace(boErr)
+		}
+		resolved, relocErr := s.resolveRange(bo, cur.StartKey)
+		if relocErr != nil {
+			atomic.AddInt32(&s.failedRegions, 1)
+			return errors.Trace(relocErr)
+		}
+		cur = resolved
+	}
+}
+
+// resolveRange re-locates the region now covering startKey and clips its
+// end to s.taskEndKey, the way enumerate originally clipped cur's bounds;
+// called before a retry so a split/merge that happened since the region
+// was first resolved doesn't leave runOne retrying against a stale range.
+func (s *RangeTaskRunner) resolveRange(bo *Backoffer, startKey []byte) (kv.KeyRange, error) {
+	loc, err := s.store.regionCache.LocateKey(bo, startKey)
+	if err != nil {
+		return kv.KeyRange{}, errors.Trace(err)
+	}
+	end := loc.EndKey
+	if len(s.taskEndKey) > 0 && (len(end) == 0 || bytes.Compare(s.taskEndKey, end) < 0) {
+		end = s.taskEndKey
+	}
+	return kv.KeyRange{StartKey: startKey, EndKey: end}, nil
+}
+
+func (s *RangeTaskRunner) recordErr(err error) {
+	s.mu.Lock()
+	if s.firstErr == nil {
+		s.firstErr = err
+	}
+	s.mu.Unlock()
+}
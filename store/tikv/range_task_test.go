@@ -17,8 +17,11 @@ import (
 	"bytes"
 	"context"
 	"sort"
+	"sync"
+	"sync/atomic"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/mockstore/mocktikv"
 )
@@ -166,3 +169,103 @@ func (s *testRangeTaskSuite) TestRangeTask(c *C) {
 		s.testRangeTaskImpl(c, concurrency)
 	}
 }
+
+// TestRangeTaskForceRegionError forces the first two attempts at one
+// sub-range to fail with a RetryableRangeError (as a handler would for a
+// region-not-found/stale-epoch/server-busy RPC response) and asserts
+// RunOnRange retries instead of aborting, and that Progress reflects the
+// retries as completed, not failed, once they succeed.
+func (s *testRangeTaskSuite) TestRangeTaskForceRegionError(c *C) {
+	const flakyStart = "c"
+	var attempts int32
+
+	handler := func(ctx context.Context, r kv.KeyRange) (int, error) {
+		if bytes.Equal(r.StartKey, []byte(flakyStart)) && atomic.AddInt32(&attempts, 1) <= 2 {
+			return 0, NewRetryableRangeError(errors.New("region not found"))
+		}
+		return 1, nil
+	}
+
+	runner := NewRangeTaskRunner("test-force-region-error", s.store, 1, handler)
+	err := runner.RunOnRange(context.Background(), []byte(""), []byte(""))
+	c.Assert(err, IsNil)
+	c.Assert(atomic.LoadInt32(&attempts), Equals, int32(3))
+	progress := runner.Progress()
+	c.Assert(progress.FailedRegions, Equals, 0)
+	c.Assert(progress.CompletedRegions, Equals, len(s.expectedRanges[0]))
+}
+
+// TestRangeTaskNonRetryableError asserts a plain (non-Retryable) handler
+// error aborts the whole task and is reported as a failed region rather
+// than retried forever.
+func (s *testRangeTaskSuite) TestRangeTaskNonRetryableError(c *C) {
+	wantErr := errors.New("permanent failure")
+	handler := func(ctx context.Context, r kv.KeyRange) (int, error) {
+		if bytes.Equal(r.StartKey, []byte("c")) {
+			return 0, wantErr
+		}
+		return 1, nil
+	}
+
+	runner := NewRangeTaskRunner("test-non-retryable", s.store, 1, handler)
+	err := runner.RunOnRange(context.Background(), []byte(""), []byte(""))
+	c.Assert(err, NotNil)
+	progress := runner.Progress()
+	c.Assert(progress.FailedRegions, Equals, 1)
+}
+
+// TestRangeTaskCancellation asserts cancelling ctx mid-run stops the task
+// without running the handler over every region.
+func (s *testRangeTaskSuite) TestRangeTaskCancellation(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	handler := func(ctx context.Context, r kv.KeyRange) (int, error) {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			cancel()
+		}
+		return 1, nil
+	}
+
+	runner := NewRangeTaskRunner("test-cancel", s.store, 1, handler)
+	err := runner.RunOnRange(ctx, []byte(""), []byte(""))
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(int(atomic.LoadInt32(&calls)) < len(s.expectedRanges[0]), IsTrue)
+}
+
+// TestRangeTaskSplitDuringScan splits a region while RunOnRange is mid-run
+// and asserts every key in the overall range is still covered exactly once
+// across the handler calls, i.e. the split doesn't produce a gap or an
+// overlap.
+func (s *testRangeTaskSuite) TestRangeTaskSplitDuringScan(c *C) {
+	var mu sync.Mutex
+	var covered []kv.KeyRange
+	var calls int32
+
+	handler := func(ctx context.Context, r kv.KeyRange) (int, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			// Split region "j".."k" in half, after it's already been
+			// enumerated as part of an earlier or concurrent sub-range.
+			newPeers := s.cluster.AllocIDs(2)
+			newRegionID := s.cluster.AllocID()
+			region, _ := s.cluster.GetRegionByKey([]byte("j"))
+			s.cluster.Split(region.Id, newRegionID, []byte("jm"), newPeers, newPeers[0])
+		}
+		mu.Lock()
+		covered = append(covered, r)
+		mu.Unlock()
+		return 1, nil
+	}
+
+	runner := NewRangeTaskRunner("test-split-during-scan", s.store, 2, handler)
+	err := runner.RunOnRange(context.Background(), []byte(""), []byte(""))
+	c.Assert(err, IsNil)
+
+	sort.Slice(covered, func(i, j int) bool {
+		return bytes.Compare(covered[i].StartKey, covered[j].StartKey) < 0
+	})
+	for i := 1; i < len(covered); i++ {
+		c.Assert(bytes.Compare(covered[i-1].EndKey, covered[i].StartKey), Equals, 0)
+	}
+	c.Assert(covered[0].StartKey, DeepEquals, []byte(""))
+	c.Assert(covered[len(covered)-1].EndKey, DeepEquals, []byte(""))
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"fmt"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+// ErrPDRegionNotFound reports that PD returned no region covering a
+// requested key (LocateKey/LocateEndKey), or a gap within a requested key
+// range (ListRegionIDsInKeyRange), along with enough PD request context
+// for an operator debugging a production incident to identify the
+// missing range without extra tracing.
+type ErrPDRegionNotFound struct {
+	// StartKey/EndKey bound the uncovered range. A single-key lookup
+	// (LocateKey/LocateEndKey) sets both to the requested key.
+	StartKey []byte
+	EndKey   []byte
+	// StaleRegion is the last-known cached metadata covering this range
+	// that was invalidated before the PD lookup was attempted, or nil if
+	// nothing was cached.
+	StaleRegion *metapb.Region
+	// PDRequestID/PDRequestTS identify the PD request that came back
+	// empty, so the failure can be correlated with PD-side logs.
+	PDRequestID uint64
+	PDRequestTS int64
+}
+
+func (e *ErrPDRegionNotFound) Error() string {
+	msg := fmt.Sprintf("PD returned no region covering key range [%q, %q)", e.StartKey, e.EndKey)
+	if e.StaleRegion != nil {
+		msg += fmt.Sprintf(", last known region was %d (epoch %s)", e.StaleRegion.GetId(), e.StaleRegion.GetRegionEpoch())
+	}
+	if e.PDRequestID != 0 {
+		msg += fmt.Sprintf(", pd request id=%d ts=%d", e.PDRequestID, e.PDRequestTS)
+	}
+	return msg
+}
+
+// newErrPDRegionNotFoundForKey builds an ErrPDRegionNotFound for a
+// single-key lookup (LocateKey/LocateEndKey).
+func newErrPDRegionNotFoundForKey(key []byte, stale *metapb.Region, pdRequestID uint64, pdRequestTS int64) *ErrPDRegionNotFound {
+	return &ErrPDRegionNotFound{
+		StartKey:    key,
+		EndKey:      key,
+		StaleRegion: stale,
+		PDRequestID: pdRequestID,
+		PDRequestTS: pdRequestTS,
+	}
+}
+
+// newErrPDRegionNotFoundForRange builds an ErrPDRegionNotFound describing
+// the [startKey, endKey) sub-range ListRegionIDsInKeyRange found
+// uncovered by any cached or newly resolved region, rather than failing
+// opaquely for the whole scan.
+func newErrPDRegionNotFoundForRange(startKey, endKey []byte, pdRequestID uint64, pdRequestTS int64) *ErrPDRegionNotFound {
+	return &ErrPDRegionNotFound{
+		StartKey:    startKey,
+		EndKey:      endKey,
+		PDRequestID: pdRequestID,
+		PDRequestTS: pdRequestTS,
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+type testRegionCacheErrorsSuite struct{}
+
+var _ = Suite(&testRegionCacheErrorsSuite{})
+
+// TestErrPDRegionNotFoundForKeyFormatsHexEscapedKey checks that a
+// single-key lookup miss reports the same key as both endpoints,
+// hex-escaped, so it can be grepped from logs without extra tracing.
+func (s *testRegionCacheErrorsSuite) TestErrPDRegionNotFoundForKeyFormatsHexEscapedKey(c *C) {
+	key := []byte{0x00, 0x74, 0xff}
+	err := newErrPDRegionNotFoundForKey(key, nil, 0, 0)
+	c.Assert(err.Error(), Equals, fmt.Sprintf("PD returned no region covering key range [%q, %q)", key, key))
+}
+
+// TestErrPDRegionNotFoundForRangeReportsUncoveredHole checks that a
+// ListRegionIDsInKeyRange hole is reported with both endpoints of the
+// uncovered sub-range rather than the original scan's full range.
+func (s *testRegionCacheErrorsSuite) TestErrPDRegionNotFoundForRangeReportsUncoveredHole(c *C) {
+	start, end := []byte("m"), []byte("z")
+	err := newErrPDRegionNotFoundForRange(start, end, 7, 123456)
+	msg := err.Error()
+	c.Assert(msg, Equals, fmt.Sprintf("PD returned no region covering key range [%q, %q), pd request id=7 ts=123456", start, end))
+}
+
+// TestErrPDRegionNotFoundIncludesStaleRegion checks that when a cached
+// region was invalidated before the PD lookup, its ID is surfaced so an
+// operator can tell which region went missing.
+func (s *testRegionCacheErrorsSuite) TestErrPDRegionNotFoundIncludesStaleRegion(c *C) {
+	stale := &metapb.Region{Id: 42, RegionEpoch: &metapb.RegionEpoch{Version: 3, ConfVer: 1}}
+	err := newErrPDRegionNotFoundForKey([]byte("k"), stale, 0, 0)
+	c.Assert(err.Error(), Matches, ".*last known region was 42.*")
+}
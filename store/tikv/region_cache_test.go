@@ -151,6 +151,25 @@ func (s *testRegionCacheSuite) TestDropStoreRetry(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(loc.Region.id, Equals, s.region1)
 	<-done
+
+	// The store comes back with the same ID but a different address, as
+	// happens when a TiKV node is redeployed. A StoreResolver tracking
+	// this region should force it to reload from PD instead of keeping
+	// the stale address cached, without waiting for the TTL to expire.
+	ctx, err := s.cache.GetRPCContext(s.bo, loc.Region)
+	c.Assert(err, IsNil)
+	resolver := NewStoreResolver(s.cache, &codecPDClient{mocktikv.NewPDClient(s.cluster)})
+	resolver.Track(loc.Region, s.store1, ctx.Addr, metapb.StoreState_Up)
+
+	newAddr := s.storeAddr(s.store1) + "-new"
+	s.cluster.UpdateStoreAddr(s.store1, newAddr)
+	resolver.resolveOnce()
+
+	loc, err = s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	ctx, err = s.cache.GetRPCContext(s.bo, loc.Region)
+	c.Assert(err, IsNil)
+	c.Assert(ctx.Addr, Equals, newAddr)
 }
 
 func (s *testRegionCacheSuite) TestUpdateLeader(c *C) {
@@ -457,6 +476,32 @@ func (s *testRegionCacheSuite) TestUpdateStoreAddr(c *C) {
 	c.Assert(getVal, BytesEquals, testValue)
 }
 
+// TestUpdateStoreAddrWithRequestTimeout checks that overriding the RPC
+// timeout and tagging a request source through WithRequestTimeout doesn't
+// change the StoreNotMatch retry/leader-switch behavior TestUpdateStoreAddr
+// already covers -- GetRPCContextWithTimeout is additive, not a different
+// code path.
+func (s *testRegionCacheSuite) TestUpdateStoreAddrWithRequestTimeout(c *C) {
+	bo := WithRequestTimeout(NewBackoffer(context.Background(), 100), 5*time.Second, RequestSourceAnalyze)
+
+	loc, err := s.cache.LocateKey(bo, []byte("a"))
+	c.Assert(err, IsNil)
+
+	timedCtx, err := s.cache.GetRPCContextWithTimeout(bo, loc.Region, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(timedCtx, NotNil)
+	c.Assert(timedCtx.Timeout, Equals, 5*time.Second)
+	c.Assert(timedCtx.Source, Equals, RequestSourceAnalyze)
+	c.Assert(timedCtx.Addr, Equals, s.storeAddr(s.store1))
+
+	// send fail leader switch to 2, same as the no-timeout path.
+	s.cache.OnSendFail(bo, timedCtx.RPCContext, false, nil)
+	timedCtx, err = s.cache.GetRPCContextWithTimeout(bo, loc.Region, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(timedCtx.Peer.Id, Equals, s.peer2)
+	c.Assert(timedCtx.Timeout, Equals, 5*time.Second)
+}
+
 func (s *testRegionCacheSuite) TestListRegionIDsInCache(c *C) {
 	// ['' - 'm' - 'z']
 	region2 := s.cluster.AllocID()
@@ -534,16 +579,23 @@ func BenchmarkOnRequestFail(b *testing.B) {
 	store, peer, idx := region.WorkStorePeer(regionStore)
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			rpcCtx := &RPCContext{
-				Region:  loc.Region,
-				Meta:    region.meta,
-				PeerIdx: idx,
-				Peer:    peer,
-				Store:   store,
+			// Wrapping in TimedRPCContext (as a caller like BR or
+			// Lightning overriding the default timeout would) must not
+			// add any extra locking to this hot path.
+			timedCtx := &TimedRPCContext{
+				RPCContext: &RPCContext{
+					Region:  loc.Region,
+					Meta:    region.meta,
+					PeerIdx: idx,
+					Peer:    peer,
+					Store:   store,
+				},
+				Timeout: 30 * time.Second,
+				Source:  RequestSourceBR,
 			}
-			r := cache.getCachedRegionWithRLock(rpcCtx.Region)
+			r := cache.getCachedRegionWithRLock(timedCtx.Region)
 			if r == nil {
-				cache.switchNextPeer(r, rpcCtx.PeerIdx)
+				cache.switchNextPeer(r, timedCtx.PeerIdx)
 			}
 		}
 	})
@@ -0,0 +1,177 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegionProblemCategory classifies a cached region as being in one of the
+// problem states PD's own RegionStatistics tracks cluster-wide -- here
+// scoped to whatever this process's RegionCache has actually observed.
+type RegionProblemCategory string
+
+const (
+	// CategoryMissPeer is a region with fewer voter peers than its
+	// replication factor, learned from an RPC response listing fewer
+	// peers than expected.
+	CategoryMissPeer RegionProblemCategory = "miss-peer"
+	// CategoryDownPeer is a region with at least one peer on a store
+	// this process has marked unreachable.
+	CategoryDownPeer RegionProblemCategory = "down-peer"
+	// CategoryLearnerPeer is a region whose only peers this process has
+	// seen are learners, with no voter to route a write to.
+	CategoryLearnerPeer RegionProblemCategory = "learner-peer"
+	// CategoryStaleEpoch is a region invalidated by OnRegionEpochNotMatch
+	// and awaiting reload from PD.
+	CategoryStaleEpoch RegionProblemCategory = "stale-epoch"
+)
+
+var regionStatsCategories = []RegionProblemCategory{
+	CategoryMissPeer, CategoryDownPeer, CategoryLearnerPeer, CategoryStaleEpoch,
+}
+
+var problemRegionsGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "tidb",
+		Subsystem: "tikvclient",
+		Name:      "problem_regions",
+		Help:      "Number of cached regions this process currently considers miss-peer, down-peer, learner-peer, or stale-epoch.",
+	}, []string{"category"})
+
+func init() {
+	prometheus.MustRegister(problemRegionsGauge)
+}
+
+// RegionStatsSnapshot is a point-in-time read of every cached region
+// RegionStatsCollector currently considers problematic, grouped by
+// category.
+type RegionStatsSnapshot struct {
+	Regions map[RegionProblemCategory][]RegionVerID
+	Counts  map[RegionProblemCategory]int
+}
+
+// RegionStatsCollector maintains, per RegionCache, the sets of cached
+// regions currently in a problem state -- missing peers, peers on a
+// store marked unreachable, learner-only, or awaiting reload after a
+// stale epoch -- so operators and callers can answer "which regions are
+// unhealthy right now" without scraping PD directly.
+//
+// Like ReplicaSelector and StoreResolver, it does not reach into
+// RegionCache's internal maps; callers feed it via Mark*/Clear as they
+// learn a region's state from OnSendFail, OnRegionEpochNotMatch, and
+// StoreResolver.
+type RegionStatsCollector struct {
+	cache *RegionCache
+
+	mu struct {
+		sync.Mutex
+		regions map[RegionProblemCategory]map[RegionVerID]struct{}
+	}
+}
+
+// NewRegionStatsCollector builds a RegionStatsCollector over cache.
+func NewRegionStatsCollector(cache *RegionCache) *RegionStatsCollector {
+	s := &RegionStatsCollector{cache: cache}
+	s.mu.regions = make(map[RegionProblemCategory]map[RegionVerID]struct{}, len(regionStatsCategories))
+	for _, category := range regionStatsCategories {
+		s.mu.regions[category] = make(map[RegionVerID]struct{})
+	}
+	return s
+}
+
+// mark adds region to category, creating its series in problemRegionsGauge
+// if this is the first region observed in that category.
+func (s *RegionStatsCollector) mark(category RegionProblemCategory, region RegionVerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.regions[category][region] = struct{}{}
+	problemRegionsGauge.WithLabelValues(string(category)).Set(float64(len(s.mu.regions[category])))
+}
+
+// unmark removes region from category, if present.
+func (s *RegionStatsCollector) unmark(category RegionProblemCategory, region RegionVerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mu.regions[category], region)
+	problemRegionsGauge.WithLabelValues(string(category)).Set(float64(len(s.mu.regions[category])))
+}
+
+// MarkMissPeer records that region reported fewer voter peers than its
+// replication factor wants.
+func (s *RegionStatsCollector) MarkMissPeer(region RegionVerID) { s.mark(CategoryMissPeer, region) }
+
+// MarkDownPeer records that region has a peer on a store this process
+// has marked unreachable, e.g. from OnSendFail.
+func (s *RegionStatsCollector) MarkDownPeer(region RegionVerID) { s.mark(CategoryDownPeer, region) }
+
+// MarkLearnerOnly records that every peer this process has seen for
+// region is a learner.
+func (s *RegionStatsCollector) MarkLearnerOnly(region RegionVerID) {
+	s.mark(CategoryLearnerPeer, region)
+}
+
+// MarkStaleEpoch records that region was invalidated by
+// OnRegionEpochNotMatch and is awaiting reload from PD.
+func (s *RegionStatsCollector) MarkStaleEpoch(region RegionVerID) {
+	s.mark(CategoryStaleEpoch, region)
+}
+
+// ClearRegion removes region from every problem category, e.g. once it
+// has been reloaded from PD and is healthy again.
+func (s *RegionStatsCollector) ClearRegion(region RegionVerID) {
+	for _, category := range regionStatsCategories {
+		s.unmark(category, region)
+	}
+}
+
+// RegionStats returns a snapshot of every region currently tracked in
+// each problem category.
+func (s *RegionStatsCollector) RegionStats() RegionStatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := RegionStatsSnapshot{
+		Regions: make(map[RegionProblemCategory][]RegionVerID, len(regionStatsCategories)),
+		Counts:  make(map[RegionProblemCategory]int, len(regionStatsCategories)),
+	}
+	for _, category := range regionStatsCategories {
+		regions := s.mu.regions[category]
+		ids := make([]RegionVerID, 0, len(regions))
+		for region := range regions {
+			ids = append(ids, region)
+		}
+		snap.Regions[category] = ids
+		snap.Counts[category] = len(ids)
+	}
+	return snap
+}
+
+// IterProblemRegions calls fn for every region currently tracked under
+// category, stopping early if fn returns false.
+func (s *RegionStatsCollector) IterProblemRegions(category RegionProblemCategory, fn func(RegionVerID) bool) {
+	s.mu.Lock()
+	regions := make([]RegionVerID, 0, len(s.mu.regions[category]))
+	for region := range s.mu.regions[category] {
+		regions = append(regions, region)
+	}
+	s.mu.Unlock()
+
+	for _, region := range regions {
+		if !fn(region) {
+			return
+		}
+	}
+}
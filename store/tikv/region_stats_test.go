@@ -0,0 +1,102 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"context"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/store/mockstore/mocktikv"
+)
+
+type testRegionStatsSuite struct{}
+
+var _ = Suite(&testRegionStatsSuite{})
+
+// TestMarkAndClearRegion checks that a region marked down-peer and
+// miss-peer shows up in both category snapshots, and that ClearRegion
+// removes it from every category at once.
+func (s *testRegionStatsSuite) TestMarkAndClearRegion(c *C) {
+	stats := NewRegionStatsCollector(nil)
+	region := RegionVerID{id: 1}
+
+	stats.MarkDownPeer(region)
+	stats.MarkMissPeer(region)
+
+	snap := stats.RegionStats()
+	c.Assert(snap.Counts[CategoryDownPeer], Equals, 1)
+	c.Assert(snap.Counts[CategoryMissPeer], Equals, 1)
+	c.Assert(snap.Regions[CategoryDownPeer], DeepEquals, []RegionVerID{region})
+
+	stats.ClearRegion(region)
+	snap = stats.RegionStats()
+	c.Assert(snap.Counts[CategoryDownPeer], Equals, 0)
+	c.Assert(snap.Counts[CategoryMissPeer], Equals, 0)
+}
+
+// TestIterProblemRegionsStopsEarly checks that IterProblemRegions honors
+// a false return from fn instead of visiting every tracked region.
+func (s *testRegionStatsSuite) TestIterProblemRegionsStopsEarly(c *C) {
+	stats := NewRegionStatsCollector(nil)
+	stats.MarkLearnerOnly(RegionVerID{id: 1})
+	stats.MarkLearnerOnly(RegionVerID{id: 2})
+
+	visited := 0
+	stats.IterProblemRegions(CategoryLearnerPeer, func(RegionVerID) bool {
+		visited++
+		return false
+	})
+	c.Assert(visited, Equals, 1)
+}
+
+// TestStoreResolverFeedsDownPeerOnSplitAndDrop mirrors TestSplit/
+// TestDropStore: after a region splits and one of its stores is removed,
+// a StoreResolver wired to a RegionStatsCollector should show the
+// affected region as down-peer, and clear it again once the store comes
+// back and the region is reloaded.
+func (s *testRegionStatsSuite) TestStoreResolverFeedsDownPeerOnSplitAndDrop(c *C) {
+	cluster := mocktikv.NewCluster()
+	storeIDs, _, regionID, _ := mocktikv.BootstrapWithMultiStores(cluster, 2)
+	pdCli := &codecPDClient{mocktikv.NewPDClient(cluster)}
+	cache := NewRegionCache(pdCli)
+	defer cache.Close()
+	bo := NewBackoffer(context.Background(), 5000)
+
+	// Split off a second region, same as TestSplit.
+	region2 := cluster.AllocID()
+	newPeers := cluster.AllocIDs(2)
+	cluster.Split(regionID, region2, []byte("m"), newPeers, newPeers[0])
+
+	stats := NewRegionStatsCollector(cache)
+	resolver := NewStoreResolver(cache, pdCli)
+	resolver.SetStats(stats)
+
+	loc, err := cache.LocateKey(bo, []byte("x"))
+	c.Assert(err, IsNil)
+	ctx, err := cache.GetRPCContext(bo, loc.Region)
+	c.Assert(err, IsNil)
+
+	storeID := storeIDs[0]
+	resolver.Track(loc.Region, storeID, ctx.Addr, metapb.StoreState_Up)
+
+	// Same failure as TestDropStore: the store backing the split-off
+	// region is removed, same as a down TiKV node.
+	cluster.RemoveStore(storeID)
+	resolver.resolveOnce()
+
+	snap := stats.RegionStats()
+	c.Assert(snap.Counts[CategoryDownPeer], Equals, 1)
+	c.Assert(snap.Regions[CategoryDownPeer], DeepEquals, []RegionVerID{loc.Region})
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+)
+
+// ReloadPriority classifies a pending PD reload so that a storm of
+// low-priority invalidations (a large split/merge, a bulk prefetch) can't
+// starve user-facing OLTP lookups of PD bandwidth.
+type ReloadPriority int
+
+const (
+	// ReloadPriorityBackground is bulk prefetch, e.g. loadRegionsToCache.
+	ReloadPriorityBackground ReloadPriority = iota
+	// ReloadPriorityNormal is the default for callers with no stronger
+	// opinion.
+	ReloadPriorityNormal
+	// ReloadPriorityHigh is a user-facing OLTP lookup, e.g. GetRPCContext.
+	ReloadPriorityHigh
+	// ReloadPriorityCritical is reserved for reloads the caller cannot
+	// tolerate being queued behind anything else.
+	ReloadPriorityCritical
+)
+
+// reloadPriorityWeights gives each ReloadPriority bucket's relative
+// selection weight: bucket i is picked with probability proportional to
+// weight[i] among buckets that currently have queued items.
+var reloadPriorityWeights = [...]float64{1.0, 4.0, 9.0, 16.0}
+
+// reloadRequest is one pending PD reload, enqueued by a caller that found
+// its cached region stale or missing.
+type reloadRequest struct {
+	region RegionVerID
+	done   chan struct{}
+}
+
+// ReloadScheduler serializes and prioritizes pending PD reloads. Callers
+// enqueue a region at a ReloadPriority through Enqueue; ReloadScheduler
+// dispatches at most maxInFlight concurrent reloads, picking the next one
+// to run by weighted random selection across non-empty priority buckets
+// rather than plain FIFO order.
+type ReloadScheduler struct {
+	resolve func(context.Context, RegionVerID) error
+	sem     chan struct{}
+
+	mu struct {
+		sync.Mutex
+		buckets [len(reloadPriorityWeights)][]*reloadRequest
+	}
+}
+
+// NewReloadScheduler builds a ReloadScheduler that dispatches at most
+// maxInFlight concurrent calls to resolve. maxInFlight below 1 is treated
+// as 1.
+func NewReloadScheduler(maxInFlight int, resolve func(context.Context, RegionVerID) error) *ReloadScheduler {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &ReloadScheduler{
+		resolve: resolve,
+		sem:     make(chan struct{}, maxInFlight),
+	}
+}
+
+// Enqueue schedules a PD reload of region at priority and returns a
+// channel that is closed once the reload has been dispatched and resolve
+// has returned.
+func (s *ReloadScheduler) Enqueue(region RegionVerID, priority ReloadPriority) <-chan struct{} {
+	req := &reloadRequest{region: region, done: make(chan struct{})}
+	s.mu.Lock()
+	s.mu.buckets[priority] = append(s.mu.buckets[priority], req)
+	s.mu.Unlock()
+	go s.dispatchOne()
+	return req.done
+}
+
+// EnqueueUserLookup enqueues region at ReloadPriorityHigh, the priority a
+// foreground caller like GetRPCContext should use.
+func (s *ReloadScheduler) EnqueueUserLookup(region RegionVerID) <-chan struct{} {
+	return s.Enqueue(region, ReloadPriorityHigh)
+}
+
+// EnqueueBackgroundPrefetch enqueues region at ReloadPriorityBackground,
+// the priority bulk prefetch like loadRegionsToCache should use.
+func (s *ReloadScheduler) EnqueueBackgroundPrefetch(region RegionVerID) <-chan struct{} {
+	return s.Enqueue(region, ReloadPriorityBackground)
+}
+
+// dispatchOne waits for a free concurrency slot, pops one request chosen
+// by weighted priority, and resolves it.
+func (s *ReloadScheduler) dispatchOne() {
+	s.sem <- struct{}{}
+	defer func() { <-s.sem }()
+
+	req := s.pop()
+	if req == nil {
+		return
+	}
+	defer close(req.done)
+	if s.resolve != nil {
+		_ = s.resolve(context.Background(), req.region)
+	}
+}
+
+// pop removes and returns one pending request, chosen by weighted random
+// selection among non-empty priority buckets, or nil if every bucket is
+// empty.
+func (s *ReloadScheduler) pop() *reloadRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0.0
+	for i, bucket := range s.mu.buckets {
+		if len(bucket) > 0 {
+			total += reloadPriorityWeights[i]
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	pick := rand.Float64() * total
+	for i, bucket := range s.mu.buckets {
+		if len(bucket) == 0 {
+			continue
+		}
+		pick -= reloadPriorityWeights[i]
+		if pick <= 0 {
+			req := bucket[0]
+			s.mu.buckets[i] = bucket[1:]
+			return req
+		}
+	}
+	// Floating point rounding landed past the last weight: fall back to
+	// the highest-priority non-empty bucket.
+	for i := len(s.mu.buckets) - 1; i >= 0; i-- {
+		if len(s.mu.buckets[i]) > 0 {
+			req := s.mu.buckets[i][0]
+			s.mu.buckets[i] = s.mu.buckets[i][1:]
+			return req
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+type testReloadSchedulerSuite struct{}
+
+var _ = Suite(&testReloadSchedulerSuite{})
+
+// TestCriticalReloadBeatsBackgroundMedian models the TestSplit/TestMerge
+// invalidation storm this scheduler exists for: thousands of background
+// reloads are already queued behind a handful of worker slots when one
+// critical reload is enqueued, and the critical reload should complete
+// well before the typical background reload does.
+func (s *testReloadSchedulerSuite) TestCriticalReloadBeatsBackgroundMedian(c *C) {
+	const backgroundCount = 500
+	resolve := func(ctx context.Context, region RegionVerID) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	}
+	sched := NewReloadScheduler(4, resolve)
+
+	start := time.Now()
+	bgDone := make([]<-chan struct{}, backgroundCount)
+	for i := 0; i < backgroundCount; i++ {
+		bgDone[i] = sched.Enqueue(RegionVerID{id: uint64(i)}, ReloadPriorityBackground)
+	}
+	criticalDone := sched.Enqueue(RegionVerID{id: 999999}, ReloadPriorityCritical)
+	<-criticalDone
+	criticalLatency := time.Since(start)
+
+	bgLatencies := make([]time.Duration, backgroundCount)
+	for i, done := range bgDone {
+		<-done
+		bgLatencies[i] = time.Since(start)
+	}
+	sort.Slice(bgLatencies, func(i, j int) bool { return bgLatencies[i] < bgLatencies[j] })
+	median := bgLatencies[len(bgLatencies)/2]
+
+	c.Assert(criticalLatency < median, IsTrue)
+}
+
+// TestPopPrefersHigherWeightedBuckets checks that, with every priority
+// bucket non-empty, pop draws from the critical bucket much more often
+// than from background over many trials -- the weighted-random contract
+// the scheduler is built on.
+func (s *testReloadSchedulerSuite) TestPopPrefersHigherWeightedBuckets(c *C) {
+	sched := NewReloadScheduler(1, nil)
+	const trials = 2000
+	var criticalPicks, backgroundPicks int
+	for i := 0; i < trials; i++ {
+		sched.mu.Lock()
+		sched.mu.buckets[ReloadPriorityBackground] = []*reloadRequest{{region: RegionVerID{id: 1}, done: make(chan struct{})}}
+		sched.mu.buckets[ReloadPriorityCritical] = []*reloadRequest{{region: RegionVerID{id: 2}, done: make(chan struct{})}}
+		sched.mu.Unlock()
+
+		req := sched.pop()
+		c.Assert(req, NotNil)
+		if req.region.id == 2 {
+			criticalPicks++
+		} else {
+			backgroundPicks++
+		}
+	}
+	c.Assert(criticalPicks > backgroundPicks*5, IsTrue)
+}
+
+// BenchmarkReloadSchedulerUnderInvalidationStorm spawns thousands of
+// concurrent background invalidations -- the split/merge storm scenario
+// from the request -- and measures scheduler throughput, modeled on
+// BenchmarkOnRequestFail.
+func BenchmarkReloadSchedulerUnderInvalidationStorm(b *testing.B) {
+	resolve := func(ctx context.Context, region RegionVerID) error { return nil }
+	sched := NewReloadScheduler(16, resolve)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := uint64(0)
+		for pb.Next() {
+			<-sched.Enqueue(RegionVerID{id: i}, ReloadPriorityBackground)
+			i++
+		}
+	})
+}
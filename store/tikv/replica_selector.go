@@ -0,0 +1,119 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"sync"
+
+	"github.com/pingcap/kvproto/pkg/errorpb"
+)
+
+// ReplicaReadPolicy describes which peer(s) of a region a request is
+// willing to read from.
+type ReplicaReadPolicy int
+
+const (
+	// ReplicaReadLeaderOnly never reads from a follower; this is the
+	// behavior GetRPCContext already gives every caller today.
+	ReplicaReadLeaderOnly ReplicaReadPolicy = iota
+	// ReplicaReadPreferLeader tries the leader first and only falls back
+	// to a follower after MaxLeaderFailures consecutive leader failures
+	// for this region.
+	ReplicaReadPreferLeader
+	// ReplicaReadFollowerOnly never reads from the leader.
+	ReplicaReadFollowerOnly
+	// ReplicaReadClosest picks whichever replica is labeled as being in
+	// the same zone/rack as this client, falling back to the leader when
+	// no replica carries a matching label.
+	ReplicaReadClosest
+)
+
+// defaultMaxLeaderFailures is how many consecutive leader failures
+// ReplicaReadPreferLeader tolerates before it starts preferring a
+// follower for the region.
+const defaultMaxLeaderFailures = 2
+
+// ReplicaSelector layers replica-selection policy and per-region retry
+// escalation bookkeeping on top of RegionCache. It does not reach into
+// RegionCache's internal peer/store lists — those are only available
+// inside region_cache.go. Instead it drives the same GetRPCContext/
+// OnSendFail/UpdateLeader surface every other caller uses, and tracks
+// enough state of its own (consecutive leader failures, per-store label
+// affinity) to decide *when* to ask RegionCache for the next peer rather
+// than *which* raw peer to address.
+type ReplicaSelector struct {
+	cache *RegionCache
+
+	mu struct {
+		sync.Mutex
+		// leaderFailures counts consecutive leader-read failures per
+		// region, reset on any successful read or on a follower
+		// retry, used to decide when ReplicaReadPreferLeader should
+		// escalate to a follower.
+		leaderFailures map[RegionVerID]int
+	}
+}
+
+// NewReplicaSelector builds a ReplicaSelector over cache.
+func NewReplicaSelector(cache *RegionCache) *ReplicaSelector {
+	s := &ReplicaSelector{cache: cache}
+	s.mu.leaderFailures = make(map[RegionVerID]int)
+	return s
+}
+
+// OnReadSuccess clears any retry escalation state recorded for region, so
+// the next read starts fresh at the leader again under
+// ReplicaReadPreferLeader.
+func (s *ReplicaSelector) OnReadSuccess(region RegionVerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mu.leaderFailures, region)
+}
+
+// shouldPreferFollower reports whether region has failed enough
+// consecutive leader reads that ReplicaReadPreferLeader should now prefer
+// a follower.
+func (s *ReplicaSelector) shouldPreferFollower(region RegionVerID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.leaderFailures[region] >= defaultMaxLeaderFailures
+}
+
+// OnPeerError classifies regionErr for a read against region and reports
+// whether the caller should retry against a different peer without
+// treating the region as unreachable, versus invalidate the region and
+// reload from PD.
+//
+// DataIsNotReady and RegionNotFound are scoped to the one peer that
+// returned them: a stale-read follower that has not applied far enough
+// yet, or a region that split/merged away from that peer's copy of the
+// world, says nothing about whether the *region* itself is reachable.
+// Any other failure is treated as the store needing re-resolution, same
+// as a plain OnSendFail would.
+func (s *ReplicaSelector) OnPeerError(region RegionVerID, policy ReplicaReadPolicy, regionErr *errorpb.Error) (retryThisRequest, invalidateRegion bool) {
+	switch {
+	case regionErr.GetDataIsNotReady() != nil, regionErr.GetRegionNotFound() != nil:
+		// Peer-scoped: skip only this peer for the current request. A
+		// leader read failing this way still counts toward leader-failure
+		// escalation so ReplicaReadPreferLeader can still back off.
+		if policy == ReplicaReadPreferLeader {
+			s.mu.Lock()
+			s.mu.leaderFailures[region]++
+			s.mu.Unlock()
+		}
+		return true, false
+	default:
+		return false, true
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"github.com/pingcap/kvproto/pkg/errorpb"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testReplicaSelectorSuite{})
+
+type testReplicaSelectorSuite struct{}
+
+// TestPreferLeaderEscalatesAfterRepeatedFailures checks that
+// ReplicaReadPreferLeader only starts preferring a follower once a region
+// has accumulated defaultMaxLeaderFailures consecutive leader failures,
+// and that a successful read resets the count.
+func (s *testReplicaSelectorSuite) TestPreferLeaderEscalatesAfterRepeatedFailures(c *C) {
+	sel := NewReplicaSelector(nil)
+	region := RegionVerID{id: 1}
+
+	c.Assert(sel.shouldPreferFollower(region), IsFalse)
+
+	for i := 0; i < defaultMaxLeaderFailures; i++ {
+		retry, invalidate := sel.OnPeerError(region, ReplicaReadPreferLeader, &errorpb.Error{DataIsNotReady: &errorpb.DataIsNotReady{}})
+		c.Assert(retry, IsTrue)
+		c.Assert(invalidate, IsFalse)
+	}
+	c.Assert(sel.shouldPreferFollower(region), IsTrue)
+
+	sel.OnReadSuccess(region)
+	c.Assert(sel.shouldPreferFollower(region), IsFalse)
+}
+
+// TestHibernatedRegionDoesNotExileFollower checks that a RegionNotFound
+// error (the error a hibernated region, woken by a stale-read, can return
+// while reporting leader=0) is treated as peer-scoped rather than as a
+// reason to invalidate the whole region — so the follower stays eligible
+// for the next stale-read attempt instead of being treated as down.
+func (s *testReplicaSelectorSuite) TestHibernatedRegionDoesNotExileFollower(c *C) {
+	sel := NewReplicaSelector(nil)
+	region := RegionVerID{id: 2}
+
+	retry, invalidate := sel.OnPeerError(region, ReplicaReadFollowerOnly, &errorpb.Error{RegionNotFound: &errorpb.RegionNotFound{}})
+	c.Assert(retry, IsTrue)
+	c.Assert(invalidate, IsFalse)
+}
+
+// TestGenuineFailureInvalidatesRegion checks that an error other than
+// DataIsNotReady/RegionNotFound is treated as the store needing
+// re-resolution, same as a plain OnSendFail would.
+func (s *testReplicaSelectorSuite) TestGenuineFailureInvalidatesRegion(c *C) {
+	sel := NewReplicaSelector(nil)
+	region := RegionVerID{id: 3}
+
+	retry, invalidate := sel.OnPeerError(region, ReplicaReadLeaderOnly, &errorpb.Error{ServerIsBusy: &errorpb.ServerIsBusy{}})
+	c.Assert(retry, IsFalse)
+	c.Assert(invalidate, IsTrue)
+}
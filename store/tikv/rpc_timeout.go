@@ -0,0 +1,108 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"sync"
+	"time"
+)
+
+// RequestSource labels which caller issued a request, so metrics and logs
+// can tell an external tool's traffic apart from regular OLTP traffic.
+type RequestSource string
+
+const (
+	// RequestSourceUser is ordinary OLTP/query traffic; the default when
+	// no source was set.
+	RequestSourceUser RequestSource = "user"
+	// RequestSourceBR is traffic issued by BR during backup/restore.
+	RequestSourceBR RequestSource = "br"
+	// RequestSourceLightning is traffic issued by Lightning during a bulk
+	// import.
+	RequestSourceLightning RequestSource = "lightning"
+	// RequestSourceAnalyze is traffic issued by a long-running ANALYZE
+	// scan.
+	RequestSourceAnalyze RequestSource = "analyze"
+)
+
+// TimedRPCContext augments an RPCContext with a per-request timeout and
+// source label, for callers -- BR, Lightning, a long analytical scan --
+// that need to override the client's default RPC timeout without
+// touching global configuration.
+type TimedRPCContext struct {
+	*RPCContext
+	Timeout time.Duration
+	Source  RequestSource
+}
+
+// requestTimeoutOption is what WithRequestTimeout records for a
+// Backoffer.
+type requestTimeoutOption struct {
+	timeout time.Duration
+	source  RequestSource
+}
+
+// requestTimeouts associates a Backoffer with the timeout/source
+// WithRequestTimeout recorded for it. Backoffer itself carries no such
+// field, so this is keyed on Backoffer identity the same way
+// ReplicaSelector and StoreResolver track state RegionCache doesn't
+// expose, rather than reaching into it.
+var requestTimeouts struct {
+	sync.Mutex
+	options map[*Backoffer]requestTimeoutOption
+}
+
+func init() {
+	requestTimeouts.options = make(map[*Backoffer]requestTimeoutOption)
+}
+
+// WithRequestTimeout records that every RPC issued through bo should use
+// timeout and be labeled source, and returns bo unchanged so it can be
+// chained into the call that builds the Backoffer.
+func WithRequestTimeout(bo *Backoffer, timeout time.Duration, source RequestSource) *Backoffer {
+	requestTimeouts.Lock()
+	requestTimeouts.options[bo] = requestTimeoutOption{timeout: timeout, source: source}
+	requestTimeouts.Unlock()
+	return bo
+}
+
+// requestTimeoutFor returns the timeout/source WithRequestTimeout last
+// recorded for bo, or the zero duration and RequestSourceUser if none was
+// set.
+func requestTimeoutFor(bo *Backoffer) (time.Duration, RequestSource) {
+	requestTimeouts.Lock()
+	defer requestTimeouts.Unlock()
+	opt, ok := requestTimeouts.options[bo]
+	if !ok {
+		return 0, RequestSourceUser
+	}
+	return opt.timeout, opt.source
+}
+
+// GetRPCContextWithTimeout is GetRPCContext plus a per-request timeout
+// override: a zero timeout falls back to whatever WithRequestTimeout last
+// recorded for bo, defaulting to RequestSourceUser if neither set one.
+func (c *RegionCache) GetRPCContextWithTimeout(bo *Backoffer, region RegionVerID, timeout time.Duration, source RequestSource) (*TimedRPCContext, error) {
+	ctx, err := c.GetRPCContext(bo, region)
+	if err != nil || ctx == nil {
+		return nil, err
+	}
+	if timeout == 0 && source == "" {
+		timeout, source = requestTimeoutFor(bo)
+	}
+	if source == "" {
+		source = RequestSourceUser
+	}
+	return &TimedRPCContext{RPCContext: ctx, Timeout: timeout, Source: source}, nil
+}
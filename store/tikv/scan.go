@@ -16,6 +16,7 @@ package tikv
 import (
 	"bytes"
 	"context"
+	"sync"
 
 	"github.com/pingcap/errors"
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
@@ -25,6 +26,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// defaultPrefetchBytesLimit caps how many bytes of not-yet-consumed pairs a
+// single parallel getData call buffers before it stops folding in further
+// shards, so a wide SetConcurrency doesn't let memory grow unboundedly on
+// tables with large rows.
+const defaultPrefetchBytesLimit = 4 * 1024 * 1024
+
 // Scanner support tikv scan
 type Scanner struct {
 	snapshot     *tikvSnapshot
@@ -39,6 +46,14 @@ type Scanner struct {
 	// Use for reverse scan.
 	reverse    bool
 	nextEndKey []byte
+
+	// concurrency is how many regions getData enumerates and fetches at
+	// once; 1 (the default) keeps the original one-region-at-a-time
+	// behavior. See SetConcurrency.
+	concurrency int
+	// prefetchBytesLimit bounds a single parallel getData call's buffered
+	// pair bytes; see SetPrefetchBytesLimit.
+	prefetchBytesLimit int
 }
 
 func newScanner(snapshot *tikvSnapshot, startKey []byte, endKey []byte, batchSize int, reverse bool) (*Scanner, error) {
@@ -47,13 +62,15 @@ func newScanner(snapshot *tikvSnapshot, startKey []byte, endKey []byte, batchSiz
 		batchSize = scanBatchSize
 	}
 	scanner := &Scanner{
-		snapshot:     snapshot,
-		batchSize:    batchSize,
-		valid:        true,
-		nextStartKey: startKey,
-		endKey:       endKey,
-		reverse:      reverse,
-		nextEndKey:   endKey,
+		snapshot:           snapshot,
+		batchSize:          batchSize,
+		valid:              true,
+		nextStartKey:       startKey,
+		endKey:             endKey,
+		reverse:            reverse,
+		nextEndKey:         endKey,
+		concurrency:        1,
+		prefetchBytesLimit: defaultPrefetchBytesLimit,
 	}
 	err := scanner.Next()
 	if kv.IsErrNotFound(err) {
@@ -62,6 +79,30 @@ func newScanner(snapshot *tikvSnapshot, startKey []byte, endKey []byte, batchSiz
 	return scanner, errors.Trace(err)
 }
 
+// SetConcurrency sets how many regions getData enumerates (via
+// regionCache.LocateKey/LocateEndKey) and fetches concurrently. n <= 1
+// restores the default serial, one-region-at-a-time behavior this Scanner
+// has always had. A region error on one shard is backed off and retried on
+// its own goroutine without aborting its peers' in-flight requests; lock
+// resolution still happens lazily back on the consumer goroutine in Next,
+// via resolveCurrentLock/snapshot.get, exactly as in serial mode. Calling
+// this mid-scan takes effect from the next getData call onward.
+func (s *Scanner) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.concurrency = n
+}
+
+// SetPrefetchBytesLimit overrides defaultPrefetchBytesLimit; n <= 0 resets
+// it to the default.
+func (s *Scanner) SetPrefetchBytesLimit(n int) {
+	if n <= 0 {
+		n = defaultPrefetchBytesLimit
+	}
+	s.prefetchBytesLimit = n
+}
+
 // Valid return valid.
 func (s *Scanner) Valid() bool {
 	return s.valid
@@ -158,6 +199,13 @@ func (s *Scanner) getData(bo *Backoffer) error {
 		zap.Binary("nextEndKey", s.nextEndKey),
 		zap.Bool("reverse", s.reverse),
 		zap.Uint64("txnStartTS", s.startTS()))
+	if s.concurrency > 1 {
+		return s.getDataParallel(bo)
+	}
+	return s.getDataSerial(bo)
+}
+
+func (s *Scanner) getDataSerial(bo *Backoffer) error {
 	sender := NewRegionRequestSender(s.snapshot.store.regionCache, s.snapshot.store.client)
 	var reqEndKey, reqStartKey []byte
 	var loc *KeyLocation
@@ -272,3 +320,208 @@ func (s *Scanner) getData(bo *Backoffer) error {
 		return nil
 	}
 }
+
+// scanShard describes one region getDataParallel enumerated: the region
+// located for it and the StartKey/EndKey it sends in its CmdScan request
+// (same reqStartKey/reqEndKey computation getDataSerial does per region).
+type scanShard struct {
+	loc         *KeyLocation
+	reqStartKey []byte
+	reqEndKey   []byte
+}
+
+// scanShardResult is one shard's CmdScan outcome.
+type scanShardResult struct {
+	pairs []*pb.KvPair
+	loc   *KeyLocation
+	err   error
+}
+
+// getDataParallel is getDataSerial's concurrency>1 counterpart: it
+// enumerates up to s.concurrency upcoming regions, issues their CmdScan
+// RPCs concurrently, and folds the ordered results into s.cache, stopping
+// early once s.prefetchBytesLimit is reached. Forward/reverse semantics and
+// endKey bounds match getDataSerial's per-region handling exactly; only the
+// fan-out across regions is new.
+func (s *Scanner) getDataParallel(bo *Backoffer) error {
+	sender := NewRegionRequestSender(s.snapshot.store.regionCache, s.snapshot.store.client)
+
+	shards := make([]scanShard, 0, s.concurrency)
+	curStartKey, curEndKey := s.nextStartKey, s.nextEndKey
+	for len(shards) < s.concurrency {
+		var loc *KeyLocation
+		var err error
+		var reqStartKey, reqEndKey []byte
+		if !s.reverse {
+			loc, err = s.snapshot.store.regionCache.LocateKey(bo, curStartKey)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			reqStartKey = curStartKey
+			reqEndKey = s.endKey
+			if len(reqEndKey) > 0 && len(loc.EndKey) > 0 && bytes.Compare(loc.EndKey, reqEndKey) < 0 {
+				reqEndKey = loc.EndKey
+			}
+		} else {
+			loc, err = s.snapshot.store.regionCache.LocateEndKey(bo, curEndKey)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			reqStartKey = curStartKey
+			if len(reqStartKey) == 0 ||
+				(len(loc.StartKey) > 0 && bytes.Compare(loc.StartKey, reqStartKey) > 0) {
+				reqStartKey = loc.StartKey
+			}
+			reqEndKey = curEndKey
+		}
+		shards = append(shards, scanShard{loc: loc, reqStartKey: reqStartKey, reqEndKey: reqEndKey})
+
+		// Advance the cursor to the region boundary the next shard should
+		// start from; stop enumerating once we've reached the scan's own
+		// bound so we don't fetch regions past it.
+		if !s.reverse {
+			if len(loc.EndKey) == 0 || (len(s.endKey) > 0 && bytes.Compare(loc.EndKey, s.endKey) >= 0) {
+				break
+			}
+			curStartKey = loc.EndKey
+		} else {
+			if len(loc.StartKey) == 0 || (len(curStartKey) > 0 && bytes.Compare(loc.StartKey, curStartKey) <= 0) {
+				break
+			}
+			curEndKey = loc.StartKey
+		}
+	}
+
+	results := make([]scanShardResult, len(shards))
+	var wg sync.WaitGroup
+	for i, sh := range shards {
+		wg.Add(1)
+		go func(i int, sh scanShard) {
+			defer wg.Done()
+			// Each shard gets its own Backoffer so one region's region-miss
+			// backoff/retry doesn't block or abort its peers' requests.
+			shardBo := NewBackoffer(context.WithValue(context.Background(), txnStartKey, s.startTS()), scannerNextMaxBackoff)
+			results[i] = s.fetchShard(shardBo, sender, sh)
+		}(i, sh)
+	}
+	wg.Wait()
+
+	var allPairs []*pb.KvPair
+	consumedBytes := 0
+	lastIdx := -1
+	for i, res := range results {
+		if res.err != nil {
+			return errors.Trace(res.err)
+		}
+		allPairs = append(allPairs, res.pairs...)
+		for _, p := range res.pairs {
+			consumedBytes += len(p.GetKey()) + len(p.GetValue())
+		}
+		lastIdx = i
+		if consumedBytes >= s.prefetchBytesLimit {
+			break
+		}
+	}
+
+	if err := s.snapshot.store.CheckVisibility(s.startTS()); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Check if kvPair contains error, it should be a Lock; resolving the
+	// lock itself still happens lazily back in Next/resolveCurrentLock.
+	for _, pair := range allPairs {
+		if keyErr := pair.GetError(); keyErr != nil {
+			lock, err := extractLockFromKeyErr(keyErr)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			pair.Key = lock.Key
+		}
+	}
+
+	s.cache, s.idx = allPairs, 0
+	if lastIdx < 0 {
+		s.eof = true
+		return nil
+	}
+	lastShard, lastRes := shards[lastIdx], results[lastIdx]
+	if len(lastRes.pairs) < s.batchSize {
+		// No more data in the last folded-in shard's Region. Next
+		// getDataParallel() starts enumerating from its boundary.
+		if !s.reverse {
+			s.nextStartKey = lastRes.loc.EndKey
+		} else {
+			s.nextEndKey = lastShard.reqStartKey
+		}
+		if (!s.reverse && (len(lastRes.loc.EndKey) == 0 || (len(s.endKey) > 0 && kv.Key(s.nextStartKey).Cmp(kv.Key(s.endKey)) >= 0))) ||
+			(s.reverse && (len(lastRes.loc.StartKey) == 0 || (len(s.nextStartKey) > 0 && kv.Key(s.nextStartKey).Cmp(kv.Key(s.nextEndKey)) >= 0))) {
+			s.eof = true
+		}
+		return nil
+	}
+	lastKey := lastRes.pairs[len(lastRes.pairs)-1].GetKey()
+	if !s.reverse {
+		s.nextStartKey = kv.Key(lastKey).Next()
+	} else {
+		s.nextEndKey = kv.Key(lastKey)
+	}
+	return nil
+}
+
+// fetchShard issues sh's CmdScan RPC, retrying on region error (re-locating
+// the shard's region, since an error here usually means it split/merged
+// since enumeration) until it succeeds or bo's backoff budget is spent.
+func (s *Scanner) fetchShard(bo *Backoffer, sender *RegionRequestSender, sh scanShard) scanShardResult {
+	loc := sh.loc
+	for {
+		req := &tikvrpc.Request{
+			Type: tikvrpc.CmdScan,
+			Scan: &pb.ScanRequest{
+				StartKey: sh.reqStartKey,
+				EndKey:   sh.reqEndKey,
+				Limit:    uint32(s.batchSize),
+				Version:  s.startTS(),
+				KeyOnly:  s.snapshot.keyOnly,
+			},
+			Context: pb.Context{
+				Priority:     s.snapshot.priority,
+				NotFillCache: s.snapshot.notFillCache,
+			},
+		}
+		if s.reverse {
+			req.Scan.StartKey = sh.reqEndKey
+			req.Scan.EndKey = sh.reqStartKey
+			req.Scan.Reverse = true
+		}
+		resp, err := sender.SendReq(bo, req, loc.Region, ReadTimeoutMedium)
+		if err != nil {
+			return scanShardResult{err: errors.Trace(err)}
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return scanShardResult{err: errors.Trace(err)}
+		}
+		if regionErr != nil {
+			logutil.Logger(context.Background()).Debug("scanner getDataParallel shard failed",
+				zap.Stringer("regionErr", regionErr))
+			if err := bo.Backoff(BoRegionMiss, errors.New(regionErr.String())); err != nil {
+				return scanShardResult{err: errors.Trace(err)}
+			}
+			var relocErr error
+			if !s.reverse {
+				loc, relocErr = s.snapshot.store.regionCache.LocateKey(bo, sh.reqStartKey)
+			} else {
+				loc, relocErr = s.snapshot.store.regionCache.LocateEndKey(bo, sh.reqEndKey)
+			}
+			if relocErr != nil {
+				return scanShardResult{err: errors.Trace(relocErr)}
+			}
+			continue
+		}
+		cmdScanResp := resp.Scan
+		if cmdScanResp == nil {
+			return scanShardResult{err: errors.Trace(ErrBodyMissing)}
+		}
+		return scanShardResult{pairs: cmdScanResp.Pairs, loc: loc}
+	}
+}
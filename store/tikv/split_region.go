@@ -16,6 +16,8 @@ package tikv
 import (
 	"bytes"
 	"context"
+	"sync"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
@@ -23,9 +25,24 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/ratelimit"
 	"go.uber.org/zap"
 )
 
+// scatterLimiter throttles the aggregate rate of ScatterRegion/GetOperator
+// RPCs issued to PD across all scatter fan-outs in this process, so that a
+// large batch pre-split does not starve the rest of the cluster. It is
+// configured via tidb_scatter_region_qps (see SetScatterRegionQPS); 0 means
+// unlimited.
+var scatterLimiter = ratelimit.NewMonitor(0)
+
+// SetScatterRegionQPS reconfigures the process-wide scatter RPC rate cap,
+// driven by the tidb_scatter_region_qps session/global variable.
+func SetScatterRegionQPS(qps int) {
+	scatterLimiter.SetLimit(qps)
+	scatterLimiter.ReportMetrics("scatter_region")
+}
+
 // SplitRegion splits the region contains splitKey into 2 regions: [start,
 // splitKey) and [splitKey, end).
 func (s *tikvStore) SplitRegion(splitKey kv.Key, scatter bool) (regionID uint64, err error) {
@@ -83,7 +100,117 @@ func (s *tikvStore) SplitRegion(splitKey kv.Key, scatter bool) (regionID uint64,
 	}
 }
 
+// BatchSplitRegions splits the regions containing splitKeys in one round
+// trip per affected region, instead of one round trip per key. It groups
+// the (already sorted) keys by the region they currently belong to, issues
+// a single SplitRegionRequest carrying every split key for that region, and
+// retries only the un-split subset of a region's keys when a regionErr
+// comes back for it. It implements SplitableStore.BatchSplitRegions.
+func (s *tikvStore) BatchSplitRegions(splitKeys []kv.Key, scatter bool) ([]uint64, error) {
+	logutil.Logger(context.Background()).Info("start batch split regions",
+		zap.Int("keys", len(splitKeys)))
+	bo := NewBackoffer(context.Background(), splitRegionBackoff)
+	sender := NewRegionRequestSender(s.regionCache, s.client)
+
+	pending := splitKeys
+	newRegionIDs := make([]uint64, 0, len(splitKeys))
+	for len(pending) > 0 {
+		groups, err := s.groupKeysByRegion(bo, pending)
+		if err != nil {
+			return newRegionIDs, errors.Trace(err)
+		}
+
+		var retry []kv.Key
+		for region, keys := range groups {
+			req := &tikvrpc.Request{
+				Type: tikvrpc.CmdSplitRegion,
+				SplitRegion: &kvrpcpb.SplitRegionRequest{
+					SplitKeys: keys,
+				},
+			}
+			req.Context.Priority = kvrpcpb.CommandPri_Normal
+			res, err := sender.SendReq(bo, req, region, readTimeoutShort)
+			if err != nil {
+				return newRegionIDs, errors.Trace(err)
+			}
+			regionErr, err := res.GetRegionError()
+			if err != nil {
+				return newRegionIDs, errors.Trace(err)
+			}
+			if regionErr != nil {
+				if err := bo.Backoff(BoRegionMiss, errors.New(regionErr.String())); err != nil {
+					return newRegionIDs, errors.Trace(err)
+				}
+				retry = append(retry, keys...)
+				continue
+			}
+			for _, newRegion := range res.SplitRegion.GetRegions() {
+				newRegionIDs = append(newRegionIDs, newRegion.Id)
+			}
+		}
+		pending = retry
+	}
+
+	if scatter {
+		if err := s.scatterRegions(newRegionIDs); err != nil {
+			return newRegionIDs, errors.Trace(err)
+		}
+	}
+	logutil.Logger(context.Background()).Info("batch split regions complete",
+		zap.Int("new regions", len(newRegionIDs)))
+	return newRegionIDs, nil
+}
+
+// groupKeysByRegion locates every key in keys and buckets them by the
+// region they currently fall in, deduplicating any key equal to its
+// region's StartKey since splitting there would be a no-op.
+func (s *tikvStore) groupKeysByRegion(bo *Backoffer, keys []kv.Key) (map[RegionVerID][][]byte, error) {
+	groups := make(map[RegionVerID][][]byte)
+	for _, key := range keys {
+		loc, err := s.regionCache.LocateKey(bo, key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if bytes.Equal(key, loc.StartKey) {
+			continue
+		}
+		groups[loc.Region] = append(groups[loc.Region], key)
+	}
+	return groups, nil
+}
+
+// scatterRegions scatters every region in regionIDs concurrently and waits
+// for all scatters to finish before returning.
+func (s *tikvStore) scatterRegions(regionIDs []uint64) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(regionIDs))
+	for _, id := range regionIDs {
+		wg.Add(1)
+		go func(regionID uint64) {
+			defer wg.Done()
+			if err := s.scatterRegion(regionID); err != nil {
+				errCh <- errors.Trace(err)
+				return
+			}
+			errCh <- s.WaitScatterRegionFinish(regionID)
+		}(id)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *tikvStore) scatterRegion(regionID uint64) error {
+	for scatterLimiter.Limit(1) == 0 {
+		time.Sleep(time.Millisecond * 10)
+	}
+	defer scatterLimiter.Done(1)
+
 	logutil.Logger(context.Background()).Info("start scatter region",
 		zap.Uint64("regionID", regionID))
 	bo := NewBackoffer(context.Background(), scatterRegionBackoff)
@@ -110,7 +237,11 @@ func (s *tikvStore) WaitScatterRegionFinish(regionID uint64) error {
 	bo := NewBackoffer(context.Background(), waitScatterRegionFinishBackoff)
 	logFreq := 0
 	for {
+		for scatterLimiter.Limit(1) == 0 {
+			time.Sleep(time.Millisecond * 10)
+		}
 		resp, err := s.pdClient.GetOperator(context.Background(), regionID)
+		scatterLimiter.Done(1)
 		if err == nil && resp != nil {
 			if !bytes.Equal(resp.Desc, []byte("scatter-region")) || resp.Status != pdpb.OperatorStatus_RUNNING {
 				logutil.Logger(context.Background()).Info("wait scatter region finished",
@@ -0,0 +1,186 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	pd "github.com/pingcap/pd/client"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// storeResolveInterval is how often storeResolveLoop asks PD whether a
+// previously unreachable store has come back.
+const storeResolveInterval = 30 * time.Second
+
+// StoreResolver watches stores that RegionCache has seen go
+// unresolved/tombstone and, once PD reports one resolved again, flags
+// every cached region this process has routed to that store so the next
+// LocateKey forces a PD refresh instead of returning the stale peer
+// address.
+//
+// Like ReplicaSelector, it does not reach into RegionCache's internal
+// region/store maps -- those live in region_cache.go. Instead it keeps
+// its own record of which region was last routed to which store,
+// populated by Track, and drives invalidation through the same
+// InvalidateCachedRegion surface every other caller uses.
+type StoreResolver struct {
+	cache *RegionCache
+	pdCli pd.Client
+	stats *RegionStatsCollector
+
+	mu struct {
+		sync.Mutex
+		// stores records the last known address/state seen for every
+		// store Track has observed, so resolveOnce can tell a PD
+		// response apart from a no-op.
+		stores map[uint64]*resolvedStore
+		// regionsByStore indexes every region currently routed to a
+		// given store, so MarkStoreNeedReload knows what to invalidate
+		// when that store comes back.
+		regionsByStore map[uint64]map[RegionVerID]struct{}
+	}
+
+	closeCh chan struct{}
+}
+
+// resolvedStore is the last address/state StoreResolver observed for a
+// store, either from Track or from a previous PD re-resolution.
+type resolvedStore struct {
+	addr  string
+	state metapb.StoreState
+}
+
+// NewStoreResolver builds a StoreResolver that re-resolves stores for
+// cache through pdCli.
+func NewStoreResolver(cache *RegionCache, pdCli pd.Client) *StoreResolver {
+	r := &StoreResolver{cache: cache, pdCli: pdCli, closeCh: make(chan struct{})}
+	r.mu.stores = make(map[uint64]*resolvedStore)
+	r.mu.regionsByStore = make(map[uint64]map[RegionVerID]struct{})
+	return r
+}
+
+// SetStats wires a RegionStatsCollector into the resolver, so that
+// Track/MarkStoreNeedReload also feed the down-peer classification.
+func (r *StoreResolver) SetStats(stats *RegionStatsCollector) {
+	r.stats = stats
+}
+
+// Track records that region is currently served through the store
+// addressed by addr, so that a later recovery of that store invalidates
+// region. state is the store's resolution state as last observed by the
+// caller (e.g. from the RPCContext that served region).
+func (r *StoreResolver) Track(region RegionVerID, storeID uint64, addr string, state metapb.StoreState) {
+	r.mu.Lock()
+	r.mu.stores[storeID] = &resolvedStore{addr: addr, state: state}
+	regions, ok := r.mu.regionsByStore[storeID]
+	if !ok {
+		regions = make(map[RegionVerID]struct{})
+		r.mu.regionsByStore[storeID] = regions
+	}
+	regions[region] = struct{}{}
+	r.mu.Unlock()
+
+	if r.stats != nil && state != metapb.StoreState_Up {
+		r.stats.MarkDownPeer(region)
+	}
+}
+
+// MarkStoreNeedReload flags every region tracked against storeID so the
+// next LocateKey/GetRPCContext call forces a PD refresh rather than
+// returning the stale cached entry.
+func (r *StoreResolver) MarkStoreNeedReload(storeID uint64) {
+	r.mu.Lock()
+	regions := r.mu.regionsByStore[storeID]
+	delete(r.mu.regionsByStore, storeID)
+	r.mu.Unlock()
+
+	for region := range regions {
+		r.cache.InvalidateCachedRegion(region)
+		if r.stats != nil {
+			r.stats.ClearRegion(region)
+		}
+	}
+}
+
+// Start launches the background store-resolution loop. Callers must call
+// Close when the resolver is no longer needed.
+func (r *StoreResolver) Start() {
+	go r.resolveLoop()
+}
+
+// Close stops the background store-resolution loop.
+func (r *StoreResolver) Close() {
+	close(r.closeCh)
+}
+
+func (r *StoreResolver) resolveLoop() {
+	ticker := time.NewTicker(storeResolveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.resolveOnce()
+		case <-r.closeCh:
+			return
+		}
+	}
+}
+
+// resolveOnce re-resolves every tracked store through PD, and marks any
+// store that has transitioned from unresolved/tombstone back to resolved
+// -- including one that kept its store ID but came back with a different
+// address -- as needing reload.
+func (r *StoreResolver) resolveOnce() {
+	r.mu.Lock()
+	tracked := make(map[uint64]resolvedStore, len(r.mu.stores))
+	for storeID, st := range r.mu.stores {
+		tracked[storeID] = *st
+	}
+	r.mu.Unlock()
+
+	ctx := context.Background()
+	for storeID, last := range tracked {
+		store, err := r.pdCli.GetStore(ctx, storeID)
+		if err != nil {
+			logutil.Logger(ctx).Warn("failed to re-resolve store",
+				zap.Uint64("storeID", storeID), zap.Error(err))
+			continue
+		}
+		if store == nil || store.GetState() == metapb.StoreState_Tombstone {
+			r.mu.Lock()
+			r.mu.stores[storeID] = &resolvedStore{state: metapb.StoreState_Tombstone}
+			regions := r.mu.regionsByStore[storeID]
+			r.mu.Unlock()
+			if r.stats != nil {
+				for region := range regions {
+					r.stats.MarkDownPeer(region)
+				}
+			}
+			continue
+		}
+		cameBack := last.state != metapb.StoreState_Up
+		addrChanged := store.GetAddress() != last.addr
+		r.mu.Lock()
+		r.mu.stores[storeID] = &resolvedStore{addr: store.GetAddress(), state: store.GetState()}
+		r.mu.Unlock()
+		if cameBack || addrChanged {
+			r.MarkStoreNeedReload(storeID)
+		}
+	}
+}
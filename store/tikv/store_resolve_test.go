@@ -0,0 +1,82 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/store/mockstore/mocktikv"
+)
+
+type testStoreResolverSuite struct {
+	OneByOneSuite
+	cluster *mocktikv.Cluster
+	store1  uint64
+	cache   *RegionCache
+	bo      *Backoffer
+}
+
+var _ = Suite(&testStoreResolverSuite{})
+
+func (s *testStoreResolverSuite) SetUpTest(c *C) {
+	s.cluster = mocktikv.NewCluster()
+	storeIDs, _, _, _ := mocktikv.BootstrapWithMultiStores(s.cluster, 1)
+	s.store1 = storeIDs[0]
+	pdCli := &codecPDClient{mocktikv.NewPDClient(s.cluster)}
+	s.cache = NewRegionCache(pdCli)
+	s.bo = NewBackoffer(context.Background(), 5000)
+}
+
+func (s *testStoreResolverSuite) TearDownTest(c *C) {
+	s.cache.Close()
+}
+
+// TestMarkStoreNeedReloadInvalidatesTrackedRegions checks that marking a
+// store as needing reload invalidates every region Track recorded
+// against it, and forgets them afterwards so invalidating the same store
+// again touches nothing.
+func (s *testStoreResolverSuite) TestMarkStoreNeedReloadInvalidatesTrackedRegions(c *C) {
+	loc, err := s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+
+	resolver := NewStoreResolver(s.cache, &codecPDClient{mocktikv.NewPDClient(s.cluster)})
+	resolver.Track(loc.Region, s.store1, fmt.Sprintf("store%d", s.store1), metapb.StoreState_Up)
+
+	resolver.MarkStoreNeedReload(s.store1)
+	c.Assert(s.cache.searchCachedRegion([]byte("a"), false), IsNil)
+
+	// Re-resolving the now-uncached store again is a no-op, not a panic.
+	resolver.MarkStoreNeedReload(s.store1)
+}
+
+// TestResolveOnceSkipsUnchangedStore checks that resolveOnce leaves
+// tracked regions alone when PD reports the same address and an already
+// resolved state.
+func (s *testStoreResolverSuite) TestResolveOnceSkipsUnchangedStore(c *C) {
+	loc, err := s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	ctx, err := s.cache.GetRPCContext(s.bo, loc.Region)
+	c.Assert(err, IsNil)
+
+	resolver := NewStoreResolver(s.cache, &codecPDClient{mocktikv.NewPDClient(s.cluster)})
+	resolver.Track(loc.Region, s.store1, ctx.Addr, metapb.StoreState_Up)
+	resolver.resolveOnce()
+
+	r := s.cache.searchCachedRegion([]byte("a"), false)
+	c.Assert(r, NotNil)
+	c.Assert(r.VerID(), Equals, loc.Region)
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	zaplog "github.com/pingcap/log"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// EmptyFileLogConfig is an empty FileLogConfig.
+var EmptyFileLogConfig = FileLogConfig{}
+
+// FileLogConfig serializes file log related config in toml/json.
+type FileLogConfig struct {
+	// Filename is the file to write logs to; backed by lumberjack when set.
+	Filename   string `toml:"filename" json:"filename"`
+	MaxSize    int    `toml:"max-size" json:"max-size"`
+	MaxDays    int    `toml:"max-days" json:"max-days"`
+	MaxBackups int    `toml:"max-backups" json:"max-backups"`
+}
+
+// LogConfig serializes log related config in toml/json.
+type LogConfig struct {
+	// Level is the minimum enabled logging level.
+	Level string `toml:"level" json:"level"`
+	// Format is the log format, one of "text", "json".
+	Format string `toml:"format" json:"format"`
+	// File is the file to redirect the slow query log to.
+	SlowQueryFile string
+	// File is the log file config.
+	File FileLogConfig `toml:"file" json:"file"`
+	// EnableSlowLog indicates whether to log slow queries.
+	EnableTimestamp bool `toml:"enable-timestamp" json:"enable-timestamp"`
+	// DisableTimestamp turns off timestamp prefixing, used by tests that
+	// want deterministic output.
+	DisableTimestamp bool
+}
+
+// DefaultLogFormat is the default log format.
+const DefaultLogFormat = "text"
+
+// NewLogConfig creates a LogConfig.
+func NewLogConfig(level, format, slowQueryFile string, fileCfg FileLogConfig, disableTimestamp bool) *LogConfig {
+	return &LogConfig{
+		Level:            level,
+		Format:           format,
+		SlowQueryFile:    slowQueryFile,
+		File:             fileCfg,
+		DisableTimestamp: disableTimestamp,
+	}
+}
+
+// SlowQueryLogger is a logrus logger dedicated to the slow query log file,
+// kept for backward compatibility with callers still on the logrus path.
+var SlowQueryLogger = log.StandardLogger()
+
+// SlowQueryZapLogger is a zap logger dedicated to the slow query log file.
+var SlowQueryZapLogger = zaplog.L()
+
+func stringToLogLevel(level string) log.Level {
+	switch strings.ToLower(level) {
+	case "fatal":
+		return log.FatalLevel
+	case "error":
+		return log.ErrorLevel
+	case "warn", "warning":
+		return log.WarnLevel
+	case "debug":
+		return log.DebugLevel
+	case "info":
+		return log.InfoLevel
+	}
+	return log.InfoLevel
+}
+
+func stringToZapLogLevel(level string) zapcore.Level {
+	switch strings.ToLower(level) {
+	case "fatal":
+		return zap.FatalLevel
+	case "error":
+		return zap.ErrorLevel
+	case "warn", "warning":
+		return zap.WarnLevel
+	case "debug":
+		return zap.DebugLevel
+	case "info":
+		return zap.InfoLevel
+	}
+	return zap.InfoLevel
+}
+
+// textFormatter is a logrus formatter mirroring TiDB's historical plain-text
+// log line: "file:line: [level] message key=val ...". When EnableEntryOrder
+// is set, fields are sorted by key so output is deterministic across runs,
+// which machine parsers (and tests) rely on.
+type textFormatter struct {
+	DisableTimestamp bool
+	EnableEntryOrder bool
+}
+
+// Format implements logrus.Formatter.
+func (f *textFormatter) Format(entry *log.Entry) ([]byte, error) {
+	var b strings.Builder
+	if !f.DisableTimestamp {
+		b.WriteString(entry.Time.Format("2006/01/02 15:04:05.000"))
+		b.WriteString(" ")
+	}
+	if file, ok := entry.Data["file"]; ok {
+		fmt.Fprintf(&b, "%v:%v: ", file, entry.Data["line"])
+	}
+	fmt.Fprintf(&b, "[%s] %s", levelName(entry.Level), entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		if k == "file" || k == "line" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if f.EnableEntryOrder {
+		sort.Strings(keys)
+	}
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, entry.Data[k])
+	}
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+func levelName(lvl log.Level) string {
+	switch lvl {
+	case log.WarnLevel:
+		return "warning"
+	default:
+		return lvl.String()
+	}
+}
+
+// InitLogger initializes the legacy logrus-based global logger (including
+// SlowQueryLogger) according to conf.
+func InitLogger(conf *LogConfig) error {
+	level := stringToLogLevel(conf.Level)
+	log.SetLevel(level)
+	log.SetFormatter(&textFormatter{DisableTimestamp: conf.DisableTimestamp})
+	log.SetReportCaller(false)
+
+	if conf.SlowQueryFile != "" {
+		w := &lumberjack.Logger{
+			Filename:   conf.SlowQueryFile,
+			MaxSize:    conf.File.MaxSize,
+			MaxBackups: conf.File.MaxBackups,
+			MaxAge:     conf.File.MaxDays,
+		}
+		slowLogger := log.New()
+		slowLogger.SetOutput(w)
+		slowLogger.SetFormatter(&slowQueryTextFormatter{})
+		slowLogger.SetLevel(log.DebugLevel)
+		SlowQueryLogger = slowLogger
+	}
+	return nil
+}
+
+// slowQueryTextFormatter renders "# Time: ..." header lines the way the
+// historical slow query log file expects, one line per message.
+type slowQueryTextFormatter struct{}
+
+func (f *slowQueryTextFormatter) Format(entry *log.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+// InitZapLogger initializes the zap-based global logger (including
+// SlowQueryZapLogger) according to conf.
+func InitZapLogger(conf *LogConfig) error {
+	zapCfg := &zaplog.Config{
+		Level:  conf.Level,
+		Format: conf.Format,
+	}
+	logger, props, err := zaplog.InitLogger(zapCfg)
+	if err != nil {
+		return err
+	}
+	zaplog.ReplaceGlobals(logger, props)
+
+	if conf.SlowQueryFile != "" {
+		w := zapcore.AddSync(&lumberjack.Logger{
+			Filename:   conf.SlowQueryFile,
+			MaxSize:    conf.File.MaxSize,
+			MaxBackups: conf.File.MaxBackups,
+			MaxAge:     conf.File.MaxDays,
+		})
+		encCfg := zaplog.DefaultZapLoggerConfig.EncoderConfig
+		core := zapcore.NewCore(zapcore.NewConsoleEncoder(encCfg), w, stringToZapLogLevel(conf.Level))
+		SlowQueryZapLogger = zap.New(core)
+	}
+	return nil
+}
+
+// SetLevel changes the global zap logger's level at runtime.
+func SetLevel(level string) error {
+	zaplog.SetLevel(stringToZapLogLevel(level))
+	return nil
+}
+
+type ctxLogKeyType struct{}
+
+var ctxLogKey = ctxLogKeyType{}
+
+// WithKeyValue attaches a key/value pair to ctx, included by every
+// subsequent Logger(ctx) call's output.
+func WithKeyValue(ctx context.Context, key, value string) context.Context {
+	var fields []zap.Field
+	if v := ctx.Value(ctxLogKey); v != nil {
+		fields = append(fields, v.([]zap.Field)...)
+	}
+	fields = append(fields, zap.String(key, value))
+	return context.WithValue(ctx, ctxLogKey, fields)
+}
+
+// Logger returns the zap logger bound to ctx, with any fields attached via
+// WithKeyValue already applied.
+func Logger(ctx context.Context) *zap.Logger {
+	if v := ctx.Value(ctxLogKey); v != nil {
+		return zaplog.L().With(v.([]zap.Field)...)
+	}
+	return zaplog.L()
+}
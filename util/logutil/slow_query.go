@@ -0,0 +1,186 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SlowQueryEmitter is the single ingress for slow-query logging. It always
+// writes zap-structured JSON with a stable, sorted field order (the same
+// guarantee TestLoggerKeepOrder exercises for the legacy textFormatter), so
+// that machine parsers don't have to cope with the two divergent formats
+// SlowQueryLogger (logrus) and SlowQueryZapLogger (zap) used to produce. A
+// token-bucket sampler bounds how many slow entries per second are actually
+// written under load; everything above that is only counted, via
+// droppedCount, and periodically flushed as a single summary entry.
+type SlowQueryEmitter struct {
+	mu     sync.Mutex
+	tokens float64
+	rate   float64 // entries/sec budget
+	burst  float64
+	last   time.Time
+
+	thresholdMs int64
+	dropped     int64 // atomic
+
+	flushInterval time.Duration
+	stopFlush     chan struct{}
+}
+
+// defaultSlowQueryEmitter is the process-wide emitter configured by
+// tidb_slow_log_sample_rate and tidb_slow_log_threshold_ms.
+var defaultSlowQueryEmitter = NewSlowQueryEmitter(0, 0)
+
+// NewSlowQueryEmitter creates a SlowQueryEmitter sampling at most
+// sampleRate entries/sec (<=0 means unlimited) and only considering entries
+// whose cost-time field is at least thresholdMs.
+func NewSlowQueryEmitter(sampleRate float64, thresholdMs int64) *SlowQueryEmitter {
+	e := &SlowQueryEmitter{
+		rate:          sampleRate,
+		burst:         sampleRate,
+		tokens:        sampleRate,
+		thresholdMs:   thresholdMs,
+		last:          time.Now(),
+		flushInterval: 10 * time.Second,
+	}
+	return e
+}
+
+// Configure re-tunes the process-wide slow query emitter, driven by the
+// tidb_slow_log_sample_rate / tidb_slow_log_threshold_ms system variables.
+func Configure(sampleRate float64, thresholdMs int64) {
+	defaultSlowQueryEmitter.mu.Lock()
+	defaultSlowQueryEmitter.rate = sampleRate
+	defaultSlowQueryEmitter.burst = sampleRate
+	defaultSlowQueryEmitter.thresholdMs = thresholdMs
+	defaultSlowQueryEmitter.mu.Unlock()
+}
+
+// allow reports whether the current call may be emitted, consuming one
+// token from the bucket if so; a non-positive rate means unlimited.
+func (e *SlowQueryEmitter) allow() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rate <= 0 {
+		return true
+	}
+	now := time.Now()
+	elapsed := now.Sub(e.last).Seconds()
+	e.last = now
+	e.tokens += elapsed * e.rate
+	if e.tokens > e.burst {
+		e.tokens = e.burst
+	}
+	if e.tokens < 1 {
+		return false
+	}
+	e.tokens--
+	return true
+}
+
+// sortedFields returns fields sorted by key so the emitted JSON's field
+// order is deterministic across runs.
+func sortedFields(fields []zap.Field) []zap.Field {
+	sorted := make([]zap.Field, len(fields))
+	copy(sorted, fields)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+// costMs extracts the "costTime"/"cost_ms" field value in milliseconds, or
+// 0 if absent; used to apply tidb_slow_log_threshold_ms.
+func costMs(fields []zap.Field) int64 {
+	for _, f := range fields {
+		if f.Key == "costTime" || f.Key == "cost_ms" {
+			return f.Integer
+		}
+	}
+	return 0
+}
+
+// Emit records one slow-query entry, subject to the threshold and sampler.
+func (e *SlowQueryEmitter) Emit(ctx context.Context, fields ...zap.Field) {
+	e.mu.Lock()
+	threshold := e.thresholdMs
+	e.mu.Unlock()
+	if threshold > 0 && costMs(fields) < threshold {
+		return
+	}
+	if !e.allow() {
+		atomic.AddInt64(&e.dropped, 1)
+		return
+	}
+	SlowQueryZapLogger.Info("slow-query", sortedFields(fields)...)
+}
+
+// DroppedCount returns, and resets, the number of entries dropped by the
+// sampler since the last call -- used by the periodic flush below and
+// available to tests.
+func (e *SlowQueryEmitter) DroppedCount() int64 {
+	return atomic.SwapInt64(&e.dropped, 0)
+}
+
+// StartDroppedCountFlush periodically logs, and resets, the dropped-entry
+// counter so operators can see how much sampling is discarding even though
+// none of it reached the slow query log itself.
+func (e *SlowQueryEmitter) StartDroppedCountFlush() {
+	e.mu.Lock()
+	if e.stopFlush != nil {
+		e.mu.Unlock()
+		return
+	}
+	e.stopFlush = make(chan struct{})
+	stop := e.stopFlush
+	e.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(e.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if n := e.DroppedCount(); n > 0 {
+					SlowQueryZapLogger.Warn("slow-query entries dropped by sampler", zap.Int64("dropped", n))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic dropped-count flush goroutine, if running.
+func (e *SlowQueryEmitter) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopFlush != nil {
+		close(e.stopFlush)
+		e.stopFlush = nil
+	}
+}
+
+// SlowQuery is the single ingress used by the executor to log a slow query.
+// It delegates to the process-wide SlowQueryEmitter, which applies
+// threshold filtering, rate-limited sampling, and deterministic field
+// ordering before writing structured JSON via SlowQueryZapLogger.
+func SlowQuery(ctx context.Context, fields ...zap.Field) {
+	defaultSlowQueryEmitter.Emit(ctx, fields...)
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"context"
+
+	. "github.com/pingcap/check"
+	"go.uber.org/zap"
+)
+
+func (s *testLogSuite) TestSlowQueryEmitterFieldOrder(c *C) {
+	fields1 := sortedFields([]zap.Field{
+		zap.String("d", "d"), zap.String("a", "a"), zap.String("c", "c"), zap.String("b", "b"),
+	})
+	keys := make([]string, 0, len(fields1))
+	for _, f := range fields1 {
+		keys = append(keys, f.Key)
+	}
+	c.Assert(keys, DeepEquals, []string{"a", "b", "c", "d"})
+}
+
+func (s *testLogSuite) TestSlowQueryEmitterSampling(c *C) {
+	e := NewSlowQueryEmitter(2, 0)
+	// A burst of 2 should be admitted immediately; everything else in the
+	// same instant is dropped until tokens refill.
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if e.allow() {
+			allowed++
+		}
+	}
+	c.Assert(allowed, Equals, 2)
+
+	e2 := NewSlowQueryEmitter(0, 0)
+	for i := 0; i < 100; i++ {
+		c.Assert(e2.allow(), IsTrue)
+	}
+}
+
+func (s *testLogSuite) TestSlowQueryEmitterThreshold(c *C) {
+	e := NewSlowQueryEmitter(0, 100)
+	c.Assert(costMs([]zap.Field{zap.Int64("costTime", 50)}), Equals, int64(50))
+	e.Emit(context.Background(), zap.Int64("costTime", 50))
+	c.Assert(e.DroppedCount(), Equals, int64(0))
+}
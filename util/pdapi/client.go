@@ -0,0 +1,302 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+)
+
+// Transport is the minimal surface Client needs to issue an HTTP request,
+// so tests can substitute a fake implementation instead of a real
+// *http.Client talking to a PD cluster.
+type Transport interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a typed wrapper around PD's HTTP API. It tracks the current set
+// of PD addresses and fails over to another member when the one it is
+// talking to stops responding or stops being the leader.
+type Client struct {
+	transport Transport
+
+	mu        sync.RWMutex
+	addrs     []string
+	curAddr   int
+
+	retryBase time.Duration
+	retryMax  time.Duration
+	maxRetry  int
+}
+
+// NewClient creates a Client talking to one of addrs, using http.DefaultClient
+// as its transport.
+func NewClient(addrs []string) *Client {
+	return NewClientWithTransport(addrs, http.DefaultClient)
+}
+
+// NewClientWithTransport creates a Client using transport instead of
+// http.DefaultClient, e.g. to point it at a fake server in tests.
+func NewClientWithTransport(addrs []string, transport Transport) *Client {
+	return &Client{
+		transport: transport,
+		addrs:     addrs,
+		retryBase: 100 * time.Millisecond,
+		retryMax:  2 * time.Second,
+		maxRetry:  5,
+	}
+}
+
+// StoreInfo is the subset of PD's /pd/api/v1/store(s) response this client
+// decodes.
+type StoreInfo struct {
+	Store struct {
+		ID      uint64 `json:"id"`
+		Address string `json:"address"`
+		State   int32  `json:"state"`
+		Version string `json:"version"`
+	} `json:"store"`
+	Status struct {
+		Capacity  string `json:"capacity"`
+		Available string `json:"available"`
+		Leaders   int    `json:"leader_count"`
+		Regions   int    `json:"region_count"`
+	} `json:"status"`
+}
+
+// StoresResponse is the decoded /pd/api/v1/stores response.
+type StoresResponse struct {
+	Count  int         `json:"count"`
+	Stores []StoreInfo `json:"stores"`
+}
+
+// RegionInfo is the subset of PD's /pd/api/v1/region(s) response this
+// client decodes.
+type RegionInfo struct {
+	ID          uint64 `json:"id"`
+	StartKey    string `json:"start_key"`
+	EndKey      string `json:"end_key"`
+	WrittenKeys uint64 `json:"written_keys"`
+	ReadKeys    uint64 `json:"read_keys"`
+	ApproxSize  int64  `json:"approximate_size"`
+	Leader      struct {
+		ID      uint64 `json:"id"`
+		StoreID uint64 `json:"store_id"`
+	} `json:"leader"`
+}
+
+// SchedulerList is the decoded /pd/api/v1/schedulers response: the names of
+// every scheduler currently registered with PD.
+type SchedulerList []string
+
+// MembersResponse is the decoded /pd/api/v1/members response.
+type MembersResponse struct {
+	Members []struct {
+		Name       string   `json:"name"`
+		MemberID   uint64   `json:"member_id"`
+		ClientUrls []string `json:"client_urls"`
+	} `json:"members"`
+	Leader struct {
+		Name     string `json:"name"`
+		MemberID uint64 `json:"member_id"`
+	} `json:"leader"`
+}
+
+// Config is the decoded /pd/api/v1/config response; PD's actual config has
+// many more fields than this, so unknown ones are preserved via Raw for a
+// caller that needs them.
+type Config struct {
+	Raw map[string]interface{}
+}
+
+// UnmarshalJSON implements json.Unmarshaler by keeping the full decoded
+// object in Raw.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &c.Raw)
+}
+
+// GetStores fetches every store known to PD.
+func (c *Client) GetStores(ctx context.Context) (*StoresResponse, error) {
+	var resp StoresResponse
+	if err := c.getJSON(ctx, Stores, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetStoreByID fetches one store's info by id.
+func (c *Client) GetStoreByID(ctx context.Context, id uint64) (*StoreInfo, error) {
+	var resp StoreInfo
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/%d", StoreByID, id), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetRegionByID fetches one region's info by id.
+func (c *Client) GetRegionByID(ctx context.Context, id uint64) (*RegionInfo, error) {
+	var resp RegionInfo
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/%d", RegionByID, id), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RegionsResponse is the decoded /pd/api/v1/regions response.
+type RegionsResponse struct {
+	Count   int          `json:"count"`
+	Regions []RegionInfo `json:"regions"`
+}
+
+// GetRegions fetches every region known to PD.
+func (c *Client) GetRegions(ctx context.Context) (*RegionsResponse, error) {
+	var resp RegionsResponse
+	if err := c.getJSON(ctx, Regions, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetMembers fetches the current PD member list and leader.
+func (c *Client) GetMembers(ctx context.Context) (*MembersResponse, error) {
+	var resp MembersResponse
+	if err := c.getJSON(ctx, Members, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSchedulers fetches the names of every scheduler registered with PD.
+func (c *Client) GetSchedulers(ctx context.Context) (SchedulerList, error) {
+	var resp SchedulerList
+	if err := c.getJSON(ctx, Schedulers, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetConfig fetches PD's current scheduling/replication config.
+func (c *Client) GetConfig(ctx context.Context) (*Config, error) {
+	var resp Config
+	if err := c.getJSON(ctx, Config, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetHotRegions fetches the PD hotspot snapshot at path (HotRead or
+// HotWrite), returning one RegionInfo per hot region keyed by region ID.
+func (c *Client) GetHotRegions(ctx context.Context, path string) (map[uint64]RegionInfo, error) {
+	return c.getHotRegions(ctx, path)
+}
+
+type hotRegionsResponse struct {
+	AsPeer   map[string][]hotRegionStat `json:"as_peer"`
+	AsLeader map[string][]hotRegionStat `json:"as_leader"`
+}
+
+type hotRegionStat struct {
+	RegionID    uint64 `json:"region_id"`
+	FlowBytes   uint64 `json:"flow_bytes"`
+	WrittenKeys uint64 `json:"hot_degree"`
+}
+
+// getHotRegions fetches either the hot-read or hot-write snapshot and
+// flattens it to one RegionInfo per region, keyed by region ID.
+func (c *Client) getHotRegions(ctx context.Context, path string) (map[uint64]RegionInfo, error) {
+	var resp hotRegionsResponse
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	snapshot := make(map[uint64]RegionInfo)
+	for _, stats := range resp.AsLeader {
+		for _, s := range stats {
+			snapshot[s.RegionID] = RegionInfo{ID: s.RegionID, WrittenKeys: s.WrittenKeys, ReadKeys: s.FlowBytes}
+		}
+	}
+	return snapshot, nil
+}
+
+// getJSON issues a GET to path against the current PD leader candidate,
+// retrying with backoff and failing over to another known PD address on
+// failure, then decodes the JSON response body into out.
+func (c *Client) getJSON(ctx context.Context, path string, out interface{}) error {
+	var lastErr error
+	for attempt := 0; attempt < c.maxRetry; attempt++ {
+		addr := c.pickAddr()
+		req, err := http.NewRequest(http.MethodGet, addr+path, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		req = req.WithContext(ctx)
+
+		resp, err := c.transport.Do(req)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(json.Unmarshal(body, out))
+		}
+		if err == nil {
+			resp.Body.Close()
+			lastErr = errors.Errorf("pdapi: unexpected status from %s: %s", addr, resp.Status)
+		} else {
+			lastErr = err
+		}
+		c.failover()
+
+		select {
+		case <-time.After(c.backoff(attempt)):
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		}
+	}
+	return errors.Annotate(lastErr, "pdapi: exhausted retries")
+}
+
+// pickAddr returns the PD address this client is currently pinned to.
+func (c *Client) pickAddr() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.addrs[c.curAddr%len(c.addrs)]
+}
+
+// failover advances to the next known PD address, so a leader change or a
+// single unreachable member doesn't stall every subsequent call.
+func (c *Client) failover() {
+	c.mu.Lock()
+	c.curAddr = (c.curAddr + 1) % len(c.addrs)
+	c.mu.Unlock()
+}
+
+// backoff computes the wait before retry number attempt, exponential with
+// jitter up to retryMax.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retryBase << uint(attempt)
+	if wait > c.retryMax || wait <= 0 {
+		wait = c.retryMax
+	}
+	jitter := time.Duration(rand.Int63n(int64(wait)/2 + 1))
+	return wait/2 + jitter
+}
@@ -15,8 +15,14 @@ package pdapi
 
 // The following constants are the APIs of PD server.
 const (
-	HotRead  = "/pd/api/v1/hotspot/regions/read"
-	HotWrite = "/pd/api/v1/hotspot/regions/write"
-	Regions  = "/pd/api/v1/regions"
-	Stores   = "/pd/api/v1/stores"
+	HotRead    = "/pd/api/v1/hotspot/regions/read"
+	HotWrite   = "/pd/api/v1/hotspot/regions/write"
+	Regions    = "/pd/api/v1/regions"
+	Stores     = "/pd/api/v1/stores"
+	Config     = "/pd/api/v1/config"
+	Members    = "/pd/api/v1/members"
+	Schedulers = "/pd/api/v1/schedulers"
+	Leader     = "/pd/api/v1/leader"
+	RegionByID = "/pd/api/v1/region/id" // appended with "/{id}"
+	StoreByID  = "/pd/api/v1/store"     // appended with "/{id}"
 )
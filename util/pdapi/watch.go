@@ -0,0 +1,121 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"context"
+	"time"
+)
+
+// HotRegionEventType classifies a HotRegionEvent the way a message-bus
+// change-event-listen API would: a region either newly appeared in the
+// hotspot snapshot, dropped out of it, or is still present with different
+// stats.
+type HotRegionEventType int
+
+const (
+	// HotRegionAdded means RegionID appeared in this poll but wasn't in the
+	// previous one.
+	HotRegionAdded HotRegionEventType = iota
+	// HotRegionRemoved means RegionID was in the previous poll but isn't in
+	// this one -- it cooled down or PD stopped tracking it.
+	HotRegionRemoved
+	// HotRegionUpdated means RegionID is in both polls with different stats.
+	HotRegionUpdated
+)
+
+// HotRegionEvent is one add/remove/update transition between two successive
+// hotspot snapshots, keyed by region ID.
+type HotRegionEvent struct {
+	Type   HotRegionEventType
+	Region RegionInfo
+}
+
+// WatchHotRegions polls both the hot-read and hot-write endpoints every
+// interval, diffs each successive snapshot against the last one seen, and
+// emits a HotRegionEvent for every region that appeared, disappeared, or
+// changed. Consumers (domain, statistics) subscribe to the returned channel
+// instead of each running their own poller against PD. The channel is
+// closed once ctx is done.
+func (c *Client) WatchHotRegions(ctx context.Context, interval time.Duration) <-chan HotRegionEvent {
+	events := make(chan HotRegionEvent)
+	go func() {
+		defer close(events)
+		prev := make(map[uint64]RegionInfo)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			cur, err := c.pollHotRegions(ctx)
+			if err == nil {
+				for _, ev := range diffHotRegions(prev, cur) {
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+				prev = cur
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// pollHotRegions fetches both hot-read and hot-write snapshots and merges
+// them into one map keyed by region ID; a region hot for both reads and
+// writes is represented once, with its write stats taking precedence.
+func (c *Client) pollHotRegions(ctx context.Context) (map[uint64]RegionInfo, error) {
+	reads, err := c.getHotRegions(ctx, HotRead)
+	if err != nil {
+		return nil, err
+	}
+	writes, err := c.getHotRegions(ctx, HotWrite)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[uint64]RegionInfo, len(reads)+len(writes))
+	for id, r := range reads {
+		merged[id] = r
+	}
+	for id, r := range writes {
+		merged[id] = r
+	}
+	return merged, nil
+}
+
+// diffHotRegions compares two successive hotspot snapshots and returns the
+// add/remove/update events between them, in a deterministic order (removed,
+// then added, then updated) so tests can assert on it directly.
+func diffHotRegions(prev, cur map[uint64]RegionInfo) []HotRegionEvent {
+	var events []HotRegionEvent
+	for id, r := range prev {
+		if _, ok := cur[id]; !ok {
+			events = append(events, HotRegionEvent{Type: HotRegionRemoved, Region: r})
+		}
+	}
+	for id, r := range cur {
+		old, ok := prev[id]
+		if !ok {
+			events = append(events, HotRegionEvent{Type: HotRegionAdded, Region: r})
+		} else if old != r {
+			events = append(events, HotRegionEvent{Type: HotRegionUpdated, Region: r})
+		}
+	}
+	return events
+}
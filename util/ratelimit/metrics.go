@@ -0,0 +1,46 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	observedRateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tidb",
+		Subsystem: "ratelimit",
+		Name:      "observed_rate",
+		Help:      "EMA-smoothed observed requests/sec per named Monitor.",
+	}, []string{"name"})
+
+	configuredLimitGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tidb",
+		Subsystem: "ratelimit",
+		Name:      "configured_limit",
+		Help:      "Configured requests/sec cap per named Monitor; 0 means unlimited.",
+	}, []string{"name"})
+)
+
+func init() {
+	prometheus.MustRegister(observedRateGauge)
+	prometheus.MustRegister(configuredLimitGauge)
+}
+
+// ReportMetrics publishes m's current EMA and configured limit under name,
+// e.g. "scatter_region". Callers that poll Status periodically (or on every
+// SetLimit) should call this so the two can be compared on a dashboard.
+func (m *Monitor) ReportMetrics(name string) {
+	st := m.Status()
+	observedRateGauge.WithLabelValues(name).Set(st.EMA)
+	configuredLimitGauge.WithLabelValues(name).Set(float64(st.Limit))
+}
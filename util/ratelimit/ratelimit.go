@@ -0,0 +1,135 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit provides a reusable, EMA-smoothed QPS limiter shared by
+// callers that fan many concurrent RPCs out to an external service (PD,
+// Pump, ...) and need to cap the aggregate rate rather than just the
+// concurrency.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sampleWindow is the bucket width used to compute the rolling RPCs/sec
+// sample that feeds the EMA.
+const sampleWindow = 100 * time.Millisecond
+
+// emaSmoothing is the weight given to the latest sample when updating rEMA;
+// smaller values smooth harder but react to load changes more slowly.
+const emaSmoothing = 0.2
+
+// Monitor is a token-bucket-like rate limiter tracking a rolling sample
+// (rSample) and exponential moving average (rEMA) of requests/sec, in the
+// style of TCP's bandwidth estimators. Callers ask Limit for how many of
+// their n pending requests they may issue in the current window and must
+// call Done when finished so in-flight accounting stays correct.
+type Monitor struct {
+	mu sync.Mutex
+
+	limit    int // configured cap, requests/sec; 0 means unlimited
+	rSample  int // requests admitted in the current window
+	rEMA     float64
+	windowAt time.Time
+
+	inflight int
+	closed   bool
+}
+
+// NewMonitor creates a Monitor capped at limit requests/sec. A limit <= 0
+// means unlimited.
+func NewMonitor(limit int) *Monitor {
+	return &Monitor{limit: limit, windowAt: now()}
+}
+
+// now is a var so tests can fake the clock.
+var now = time.Now
+
+func (m *Monitor) rollWindow() {
+	elapsed := now().Sub(m.windowAt)
+	if elapsed < sampleWindow {
+		return
+	}
+	sampleRate := float64(m.rSample) / elapsed.Seconds()
+	m.rEMA = m.rEMA*(1-emaSmoothing) + sampleRate*emaSmoothing
+	m.rSample = 0
+	m.windowAt = now()
+}
+
+// Limit returns how many of the caller's n pending requests may be admitted
+// in the current window without exceeding the configured cap; it may
+// return fewer than n, including 0, when the cap is already saturated.
+// A non-positive configured limit means unlimited and Limit always
+// returns n.
+func (m *Monitor) Limit(n int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.limit <= 0 || m.closed {
+		m.rSample += n
+		m.inflight += n
+		return n
+	}
+	m.rollWindow()
+	budget := m.limit - m.rSample
+	if budget <= 0 {
+		return 0
+	}
+	admit := n
+	if admit > budget {
+		admit = budget
+	}
+	m.rSample += admit
+	m.inflight += admit
+	return admit
+}
+
+// Done releases n previously admitted tokens back to the in-flight count
+// once their requests complete.
+func (m *Monitor) Done(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight -= n
+	if m.inflight < 0 {
+		m.inflight = 0
+	}
+}
+
+// SetLimit reconfigures the cap, requests/sec; <= 0 disables limiting.
+func (m *Monitor) SetLimit(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limit = limit
+}
+
+// Status reports the current EMA rate and number of in-flight requests.
+type Status struct {
+	EMA      float64
+	Inflight int
+	Limit    int
+}
+
+// Status returns the Monitor's current state, for metrics export.
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Status{EMA: m.rEMA, Inflight: m.inflight, Limit: m.limit}
+}
+
+// Close marks the Monitor as shut down; Limit becomes a no-op pass-through
+// afterwards so callers draining in-flight work don't block forever.
+func (m *Monitor) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+}
@@ -31,3 +31,86 @@ func (s Float64Set) Exist(val float64) bool {
 func (s Float64Set) Insert(val float64) {
 	s[val] = struct{}{}
 }
+
+// Remove deletes `val` from `s`. It is a no-op if `val` is not present.
+func (s Float64Set) Remove(val float64) {
+	delete(s, val)
+}
+
+// Len returns the number of elements in `s`.
+func (s Float64Set) Len() int {
+	return len(s)
+}
+
+// Clear removes every element from `s`.
+func (s Float64Set) Clear() {
+	for k := range s {
+		delete(s, k)
+	}
+}
+
+// ToSlice returns the elements of `s` as a slice, in no particular order.
+func (s Float64Set) ToSlice() []float64 {
+	out := make([]float64, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Range calls f for every element of `s`, stopping early if f returns false.
+func (s Float64Set) Range(f func(float64) bool) {
+	for v := range s {
+		if !f(v) {
+			return
+		}
+	}
+}
+
+// Union returns a new set containing every element in `s` or `other`.
+func (s Float64Set) Union(other Float64Set) Float64Set {
+	out := make(Float64Set, len(s)+len(other))
+	for v := range s {
+		out.Insert(v)
+	}
+	for v := range other {
+		out.Insert(v)
+	}
+	return out
+}
+
+// Intersect returns a new set containing every element in both `s` and `other`.
+func (s Float64Set) Intersect(other Float64Set) Float64Set {
+	small, big := s, other
+	if len(big) < len(small) {
+		small, big = big, small
+	}
+	out := make(Float64Set, len(small))
+	for v := range small {
+		if big.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// Difference returns a new set containing every element in `s` that is not in `other`.
+func (s Float64Set) Difference(other Float64Set) Float64Set {
+	out := make(Float64Set, len(s))
+	for v := range s {
+		if !other.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// SymmetricDifference returns a new set containing every element that is
+// in exactly one of `s` and `other`.
+func (s Float64Set) SymmetricDifference(other Float64Set) Float64Set {
+	out := s.Difference(other)
+	for v := range other.Difference(s) {
+		out.Insert(v)
+	}
+	return out
+}
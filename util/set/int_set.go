@@ -50,3 +50,86 @@ func (s Int64Set) Exist(val int64) bool {
 func (s Int64Set) Insert(val int64) {
 	s[val] = struct{}{}
 }
+
+// Remove deletes `val` from `s`. It is a no-op if `val` is not present.
+func (s Int64Set) Remove(val int64) {
+	delete(s, val)
+}
+
+// Len returns the number of elements in `s`.
+func (s Int64Set) Len() int {
+	return len(s)
+}
+
+// Clear removes every element from `s`.
+func (s Int64Set) Clear() {
+	for k := range s {
+		delete(s, k)
+	}
+}
+
+// ToSlice returns the elements of `s` as a slice, in no particular order.
+func (s Int64Set) ToSlice() []int64 {
+	out := make([]int64, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Range calls f for every element of `s`, stopping early if f returns false.
+func (s Int64Set) Range(f func(int64) bool) {
+	for v := range s {
+		if !f(v) {
+			return
+		}
+	}
+}
+
+// Union returns a new set containing every element in `s` or `other`.
+func (s Int64Set) Union(other Int64Set) Int64Set {
+	out := make(Int64Set, len(s)+len(other))
+	for v := range s {
+		out.Insert(v)
+	}
+	for v := range other {
+		out.Insert(v)
+	}
+	return out
+}
+
+// Intersect returns a new set containing every element in both `s` and `other`.
+func (s Int64Set) Intersect(other Int64Set) Int64Set {
+	small, big := s, other
+	if len(big) < len(small) {
+		small, big = big, small
+	}
+	out := make(Int64Set, len(small))
+	for v := range small {
+		if big.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// Difference returns a new set containing every element in `s` that is not in `other`.
+func (s Int64Set) Difference(other Int64Set) Int64Set {
+	out := make(Int64Set, len(s))
+	for v := range s {
+		if !other.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// SymmetricDifference returns a new set containing every element that is
+// in exactly one of `s` and `other`.
+func (s Int64Set) SymmetricDifference(other Int64Set) Int64Set {
+	out := s.Difference(other)
+	for v := range other.Difference(s) {
+		out.Insert(v)
+	}
+	return out
+}
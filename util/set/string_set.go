@@ -0,0 +1,120 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+// StringSet is a string set.
+type StringSet map[string]struct{}
+
+// NewStringSet builds a StringSet.
+func NewStringSet(ss ...string) StringSet {
+	s := make(StringSet, len(ss))
+	for _, v := range ss {
+		s.Insert(v)
+	}
+	return s
+}
+
+// Exist checks whether `val` exists in `s`.
+func (s StringSet) Exist(val string) bool {
+	_, ok := s[val]
+	return ok
+}
+
+// Insert inserts `val` into `s`.
+func (s StringSet) Insert(val string) {
+	s[val] = struct{}{}
+}
+
+// Remove deletes `val` from `s`. It is a no-op if `val` is not present.
+func (s StringSet) Remove(val string) {
+	delete(s, val)
+}
+
+// Len returns the number of elements in `s`.
+func (s StringSet) Len() int {
+	return len(s)
+}
+
+// Clear removes every element from `s`.
+func (s StringSet) Clear() {
+	for k := range s {
+		delete(s, k)
+	}
+}
+
+// ToSlice returns the elements of `s` as a slice, in no particular order.
+func (s StringSet) ToSlice() []string {
+	out := make([]string, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Range calls f for every element of `s`, stopping early if f returns false.
+func (s StringSet) Range(f func(string) bool) {
+	for v := range s {
+		if !f(v) {
+			return
+		}
+	}
+}
+
+// Union returns a new set containing every element in `s` or `other`.
+func (s StringSet) Union(other StringSet) StringSet {
+	out := make(StringSet, len(s)+len(other))
+	for v := range s {
+		out.Insert(v)
+	}
+	for v := range other {
+		out.Insert(v)
+	}
+	return out
+}
+
+// Intersect returns a new set containing every element in both `s` and `other`.
+func (s StringSet) Intersect(other StringSet) StringSet {
+	small, big := s, other
+	if len(big) < len(small) {
+		small, big = big, small
+	}
+	out := make(StringSet, len(small))
+	for v := range small {
+		if big.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// Difference returns a new set containing every element in `s` that is not in `other`.
+func (s StringSet) Difference(other StringSet) StringSet {
+	out := make(StringSet, len(s))
+	for v := range s {
+		if !other.Exist(v) {
+			out.Insert(v)
+		}
+	}
+	return out
+}
+
+// SymmetricDifference returns a new set containing every element that is
+// in exactly one of `s` and `other`.
+func (s StringSet) SymmetricDifference(other StringSet) StringSet {
+	out := s.Difference(other)
+	for v := range other.Difference(s) {
+		out.Insert(v)
+	}
+	return out
+}
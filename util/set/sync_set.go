@@ -0,0 +1,162 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import "sync"
+
+// SyncFloat64Set is a concurrency-safe float64 set, for callers such as a
+// hash-join build side or a distinct aggregator that populate a set from
+// many worker goroutines instead of rolling their own mutex around a plain
+// Float64Set.
+type SyncFloat64Set struct {
+	mu sync.RWMutex
+	s  Float64Set
+}
+
+// NewSyncFloat64Set builds an empty SyncFloat64Set.
+func NewSyncFloat64Set() *SyncFloat64Set {
+	return &SyncFloat64Set{s: NewFloat64Set()}
+}
+
+// Exist checks whether `val` exists in the set.
+func (s *SyncFloat64Set) Exist(val float64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Exist(val)
+}
+
+// Insert inserts `val` into the set.
+func (s *SyncFloat64Set) Insert(val float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Insert(val)
+}
+
+// Remove deletes `val` from the set.
+func (s *SyncFloat64Set) Remove(val float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Remove(val)
+}
+
+// Len returns the number of elements in the set.
+func (s *SyncFloat64Set) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Len()
+}
+
+// ToSlice returns the elements of the set as a slice, in no particular order.
+func (s *SyncFloat64Set) ToSlice() []float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.ToSlice()
+}
+
+// SyncInt64Set is a concurrency-safe int64 set; see SyncFloat64Set.
+type SyncInt64Set struct {
+	mu sync.RWMutex
+	s  Int64Set
+}
+
+// NewSyncInt64Set builds an empty SyncInt64Set.
+func NewSyncInt64Set() *SyncInt64Set {
+	return &SyncInt64Set{s: NewInt64Set()}
+}
+
+// Exist checks whether `val` exists in the set.
+func (s *SyncInt64Set) Exist(val int64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Exist(val)
+}
+
+// Insert inserts `val` into the set.
+func (s *SyncInt64Set) Insert(val int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Insert(val)
+}
+
+// Remove deletes `val` from the set.
+func (s *SyncInt64Set) Remove(val int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Remove(val)
+}
+
+// Len returns the number of elements in the set.
+func (s *SyncInt64Set) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Len()
+}
+
+// ToSlice returns the elements of the set as a slice, in no particular order.
+func (s *SyncInt64Set) ToSlice() []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.ToSlice()
+}
+
+// SyncStringSet is a concurrency-safe string set. Unlike SyncFloat64Set and
+// SyncInt64Set it is backed by sync.Map rather than a mutex-guarded plain
+// map: IN (...) pushdown filters and other read-mostly consumers look up
+// the same handful of strings from many goroutines far more often than they
+// mutate the set, which is exactly the access pattern sync.Map is tuned for.
+type SyncStringSet struct {
+	m sync.Map
+}
+
+// NewSyncStringSet builds an empty SyncStringSet.
+func NewSyncStringSet() *SyncStringSet {
+	return &SyncStringSet{}
+}
+
+// Exist checks whether `val` exists in the set.
+func (s *SyncStringSet) Exist(val string) bool {
+	_, ok := s.m.Load(val)
+	return ok
+}
+
+// Insert inserts `val` into the set.
+func (s *SyncStringSet) Insert(val string) {
+	s.m.Store(val, struct{}{})
+}
+
+// Remove deletes `val` from the set.
+func (s *SyncStringSet) Remove(val string) {
+	s.m.Delete(val)
+}
+
+// Len returns the number of elements in the set. It is O(n) since sync.Map
+// does not track its own size.
+func (s *SyncStringSet) Len() int {
+	n := 0
+	s.m.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// ToSlice returns the elements of the set as a slice, in no particular order.
+func (s *SyncStringSet) ToSlice() []string {
+	out := make([]string, 0)
+	s.m.Range(func(k, _ interface{}) bool {
+		out = append(out, k.(string))
+		return true
+	})
+	return out
+}
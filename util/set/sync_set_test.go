@@ -0,0 +1,159 @@
+// Copyright 2025 Ekjot Singh
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package set
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// mutexInt64Set is the "roll your own locking" baseline these benchmarks
+// compare SyncInt64Set against: a plain map guarded by a single mutex.
+type mutexInt64Set struct {
+	mu sync.Mutex
+	m  map[int64]struct{}
+}
+
+func newMutexInt64Set() *mutexInt64Set {
+	return &mutexInt64Set{m: make(map[int64]struct{})}
+}
+
+func (s *mutexInt64Set) Insert(val int64) {
+	s.mu.Lock()
+	s.m[val] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *mutexInt64Set) Exist(val int64) bool {
+	s.mu.Lock()
+	_, ok := s.m[val]
+	s.mu.Unlock()
+	return ok
+}
+
+// mutexStringSet is the mutex-guarded-plain-map baseline for SyncStringSet.
+type mutexStringSet struct {
+	mu sync.Mutex
+	m  map[string]struct{}
+}
+
+func newMutexStringSet() *mutexStringSet {
+	return &mutexStringSet{m: make(map[string]struct{})}
+}
+
+func (s *mutexStringSet) Insert(val string) {
+	s.mu.Lock()
+	s.m[val] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *mutexStringSet) Exist(val string) bool {
+	s.mu.Lock()
+	_, ok := s.m[val]
+	s.mu.Unlock()
+	return ok
+}
+
+// BenchmarkMutexInt64SetConcurrentReadWrite is the baseline for
+// BenchmarkSyncInt64SetConcurrentReadWrite: a mutex-guarded plain map under
+// the same mixed read/write load a hash-join build side would generate.
+func BenchmarkMutexInt64SetConcurrentReadWrite(b *testing.B) {
+	s := newMutexInt64Set()
+	for i := int64(0); i < 1000; i++ {
+		s.Insert(i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int64(0)
+		for pb.Next() {
+			if i%8 == 0 {
+				s.Insert(i)
+			} else {
+				s.Exist(i % 1000)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkSyncInt64SetConcurrentReadWrite exercises SyncInt64Set under the
+// same load as BenchmarkMutexInt64SetConcurrentReadWrite so callers can
+// compare the two directly.
+func BenchmarkSyncInt64SetConcurrentReadWrite(b *testing.B) {
+	s := NewSyncInt64Set()
+	for i := int64(0); i < 1000; i++ {
+		s.Insert(i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int64(0)
+		for pb.Next() {
+			if i%8 == 0 {
+				s.Insert(i)
+			} else {
+				s.Exist(i % 1000)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkMutexStringSetConcurrentReadHeavy is the baseline for
+// BenchmarkSyncStringSetConcurrentReadHeavy, modelling an IN (...)
+// pushdown filter: lookups dominate, inserts are rare.
+func BenchmarkMutexStringSetConcurrentReadHeavy(b *testing.B) {
+	s := newMutexStringSet()
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		s.Insert(keys[i])
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%64 == 0 {
+				s.Insert(keys[i%len(keys)])
+			} else {
+				s.Exist(keys[i%len(keys)])
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkSyncStringSetConcurrentReadHeavy exercises SyncStringSet's
+// sync.Map backing under the same read-heavy load as
+// BenchmarkMutexStringSetConcurrentReadHeavy.
+func BenchmarkSyncStringSetConcurrentReadHeavy(b *testing.B) {
+	s := NewSyncStringSet()
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		s.Insert(keys[i])
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%64 == 0 {
+				s.Insert(keys[i%len(keys)])
+			} else {
+				s.Exist(keys[i%len(keys)])
+			}
+			i++
+		}
+	})
+}